@@ -20,12 +20,108 @@ import (
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// List travel agencies
+	// (GET /api/v1/admin/agencies)
+	ListAgencies(c *gin.Context)
+	// Create a travel agency
+	// (POST /api/v1/admin/agencies)
+	CreateAgency(c *gin.Context)
+	// List the API keys of an agency
+	// (GET /api/v1/admin/agencies/{agencyId}/api-keys)
+	ListAPIKeys(c *gin.Context, agencyId uint)
+	// Create an API key for an agency
+	// (POST /api/v1/admin/agencies/{agencyId}/api-keys)
+	CreateAPIKey(c *gin.Context, agencyId uint)
+	// Revoke an API key
+	// (DELETE /api/v1/admin/api-keys/{keyId})
+	RevokeAPIKey(c *gin.Context, keyId uint)
+	// Import a flight schedule
+	// (POST /api/v1/admin/flights/import)
+	ImportFlightSchedule(c *gin.Context, params ImportFlightScheduleParams)
+	// List the status changes of a flight
+	// (GET /api/v1/admin/flights/{id}/status)
+	ListFlightStatusHistory(c *gin.Context, id uint)
+	// Update the status and times of a flight
+	// (POST /api/v1/admin/flights/{id}/status)
+	UpdateFlightStatus(c *gin.Context, id uint)
+	// List recurring flight schedules
+	// (GET /api/v1/admin/schedules)
+	ListSchedules(c *gin.Context)
+	// Create a recurring flight schedule
+	// (POST /api/v1/admin/schedules)
+	CreateSchedule(c *gin.Context)
+	// List webhooks
+	// (GET /api/v1/admin/webhooks)
+	ListWebhooks(c *gin.Context)
+	// Subscribe a webhook to events
+	// (POST /api/v1/admin/webhooks)
+	CreateWebhook(c *gin.Context)
+	// Delete a webhook
+	// (DELETE /api/v1/admin/webhooks/{webhookId})
+	DeleteWebhook(c *gin.Context, webhookId uint)
+	// Deliver past events to a webhook again
+	// (POST /api/v1/admin/webhooks/{webhookId}/replay)
+	ReplayWebhook(c *gin.Context, webhookId uint)
+	// Register a customer of the agency
+	// (POST /api/v1/agency/customers)
+	CreateAgencyCustomer(c *gin.Context)
+	// Sign in with email and password
+	// (POST /api/v1/auth/login)
+	Login(c *gin.Context)
+	// Exchange a refresh token for new tokens
+	// (POST /api/v1/auth/refresh)
+	RefreshToken(c *gin.Context)
+	// Create a customer account
+	// (POST /api/v1/auth/signup)
+	Signup(c *gin.Context)
+	// Delete the calendar feed of the signed in customer
+	// (DELETE /api/v1/calendar/feed)
+	DeleteCalendarFeed(c *gin.Context)
+	// Create the calendar feed of the signed in customer
+	// (POST /api/v1/calendar/feed)
+	CreateCalendarFeed(c *gin.Context)
+	// Get a calendar feed
+	// (GET /api/v1/calendar/feeds/{feedToken}/flights.ics)
+	GetCalendarFeed(c *gin.Context, feedToken string)
+	// Autocomplete city and airline names
+	// (GET /api/v1/flights/autocomplete)
+	AutocompleteFlights(c *gin.Context, params AutocompleteFlightsParams)
 	// Search flights with filtering, sorting, and pagination
 	// (GET /api/v1/flights/search)
 	SearchFlights(c *gin.Context, params SearchFlightsParams)
+	// Stream seat availability of a flight
+	// (GET /api/v1/flights/{id}/availability/stream)
+	StreamFlightAvailability(c *gin.Context, id uint)
+	// Join the waiting room of a flight
+	// (POST /api/v1/flights/{id}/waiting-room)
+	JoinWaitingRoom(c *gin.Context, id uint)
+	// Get the position in the waiting room of a flight
+	// (GET /api/v1/flights/{id}/waiting-room/{token})
+	GetWaitingRoomStatus(c *gin.Context, id uint, token string)
 	// Submit a new flight booking order
 	// (POST /api/v1/orders)
-	CreateOrder(c *gin.Context)
+	CreateOrder(c *gin.Context, params CreateOrderParams)
+	// Submit a flight booking order asynchronously
+	// (POST /api/v1/orders/async)
+	SubmitOrder(c *gin.Context, params SubmitOrderParams)
+	// Get the status of an asynchronously submitted order
+	// (GET /api/v1/orders/tickets/{ticketId})
+	GetOrderTicket(c *gin.Context, ticketId string)
+	// Get an order
+	// (GET /api/v1/orders/{orderNumber})
+	GetOrder(c *gin.Context, orderNumber OrderNumber)
+	// Download the flight of an order as a calendar event
+	// (GET /api/v1/orders/{orderNumber}/calendar.ics)
+	GetOrderCalendar(c *gin.Context, orderNumber OrderNumber)
+	// Cancel an order
+	// (POST /api/v1/orders/{orderNumber}/cancel)
+	CancelOrder(c *gin.Context, orderNumber OrderNumber)
+	// Check in travelers of an order
+	// (POST /api/v1/orders/{orderNumber}/check-in)
+	CheckIn(c *gin.Context, orderNumber OrderNumber)
+	// Download the itinerary of an order
+	// (GET /api/v1/orders/{orderNumber}/itinerary.pdf)
+	GetItineraryPdf(c *gin.Context, orderNumber OrderNumber)
 
 	// (GET /liveness)
 	GetLiveness(c *gin.Context)
@@ -40,6 +136,485 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// ListAgencies operation middleware
+func (siw *ServerInterfaceWrapper) ListAgencies(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListAgencies(c)
+}
+
+// CreateAgency operation middleware
+func (siw *ServerInterfaceWrapper) CreateAgency(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateAgency(c)
+}
+
+// ListAPIKeys operation middleware
+func (siw *ServerInterfaceWrapper) ListAPIKeys(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "agencyId" -------------
+	var agencyId uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "agencyId", c.Param("agencyId"), &agencyId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter agencyId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListAPIKeys(c, agencyId)
+}
+
+// CreateAPIKey operation middleware
+func (siw *ServerInterfaceWrapper) CreateAPIKey(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "agencyId" -------------
+	var agencyId uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "agencyId", c.Param("agencyId"), &agencyId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter agencyId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateAPIKey(c, agencyId)
+}
+
+// RevokeAPIKey operation middleware
+func (siw *ServerInterfaceWrapper) RevokeAPIKey(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "keyId" -------------
+	var keyId uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "keyId", c.Param("keyId"), &keyId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter keyId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeAPIKey(c, keyId)
+}
+
+// ImportFlightSchedule operation middleware
+func (siw *ServerInterfaceWrapper) ImportFlightSchedule(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportFlightScheduleParams
+
+	// ------------- Required query parameter "format" -------------
+
+	if paramValue := c.Query("format"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument format is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ImportFlightSchedule(c, params)
+}
+
+// ListFlightStatusHistory operation middleware
+func (siw *ServerInterfaceWrapper) ListFlightStatusHistory(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListFlightStatusHistory(c, id)
+}
+
+// UpdateFlightStatus operation middleware
+func (siw *ServerInterfaceWrapper) UpdateFlightStatus(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateFlightStatus(c, id)
+}
+
+// ListSchedules operation middleware
+func (siw *ServerInterfaceWrapper) ListSchedules(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListSchedules(c)
+}
+
+// CreateSchedule operation middleware
+func (siw *ServerInterfaceWrapper) CreateSchedule(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateSchedule(c)
+}
+
+// ListWebhooks operation middleware
+func (siw *ServerInterfaceWrapper) ListWebhooks(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListWebhooks(c)
+}
+
+// CreateWebhook operation middleware
+func (siw *ServerInterfaceWrapper) CreateWebhook(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateWebhook(c)
+}
+
+// DeleteWebhook operation middleware
+func (siw *ServerInterfaceWrapper) DeleteWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "webhookId" -------------
+	var webhookId uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "webhookId", c.Param("webhookId"), &webhookId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter webhookId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteWebhook(c, webhookId)
+}
+
+// ReplayWebhook operation middleware
+func (siw *ServerInterfaceWrapper) ReplayWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "webhookId" -------------
+	var webhookId uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "webhookId", c.Param("webhookId"), &webhookId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter webhookId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ReplayWebhook(c, webhookId)
+}
+
+// CreateAgencyCustomer operation middleware
+func (siw *ServerInterfaceWrapper) CreateAgencyCustomer(c *gin.Context) {
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateAgencyCustomer(c)
+}
+
+// Login operation middleware
+func (siw *ServerInterfaceWrapper) Login(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.Login(c)
+}
+
+// RefreshToken operation middleware
+func (siw *ServerInterfaceWrapper) RefreshToken(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RefreshToken(c)
+}
+
+// Signup operation middleware
+func (siw *ServerInterfaceWrapper) Signup(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.Signup(c)
+}
+
+// DeleteCalendarFeed operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCalendarFeed(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteCalendarFeed(c)
+}
+
+// CreateCalendarFeed operation middleware
+func (siw *ServerInterfaceWrapper) CreateCalendarFeed(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateCalendarFeed(c)
+}
+
+// GetCalendarFeed operation middleware
+func (siw *ServerInterfaceWrapper) GetCalendarFeed(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "feedToken" -------------
+	var feedToken string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "feedToken", c.Param("feedToken"), &feedToken, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter feedToken: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetCalendarFeed(c, feedToken)
+}
+
+// AutocompleteFlights operation middleware
+func (siw *ServerInterfaceWrapper) AutocompleteFlights(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AutocompleteFlightsParams
+
+	// ------------- Required query parameter "q" -------------
+
+	if paramValue := c.Query("q"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument q is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "type" -------------
+
+	if paramValue := c.Query("type"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument type is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "type", c.Request.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter type: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AutocompleteFlights(c, params)
+}
+
 // SearchFlights operation middleware
 func (siw *ServerInterfaceWrapper) SearchFlights(c *gin.Context) {
 
@@ -88,6 +663,22 @@ func (siw *ServerInterfaceWrapper) SearchFlights(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "includeTotal" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeTotal", c.Request.URL.Query(), &params.IncludeTotal)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeTotal: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	// ------------- Optional query parameter "filters" -------------
 
 	err = runtime.BindQueryParameter("deepObject", true, false, "filters", c.Request.URL.Query(), &params.Filters)
@@ -96,6 +687,14 @@ func (siw *ServerInterfaceWrapper) SearchFlights(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "match" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "match", c.Request.URL.Query(), &params.Match)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter match: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -106,9 +705,331 @@ func (siw *ServerInterfaceWrapper) SearchFlights(c *gin.Context) {
 	siw.Handler.SearchFlights(c, params)
 }
 
+// StreamFlightAvailability operation middleware
+func (siw *ServerInterfaceWrapper) StreamFlightAvailability(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StreamFlightAvailability(c, id)
+}
+
+// JoinWaitingRoom operation middleware
+func (siw *ServerInterfaceWrapper) JoinWaitingRoom(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.JoinWaitingRoom(c, id)
+}
+
+// GetWaitingRoomStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetWaitingRoomStatus(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id uint
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "token", c.Param("token"), &token, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter token: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetWaitingRoomStatus(c, id, token)
+}
+
 // CreateOrder operation middleware
 func (siw *ServerInterfaceWrapper) CreateOrder(c *gin.Context) {
 
+	var err error
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateOrderParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "X-Admission-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Admission-Token")]; found {
+		var XAdmissionToken AdmissionToken
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for X-Admission-Token, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Admission-Token", valueList[0], &XAdmissionToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter X-Admission-Token: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.XAdmissionToken = &XAdmissionToken
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateOrder(c, params)
+}
+
+// SubmitOrder operation middleware
+func (siw *ServerInterfaceWrapper) SubmitOrder(c *gin.Context) {
+
+	var err error
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SubmitOrderParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "X-Admission-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Admission-Token")]; found {
+		var XAdmissionToken AdmissionToken
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for X-Admission-Token, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Admission-Token", valueList[0], &XAdmissionToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter X-Admission-Token: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.XAdmissionToken = &XAdmissionToken
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SubmitOrder(c, params)
+}
+
+// GetOrderTicket operation middleware
+func (siw *ServerInterfaceWrapper) GetOrderTicket(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "ticketId" -------------
+	var ticketId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "ticketId", c.Param("ticketId"), &ticketId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter ticketId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetOrderTicket(c, ticketId)
+}
+
+// GetOrder operation middleware
+func (siw *ServerInterfaceWrapper) GetOrder(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "orderNumber" -------------
+	var orderNumber OrderNumber
+
+	err = runtime.BindStyledParameterWithOptions("simple", "orderNumber", c.Param("orderNumber"), &orderNumber, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter orderNumber: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetOrder(c, orderNumber)
+}
+
+// GetOrderCalendar operation middleware
+func (siw *ServerInterfaceWrapper) GetOrderCalendar(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "orderNumber" -------------
+	var orderNumber OrderNumber
+
+	err = runtime.BindStyledParameterWithOptions("simple", "orderNumber", c.Param("orderNumber"), &orderNumber, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter orderNumber: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetOrderCalendar(c, orderNumber)
+}
+
+// CancelOrder operation middleware
+func (siw *ServerInterfaceWrapper) CancelOrder(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "orderNumber" -------------
+	var orderNumber OrderNumber
+
+	err = runtime.BindStyledParameterWithOptions("simple", "orderNumber", c.Param("orderNumber"), &orderNumber, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter orderNumber: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CancelOrder(c, orderNumber)
+}
+
+// CheckIn operation middleware
+func (siw *ServerInterfaceWrapper) CheckIn(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "orderNumber" -------------
+	var orderNumber OrderNumber
+
+	err = runtime.BindStyledParameterWithOptions("simple", "orderNumber", c.Param("orderNumber"), &orderNumber, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter orderNumber: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CheckIn(c, orderNumber)
+}
+
+// GetItineraryPdf operation middleware
+func (siw *ServerInterfaceWrapper) GetItineraryPdf(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "orderNumber" -------------
+	var orderNumber OrderNumber
+
+	err = runtime.BindStyledParameterWithOptions("simple", "orderNumber", c.Param("orderNumber"), &orderNumber, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter orderNumber: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(BearerAuthScopes, []string{})
+
+	c.Set(ApiKeyAuthScopes, []string{"booking"})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -116,7 +1037,7 @@ func (siw *ServerInterfaceWrapper) CreateOrder(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CreateOrder(c)
+	siw.Handler.GetItineraryPdf(c, orderNumber)
 }
 
 // GetLiveness operation middleware
@@ -159,42 +1080,197 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.GET(options.BaseURL+"/api/v1/admin/agencies", wrapper.ListAgencies)
+	router.POST(options.BaseURL+"/api/v1/admin/agencies", wrapper.CreateAgency)
+	router.GET(options.BaseURL+"/api/v1/admin/agencies/:agencyId/api-keys", wrapper.ListAPIKeys)
+	router.POST(options.BaseURL+"/api/v1/admin/agencies/:agencyId/api-keys", wrapper.CreateAPIKey)
+	router.DELETE(options.BaseURL+"/api/v1/admin/api-keys/:keyId", wrapper.RevokeAPIKey)
+	router.POST(options.BaseURL+"/api/v1/admin/flights/import", wrapper.ImportFlightSchedule)
+	router.GET(options.BaseURL+"/api/v1/admin/flights/:id/status", wrapper.ListFlightStatusHistory)
+	router.POST(options.BaseURL+"/api/v1/admin/flights/:id/status", wrapper.UpdateFlightStatus)
+	router.GET(options.BaseURL+"/api/v1/admin/schedules", wrapper.ListSchedules)
+	router.POST(options.BaseURL+"/api/v1/admin/schedules", wrapper.CreateSchedule)
+	router.GET(options.BaseURL+"/api/v1/admin/webhooks", wrapper.ListWebhooks)
+	router.POST(options.BaseURL+"/api/v1/admin/webhooks", wrapper.CreateWebhook)
+	router.DELETE(options.BaseURL+"/api/v1/admin/webhooks/:webhookId", wrapper.DeleteWebhook)
+	router.POST(options.BaseURL+"/api/v1/admin/webhooks/:webhookId/replay", wrapper.ReplayWebhook)
+	router.POST(options.BaseURL+"/api/v1/agency/customers", wrapper.CreateAgencyCustomer)
+	router.POST(options.BaseURL+"/api/v1/auth/login", wrapper.Login)
+	router.POST(options.BaseURL+"/api/v1/auth/refresh", wrapper.RefreshToken)
+	router.POST(options.BaseURL+"/api/v1/auth/signup", wrapper.Signup)
+	router.DELETE(options.BaseURL+"/api/v1/calendar/feed", wrapper.DeleteCalendarFeed)
+	router.POST(options.BaseURL+"/api/v1/calendar/feed", wrapper.CreateCalendarFeed)
+	router.GET(options.BaseURL+"/api/v1/calendar/feeds/:feedToken/flights.ics", wrapper.GetCalendarFeed)
+	router.GET(options.BaseURL+"/api/v1/flights/autocomplete", wrapper.AutocompleteFlights)
 	router.GET(options.BaseURL+"/api/v1/flights/search", wrapper.SearchFlights)
+	router.GET(options.BaseURL+"/api/v1/flights/:id/availability/stream", wrapper.StreamFlightAvailability)
+	router.POST(options.BaseURL+"/api/v1/flights/:id/waiting-room", wrapper.JoinWaitingRoom)
+	router.GET(options.BaseURL+"/api/v1/flights/:id/waiting-room/:token", wrapper.GetWaitingRoomStatus)
 	router.POST(options.BaseURL+"/api/v1/orders", wrapper.CreateOrder)
+	router.POST(options.BaseURL+"/api/v1/orders/async", wrapper.SubmitOrder)
+	router.GET(options.BaseURL+"/api/v1/orders/tickets/:ticketId", wrapper.GetOrderTicket)
+	router.GET(options.BaseURL+"/api/v1/orders/:orderNumber", wrapper.GetOrder)
+	router.GET(options.BaseURL+"/api/v1/orders/:orderNumber/calendar.ics", wrapper.GetOrderCalendar)
+	router.POST(options.BaseURL+"/api/v1/orders/:orderNumber/cancel", wrapper.CancelOrder)
+	router.POST(options.BaseURL+"/api/v1/orders/:orderNumber/check-in", wrapper.CheckIn)
+	router.GET(options.BaseURL+"/api/v1/orders/:orderNumber/itinerary.pdf", wrapper.GetItineraryPdf)
 	router.GET(options.BaseURL+"/liveness", wrapper.GetLiveness)
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xYbW/bOBL+KwTvPrSAEstu06YCCpwTu73c5Q1J9kM2MQJGGttsJVIlR0m9hf/7gqQk",
-	"68123O0C+8kWOZoZzjwz81A/aCiTVAoQqGnwg+pwDgmzf48VMIQLFYG6gm8ZaDSrqZIpKORgZcJMo0xA",
-	"PfDIPEagQ8VT5FLQgJ6MiJwSnAMpxEjCvnIxs2uPUpr/1KPwnSVpDDToe3QqVcKQBjTjAqlHcZECDSgX",
-	"CDNQdOnRacxnc9xi0AkRlNbMzjaQh18BH1giM4FtO+dZ8gjK2rKCusvQwKMJFzzJEmu0aWTpUQXfMq4g",
-	"osFd5VReLahNXyalJvn4BUI03h7n8u30QMJ4bP8UXtEvci72Iwn/yZf2Q5nQSkzcKx5N2PdTEDOc06Dv",
-	"+/Ys5XPpg0ZlUrj0qEvHpigrYNGFiBc0QJVBV9QFS6Du7P/kXJCRhN39SedSNJT5H/qDN28P3r0/rKsb",
-	"bNPWSJV10ysD5Sx15WWslOxISigjaGPKChO71xWbBLRms7XvFdvbfM/1F+KTpUc/WeS13WRchYpNsR7E",
-	"IwmmgN8fvq8H8WB7ShhXMW8m5Uhx5HpOhlw9s4XePdFMKf7E4oeQ46Ku+lSKSIqf14i8CceBPzjY8/t7",
-	"A/9mMAh8P/D936u1EzGEPftal9onxmP2GMODBuaa7apc8vC5buF3AeCRaXhIFQ87MHBplgkXRCcsjkEj",
-	"CTOlQIQLkgmO5BXsz/Y9Epom/7raow58399qOYKUKcwUdMT4HJ7JrVRfd4/ySuvGOPf9XeOcd1Jhe3QD",
-	"bMP+4M2Oxf+S1taOmUaGmcuxMIG9o9fH/x2Pfjsdj6hHR+PT4a39dzw8Px6futWT84fLq4vPV+Pra7Nz",
-	"cXZ5Or4Zj0xrWR2hqmZzqdvhUQ/GqgZbWW0UUis/jbLwVu2hPGwb4jXUdnVISy7arSenBr8aGWFlTv5b",
-	"wZQG9F+9Ff3p5dynV87TpdckOD9DVbaZyxtwk9rsZupn3pEm+p2FcnE1Kmblh65ItuF9OT4fnZx/tsA9",
-	"/3RyddaC9xpAr15sWUGJLF5Lwm7MLnG7f6H3beFl1SrqIGcl9Gu+NkLr1QHdVQiXUszadaCRKbzJi2Bz",
-	"ta9Eu9RfA1Ph3AHtCnQqhYa2uYghM78cIdEvR21ujSnFFpZ6dfKUY5sVJGaXlIHZxJCdpmv+B2xi4NZZ",
-	"koKymreqtHk63oQoUapWEEoV6Wp/4QLfvaWbJ2YjMzaqNcN5iCrna+fMaOFiKtteDokGxUEbD4eXJ5pM",
-	"pSIuG+TI4YxcLzRCYi8PaKts3f4TKO3U9vf9fd92hRQESzkN6Bu7ZNzEucVDj6W899TvuXrQPW1hZXZm",
-	"0BHPK8BMCU0YiblG42/+IjFjISJSEKeBhIojKM7IM8e5SSQXzCghOktTqWxFpaDs2klkxmAF0Nq6qFgC",
-	"CErT4K7px4ghGOvlSCOvbm9vb/fOzvZGo1pPqMwXauJPA/otA2XmobucVMaiGTWmA9h6MIevDaGu8dyi",
-	"batSsElcnXuN8Rw2K5MRTFkWo8X65svmDgW0zrJFard133Kq3HyNUb7ImU8c4sjcobVUaO/vCnQWG6As",
-	"1jhkJI8W3e60uUsxpbaRmgpdabOZyQtSem0OYJs/ecV0CCIy5SYVMWLu6fWGEzk+1H0opsPKSdyT0foi",
-	"v/4Pi70nFmdAUsaV6xlTHpuiE7Oi0e2TYXHkfFMH92KP1KliQEZlHZlnYvw3YlUCGZChe2qIOPoZmOue",
-	"+VNu1HhqULQz93gv7sXYVWhQ+HVX92nysbiI3Ge+P3hXSFVdmnx0F8KGhHNk8rFxEb0Xti+ksb2suy8W",
-	"XVnL9dRyxqKIm7iz+LI2X9sEpzmnNS5i12UgvchXJ2acuJFttQx8331FEAhuirE0jXloG0fvizb5/lHx",
-	"ZtMQ7+QFdvY0YJ2FIWg9zWJS9mF3icvR+Yv8cV9MOhzIBHxPIUSICOQyHtVZkjC1KMdBOV7sFCnh7dm2",
-	"Yv8wEVW7rFFSzDRbtDbAqdQdw8x9DTXDTMBzXuK2ihxUV18z64Oq8hGVOl4AGo9ktPhlMev4TLuscxCD",
-	"3mULRf2dPOhmipv8cmdeLjupTT22VpSE9iAR0SXY4sU/CmTZY8IxB0A97Q4PDk8xfwIBWldoUR0SnwFP",
-	"C5m/sbbtlaLjlIV/RFUK3p4U1FNBoDIV04DOEdOg14tlyOK51Bgc+oc+XU6WfwYAAP//Hl5LNDYYAAA=",
+	"H4sIAAAAAAAC/+x9i3IbubHor6Dm5tZJqoYUSUmWrKqturIk7yrrh46kTbJZ+WrBGVBEPARoACOZcfnf",
+	"T3UDmCeGD1mS7T3ZSsUiiUej0d3obnQ3PkWJnM2lYMLo6OBTNKeKzphhCj8dpjOuNZfiUr5nAr5JmU4U",
+	"nxsuRXSAvxvDUnJHueHihigpZ8RA45go9iHnCn6c8owRM2X1ZlwTJug4Y2kURxyGmzKaMhXFkaAzFh1E",
+	"/+gVAPQsBHGkkymbUQDFLObQSBvFxU30+XMcvVUpU2/y2ZipNqz2eyInCIlUdib2kc7mGYzz9vy4NxqM",
+	"dgfD0aA3pKPxdrJTQDanZlrCJSvzxJFfZ3RgVM6WQfjZ/2iRe3b6M1vAX3Ml50wZzvB7esNEsrjmKXwo",
+	"4BvG0USqGTXRQZRzYaLYD8+FYTdMRZ/jKFGMGpZeQ6tPZfuUGtYzfMaiuAlTHKWUZ4vrsZTvubi5/pBL",
+	"Q9vIQ8xqQrNM3rGUzJkiv1wekZQuYjIgE6mIkCTjM26qON0dDEJQ3mdlFvOVXtGZkmmeIHyBVc0Vm/CP",
+	"7YVcGKqMJ4L3bBETI4lhWQYfNKFzqmpLiIwUH6+3J3R/dxKaR1HDru3CW3Odsw8506aOthkXuWHLsPYs",
+	"jDXFbuX7DfdWJ3JuiYobNsM//qTYJDqI/s9Wyfdbjii3LEVeQKfoczEcVYoukL9KSv8NNjGukKrboQLx",
+	"xdw1FIWprUa374p55fhfLDEASBWuFpY1oyqZwja6vyYZv5kaHRM3C/wEf8KWz6I4YiKfwQps8yiOXLvo",
+	"XQCDh7jCNpPeh9Mehu5fcSnIpaK3LGtPEtoktzGrcJybKcpYHZBIScK0vjb+EGitjH2cc8X0NQ+cERcs",
+	"kSLVJBeGZ8h2djh7TBDXtcoAz7sYYKKYni4BA3+5tl9XUfaCUYWiejm2astszlcbvbbiDmxKYLCMGXbO",
+	"9FwKzdp4TamhazPnRX5zwzQidRVv4rghsF5IqlIubs6oDmzzmKpEpgEWOzt+uTPcI+53Lz1fHL04IxaV",
+	"MaFkTDV7tkOYgDYpOXvzI+EzesOiCpmPFybIGeNkPG9P66Elc6o14QJnPT28PCQvqCJHOE3RBpZE3ERV",
+	"+f16+Or0zdbrk1NS/e9keDh6sX1ELs9O3pxfvjgng+HzfTIYDX8dDEeHg8FolwwHgxCoyZQlIIa5cLxf",
+	"TgXaQ28w7I0Gl8PBwQD+988oXlM4pAyOnlyxa2wRHnh4OXh+sL3ZwBOutLluy5HXjAebo/S8FoUWVWGj",
+	"8+Hz/VCfjAZneMWDR7NmTcQNR4fhhh9yJhJWAaZOIEewFz0uiG9JRE3JMygl4bOlHbu2KnmMdkOSxve7",
+	"hw7W4MTqSLWtqCLNoaS94Ca1NXenRTWOk+KClUNC4IhmTKRUvWQsbQuBXGVtRP9y/srjdMJYGhM9lXeC",
+	"SJEtiBQJq3Hc1Ji5PtjaonPed9/2EzmDz1u3w63ETb8FI2n4eP18sv8sHewP9/d3kr302e5zOpowSgfJ",
+	"7i5NB8Nduj2e7EyG49F4MN4fjZItd8T3eaJDhHPHxjBscCmXU0Y0nTECa7rjZmoNE+xBUNayGCyWZErk",
+	"nAlNPLyEzue1Y8pN8xVX2iA2WG9t8cHdB4o6FU43be+/p1gdQJ3/CTQqpEzCRUzYLVOLCq9VDCzCJyRj",
+	"E0NkbqJ4vXPOAegnCx523avqOmrH7py4hrNkA5W4dmKuOnebkyzBf7G8FqRePDa1KGpIMpWaCTJe1KRb",
+	"TKhYkIliDDRg08D5Sin7OMIuuHTUQK0y30l/nebohOaZiQ4G8ZeYpjMu+AzU/8E9jMwZ/fiKiRszjQ6G",
+	"oKLOuCg+r2Eadi1gMzNx+Qq+zOCbcXFquw1XULo/t+x0SzYbzafOzV5l3WyE8RCI3ZAh2XQCluTayFnB",
+	"FPUNOz32Qs43K0zMiVSxPRZpOuNCEypSglYyZ5rM6AKbXQnYVCqkmTJVDNInx5ZGUL7C8JrfCJaC5uvb",
+	"xMVgV2KWa0NodkcXmmhmCDf9KxHFG3FwoVEsX6dt5Fe58RyGJ++ZuaYzmQuz1DOHDXVoolGF9IfLlLbA",
+	"wQXCm4kbEBPV0wl2iiG32XljAjSTekvjFTVckIwZw5S+ErYnV07uklQm+Qy4qU9ew76yQurabViLAf0x",
+	"8AaIddXxUm5VE6XdZH6RTFmaZ6yT0ilXiaKT0LaAliSVtbhgeFK1/op+1SNmb/956IihXGVcBAxLHLox",
+	"KrSMiaHgHJgoOasSoFPv76ZMhM+4F+fB+ZXitzS7plzNpWoYH7/+7bwuabZrcmZ7yXgpXVzLyUSzAPaO",
+	"gS3HzNwxZskpk6BhFgq7lQx2IJJSU3eBDBAiS+3bFcrvBUnfw+Mtxzokr3Bi+I1QY7HspvX4qGkJYGBG",
+	"cTSnxjAF/f//b4Pe6N1vg97zdwe/DXq79s8/Be14qtn1XPEk5ESAr4G39IxmGdOGJLlScDyQXHATE+yX",
+	"gnaTcm0omHPNjV4uAVK60Ndycn3H2PuODZET0OoVhe8I1STlNxxchUM8Zl9LkdIFSJ89/HyRw+c6erZ3",
+	"G8gZ9vb67z4N473Pf1pu11eobwUXVIgksEOXZyebEmzbubCKRJaDMNo+2Lk3kbDJhCWG37JrYO+Wp+NZ",
+	"bzjsDYZN/8bykYwMLIpqg5xV2/W4oCfnkZzkCo9hIQ1vWLKjwWivN9jujfbWgWa552RU37PRYOWmGWlo",
+	"dq0ZNTpsD6C/i2iasdiqC/hdQz5vxEPhAyfgbSiJoilbG2wYcFHUpFWLHOLyROo+1f7OxlMp33ceauyW",
+	"CYOu2vV1YTfmCXS9XIRuQGLvHGn7OhI1q3kAYCS9BXdH9W3f3VSL7TTjrTe/vEOkWfZ2Eh38to7CH32O",
+	"mxh7zxZtIgNfyeHZqb0nW+byqVySPdtJBnTIRnvj3RS8G/tsZ5g8Gw/SbbY3eU6H41Gykz5j+5PBSn8G",
+	"wNRe+7ty9W7L1l++79Bev2aJYiaMAlDDwcVs2yzHxN1Us+ThUeHA68CGMw4CfDCjvEGx/5JT0U8l+38V",
+	"cq1KN9tlY2N3tfNAMZq+FdnCX5OvY37/VU4FOZZsc3jmUykagw2eD0fbO7vP9vaXiuL1DMu4QJSdKcSj",
+	"J0rJwKaE71iwMWoBQRNqxrSmN539/M+rYHfj++ZAPS+tU3ypYVA5ySQDVtjb32sKtpVbUrECKuMpbrie",
+	"kkOuwJDdfKP9aZJws2i4EqRIpbj/iEvuYQaXo9GmFzz0lvIMwl3KI71kl91B5WAOena+QLUmf2b9m35M",
+	"EpCFf2kEaAxWzlwe4G0cv2F35Fep3m+O5bXuu+5zkbZMDTscjrY3ZP77XdtrQ01u99hFG1wc/XRy/Mur",
+	"k+Mojo5PXh3+in8dHb45Onllvz19c312/vbH85OLC/jl7euzVyeXJ8cgWsolVIdZ4+6/qcF5HmztaoOR",
+	"VqttFfvfLbZN4jWqDUlIK3sObTeeOfJqyKElfLO9inZr3q0vcG1XXS9NgLoXdoGIOZpScRO4kqCo+LL0",
+	"Gp1RAR3/ENVi66vSaJ9MOEsL36MdtuqLGw3W8Q6sx0Z2+PR6vAiH/QlyN5VkRlNWh+aRgtRa0mK9fkwY",
+	"bjKGfrrrG0WFYWlndJv7nVCi2CQXKan09pcua2P93pR3P4EzV+yWy1xf32+3i+73RXQxQEXyrSO0UC/U",
+	"MhzPExqrlJ3riz/8uwlhRW51rb4Lraulo1tU3GLyDpJcGZ51OgPrulOU2CuqynFjh4viKJ87f0UuHEsH",
+	"49zKBWHzsFNmtJYbhLMsDUizl/g9oYJYkBwr6XVd5RYDOIpDQ8BAX+2DacEb9ky/4qJwx2nnQ696ou+o",
+	"htA1KlKWEue4WHGGUH+P6E7gbu8KorabCjrsCr+QpcZDiY9cvBdgxzrHDbmK/vnPf15FKxnLQV+YEJ1g",
+	"VreqBSxSSR2gikbRJqqWp/CQq3GuyeH29qC3HQ7VMrLepbRdes9XLtMC6GbGsbpXes68Y7dh6TkKX9cF",
+	"VWPyAHU7GRHgLRslQlyDGK5tjHQfIfqJklQtiMpF8PBI1eIafiuJZyxlxihGGjKgtk3XYEk05EErpFCQ",
+	"Uq1oWLJC18Cv0H5ctcLG1vrllggtJ65CGEeliHJYCNHAK66N9avpBwr1LLx09wzzRIjcPfFDgWTjoL8I",
+	"pLZS/FDQtUf+Mkj9relDwefH+zKonPfyoYAqnaH3hUne8O5AsvV9j21lkmp9J1VaUz+LL1dJ7sIx5zuE",
+	"YEeVf0XuTcPowZ+ImVKDwQksrcS5YSAhN9q7yzcOkfABTw/sDEkqruGlQXe+XaXPvWyXqna7NiXiZpyU",
+	"Pbs1u/UEwVewv5AMgnrn2/Nj73cOBke0XUVnJ2+OT9/8iE6gNy9Pz1+3XEUdzqGyY2uWjUNwLIk3InDa",
+	"67aXlF3DXsKvxP76Bf7JFcE+G0XarJfYVDUcq/xQMRprK28iuEEQDf7uFEhVHmjJJmcrdqcdDC8HzzYV",
+	"EO+5CMi6IyoSlmWYPFXIOIxZI5M8y5x/JCY005LQiWHwS2I7sfRKVIykNGegqFnjpk/wdPbjVhK0fDCc",
+	"7XklODq8iJxMmAJ1z2+CdtNg+Az0J/SGcuEi3xwDnZ+8/OXNcdDOLb0NJQIdtJje4ADVZGc6mA00yTqs",
+	"3FwXO1FH3d+nLt6n6jwCRECPuESTDUHJNdOEG8QtdLKIXXP3mtelHHsWrocKvXQS3CVS7cOkt33ZueFt",
+	"2mZsLCwG5BIlLw9PX50cF5m7XY7/B5D40WCyP6Hp7riXPk/GvZ1nzyc9Ony229sb7D/b2xvtP98dBFHQ",
+	"PAWCQrbkKK7JnCnNNaZQgwohRcJIKd3XyU/e8EAph7b4vP8JsvxgcAbVBhQUksOFtO0WyCsEb4WSa0CF",
+	"WOJMips2L2hDlbl0etlymMumoeHPbUJhp8a8KsGxMVm9eXjCeUYXq4JlvG9lPS4PRVqdiOJWAroRBRZY",
+	"TIS8g2QEkOM3/JaJ+4k1BG+N1XXZRBgLpJepPLYF+ZCzHJx5UpGUZRyyWqoMuDNa6VhwU4WALSzAje/Z",
+	"e6tCastOJ387JIen9w2BXTPMtRqjuvqyqR1YuiJotJQqOx26XzPMsxmcuV4IZjOecrMLahf/+ISxjY8X",
+	"lfggF96NYMXyhnYwWMk2a99UP2DUYYC2l4Ui1he48l77AksLWGP0QV1cIctYsI/mKFc6pD/Z771whpZk",
+	"TkE2S1e4xWXGQjIq/hJ2x4QCj47QhLO9SLFtyyO0oe0F/zdbJo0RG5iV4eBZPiTuy9Ey87PMCVYskSrV",
+	"5eoxNpaLJMtTZhtzTSY007WbbC7Ms50VqVchn5nDXGXZQVrhNyKfP4Ab7UtD+B40/G6FB68y3N6oNtr+",
+	"NxDMt8J3WKnE0OZpl7qw1MWymEtNEqkUXg37JD9KbO0UMqMG65LUskGWhrnc0ixvIOiS8nkow7+xftsz",
+	"LuEOLbg7WfWxqiFsWLTgPifW/YoWBLJyqXZ5epihaysSrE68DZ6B4foAy7bkjVtA83Z1GfoaYZublXNY",
+	"3rl1ibr2gn5B06x6kdOdsrY6QHN4OXx2sLP7hRU4GgzM7ippMdAmrmVTzeQtgyReKW5ILjKmNalCCkeL",
+	"N4PCEG9vWtqjdGYFvSZGZileA3vfWW3mV9SUsLeT+moydbCWm+GBQhzXjfBxAISI6e+2zty5lLOLAswG",
+	"EbnSdUEPHroi0ZzFGkUJFZDfCR68ItNXyLsoDl2Xr1EKCbNx/EDo8imgiRuq8yOFN8bRXGruj7BGNHFG",
+	"bTQxIACNYlfBDwz5EKA7o8qELWWpQ2tzLo4v9bk1aMKOW3cXFSAHKaVMIWmHVN2ycEjEfbyjjZSkhgPF",
+	"eiA0E8bnft9ZwMDH7tlzBt4UNptjfO6D5TTdh3julwe1xgmYYwmTKq5ivxMrQ+Rai62IJnQK9stwD/dZ",
+	"iglXs+o3/g6joKF+d9QcqgVJrrhZgHtn5ghnzn9mCyinFrhDtrfD1p/t8sjtlXOfQPwISahSC5/Mp6AG",
+	"jSpuSbDYAtx7XImZTThXIMKxMARoHyIlU3rLIBIGamgU/bCGhstJ76iyeXba+xkvrT1x4BrQOcOoYsqv",
+	"xn566enjr3+/jJrVLA6rxd0wf1ujgROTDMIFYEHOcdknNo5Yydww7cuFwuKvBMVfcMVFNZ6yqsJ/2eEd",
+	"uuzSkOSRTxvF3oA0be1NLiYB5+Uh0UxxhomTh2enNqnypbsScii8WGjDMFSBG6T3rt9vmdJ22GF/0B/g",
+	"ncCcCTrn0UG0jV9h1uwUScUXCsJVbfnKDvDLjfW1FVmrp2l0UAvrieJIOccCdhgNBhHmFgnjLg/pfJ7x",
+	"BHtv/cupCWVp0mVSIxg+hBhsHGc57vUkz8r0WqtIuVInDwSPCyZrA5AL9nGONhRhrk3JkpgMWCXf3/Ak",
+	"ENG7z+/iSOezGVULh9UaLwJ27QEZ2INqWRNX+5Vp80Kmiwdbbqhyyue6tDQqZ59bFDB8MBDc+gIot7/4",
+	"QEPA087g+ePvtJuWZorRdEHYR66N/v5ozW5tU/bjUGFZsPXJNjlNP0ODHhSpXS4fbCDio4uHRrzjH1w6",
+	"lHnQeExQUexcXKuY/Vt3CR3qRUagnrTf46XFpFdmLL0rZVY7hxm0Dq7tIaqYyZVgKZkyxWLCDfyijVTw",
+	"FdVTlvajOCz3cNsfV+7VyoM9sdyrZ9WH5JBT4Gryb+fJ5B9YYBOZi/T7FX3Cc5L1eYolMtDJu61P7xkI",
+	"QEvYGbPpKXXyPMfi2AV5rsmTZbBmgClx0i/myAap7nTbBK6+99ORlJu3oCmrmiMQ/qD9/sjMEkKFzAKE",
+	"5WpqbvFZkbYRlJsnmN+jbcCOJTcoRswUl6k9B8jRxd+KElkXF6evy1whLoy8EhhuUlYkR8++dSKhCgPj",
+	"uR8hwbBe5QotvdLhCEP1yVsrwAFwXUlI1N7jmKrFeS6Ka6z+lXgjzRTm4ZrYBft7L6gaCVesIPMBYYh0",
+	"a0zVmcul81jXrFvfKiazNmIzg8pz2ofcBlc4VivKNnfzWpHXpm+jONKaz4IGedsTCqDXEAVxVjI35E7Z",
+	"tyhcVfgQXBaZtUcnCm6oFbEofEOW5bvORsM+mi1YQY1ZWouw7eYZ5WJ5yzXOxodT+2qpTiF9r6B8R2WY",
+	"ozPPqBAsrWfogIgbbj++RClAMlKSjCqbFrIzGj09UoDHbA4RxEVZjoSoUI+s70/S2qVDRLCVWgWPdwvc",
+	"Tzz9vFXeG3QaMdVboJ84aKYbHOpFke/Amc4f/EB/WLNqWYZUYB9dk/rKYyKzlGlD8PLtydSJl+7s+n41",
+	"1MLUsxRaHBh40E+K8JuwruC3otK/0i9G7yyHI5vPmLbnL14Gxi4ohYubKwGdfcY5wZt5fPYA+k0tG/TJ",
+	"CVbbLgrPYtQwaNHoJL8SNrC4Vu8eTn6Mb8D4c5EWUHnXtg2AhGZwJhombH1adwOh+8SXWFZseY2EOxf7",
+	"XiQAcF1mBRCMq7TB9b+/fHX640+X1y9Of7zG677f4deZVAwD7uHujQIUQMGFYElDukn72vgbkhQPbyN3",
+	"35I/sTYQyrjsFAqOzqwsGjyBaeNutfHCErNTCjX6q8rDJ3GVepxX+c4/RVMYdqibeT3ESLdD35/MtvxQ",
+	"lbog4KyQrcntlk7ipcpyTaRIBH5sh2o74/iP7VJV0EmV9i8p96PzjL0wUjFdapylTS4F0XIGNvKi0IYg",
+	"EBip4YYJaIeJVtofTVBJoCizbqeGgaZS8X9L0Se+3oCvDkvh1hROwCvhx0t9TSDN1C00sac7sY8/hA6r",
+	"eknuR3WjNut+P7Ej9aI0BrptopoT9QmOhVNxSzOeViyV7/b+qJN9ApLO63FLBZ2vLfDYcq5Vw+CPLeYK",
+	"3HcKNRf6QxXzWUc2kjRjVBsMDotBslBy9vbi0p5p1UCXPrkEffsfvUspPvYglpyit9AGeWCABHdmCZ6K",
+	"hs7m9oycMvLT68Oj3sVPh6PdZ0ROrsRVVLTpj2W6uIrKF5J88VuqyVVkfhjuDdx/8e3wh6t8MNhOpuwj",
+	"/sGuIgeW7RS+eOoWkG59jyofG0lxX+eeyS80QILup6qM/L7I/yIfw5hjkFaOC9DtjeS+REptfXJ/rbjx",
+	"OcbvS1JZ0+a7KzoEjL5i5ie49vEbbNf3dNc+ft7v2FFjt76kqzWpaUthwmj3fc9/5yx3stKlhDru88Gw",
+	"ZXJrLUvU6ZFXoojZvCtDiBfkjtVk+5hNpGLO/goJwVpe6zdI2Q8vkoN5ymuJ5NFjwdCtnZx0Zwv/h4c3",
+	"4WHAGRhLxnObkZWzAst61DkbAwW2ymyGTka2xysoTb5xJQQH42IxyrNPfJQjRvijiuJf8UJnLFcEKiQW",
+	"M3arLDjQoqii9Eiaix/+qbWV2rzNLFCH4KeOCDwBj3YREKjYDdeGqW9OU6pGg/9WvjTeDFqw0FcJth42",
+	"VuOD3Ey3MJi6ygENkw5/fhwyrJV9e2K3c+WV8pDh6N/Ls1Q4fDqXAt6v4H23Txz9+mRYquL8BrKcrS1n",
+	"IQULsAS1SVwuQL+bvFwlk8vipfTH0AlqxVK+KTqDTECXgcC1zln65PQmlXs4P/XZFBagb4nsTj7aWwVC",
+	"6zDaF00LFLbJzyaMdFOfzZh/JLqrp+M/ddz9UrI7TBIspOetEmBiXRd5/7sP3raftDhOqcVdjdhq73Ov",
+	"9jXU3k1fx8SHhlX7/lvV0MPmNcbMuSXjq+9FMF/rVdolYRGlJh4ayt/FNJ7T/S/tHr2ICQRR6sKVZCRO",
+	"W32RHT2NdlB40d07PDmMeiesjAGv5ZWodQONH8wnzaCOIGLDttUuVkM3n/nqXwlcDTjdqZ0QbRSCboXE",
+	"OQ4qL+VLwZyx3203LKepB1Thq/OELo1hOd+BrzF8H7IRqXYJAL31Cf5B8fu59u5+eW/SDC4Fd7Zei5Jt",
+	"KuT2wF5R0htJpCNLKgj320P+fP7yiOzu7uz+BdfRJ0eVa3wcRxu68P4oS4RI2r6VNaUtU9jT1rUFfxCB",
+	"45UJA3vIdDFEiDx/ZKZBm0vdUIi1gqtth4AbqkDwUjdUM8x1ddSdDax18K6MmW3WOq0QztOFh8DWfUv+",
+	"m4KnfmSmKa5rTOODOWlupI8rWc0ii7nsGZkxRYUhuiigY7NtE24WoNK6ml/4DDeyUj5P5KwSJN8i08MK",
+	"EC+LNstJlX00AA9LiZZkQlX9JUvK5x3R4B+WEu2GT9U3ofqZ2xqKdulGehx1wILjrRUv7x73sphdK2b+",
+	"tX14ulI3q7JfHfBgEno4UH5Yfcu69s7d8IkjbKvE8h3ePxccWl2H5R2M6qwyT5BjbUmBlbxKSQZX13JS",
+	"pqZQbUP47QgkUdwwxan1KMzpDRe4fqLzuSvH17DYKvXwVjLosXuyuUx9+fOvv/76a+/1697x8V/C1XMG",
+	"XRkc9Td1gpcd4cqJbb44KwvdodQq1x0TfiMwfdIm1pDElt2rVPwJAeeqw4V4ZgWXxBuUz+uaGQvSrebY",
+	"4WCwKTD48A/KMJ97o5jOM8xx6gAIWr5YhMFp13L0wm1VkcdKlcZlz/Z1b/kFLMCGVP+Z6oSJFKPNFEmZ",
+	"//SXJSt660pVhxZFdVJZif0Eo64F19s5/ZAzT2ZFWAXVpCwG6aPTioBy2PQ+wfrkmpnYhZsDUXNNFKMY",
+	"zwYJl8wQLrSBb+QEvrQFMvvE7pL1O/jlXYlZro3NbHN7ja4T+1DGXMk0T9xTGRbcSiWSBsrs79EyVTDu",
+	"LNYkiXWO2KpN9bqPCBzsnCsA2SenYsIFN6ynEyWzjCQZtxe+FJFDONbhwRQ6+EO/53O7BJij3wF/tZDk",
+	"RkljbW2ALXoY/0/mlCurJE14ZpiqreLQk7T7UR9ciR6pv+x5QI79Z3tWALDQrPre5wHxIdP1JvZIOSCH",
+	"9o/ih1qx1gNfLcV+vBJX4sRK6AMP1291mN794N+NhXih0TPfqgrSux/s+72NFhaQdz803g2GSf9mEyao",
+	"YkWeZUI169nTMUmYMD0uNBOaQ65EtrCW/v/F/7+2hn5BxjPNslsfxck+zjN8tNnuXzCF0QJY23aaplhi",
+	"i2ZntdJS7ZrazVJG2iwye3yx+Vv/bRfpu6krLHg3lRmzCSQgrHBl4BAZsxsu4CnzDgL2hSdD8op9pImp",
+	"SCz/2dasDEmtx1TlggV2v0NVzq6jULRQnyoYPUYpi3/Y+xqvbwS1O0yuo5WndLe0UYzOOvW9Cwxb7l0w",
+	"YYgvQIY9Cu8JnJO2WB2mb7qsIfQt2AdczJWwVMZd8TIpSCKFYIlBkJlNVrLXAFyTea6n9pyC9J6rqAru",
+	"VWSdF31yJGeYTgScbvkZx6aaTBlVZgxghXwWFwh94E3h7yR3EL0YiINeuXWbpOPUFh3ihmJ7q4gvYvK+",
+	"YmbM7uAJ0oEvpSQzyHqXc9CBEBn6m5IGdn80VpWt7lBXKkuN9+9s0cuekrby/Mogt4RmmbNlfK24OpMX",
+	"iqXzJGoyl+6BH/dejS0P6WtJklwYnrmCMr7sYuzDjYXNjEiLcDk/KRPpXHKMgtbkHz2oCqc1l6KHrsIQ",
+	"p/9VclGp8fmtMvjD+e/bBU0D1AVYcaq2owWCtPC/OAW4YC3ATQszm/PV1idkhc+duRQ/MtPeq2+CPOOw",
+	"z77gcXRd/Ety4V/nqtFQECTzCK78R+aRLoXwSRjEYtw9vBwXERxSFdfpuWbf3GWAmVYk/CZc5J48XyNM",
+	"E6JBykfm7FD+TOq4OfW+lQZnhZBQNtkqzpZLGy3z7jFzTBDER4wkCb8msvKhTdyjhsnZJgxsWoSa6IJt",
+	"ssW3fT8crxlzeZGPZ9w42qtTnCXFAClvUb0QSTdBnzNMBdUV6wl0nw+lymWJHEp/s8rTb9xVTRjT5P2N",
+	"gqOzT0pvvH3gDIx6XdamsBXJrTrm1C4conhYriiuDuLFPvUWtJkQDX9IZnq43IDqU4mdrEKThM2NSwmY",
+	"Kwn8Urws8IfhlhCnEGSLqZJC5jpbhBjH0rDe+mT/OE2XalBVfK+tOzk2KfQZvI4aOwrt0F8cMN+MCrOC",
+	"0L6u8mLxWysX6DSYPwaNe12nUr5HNEjbUpR9OKvrjPiE/9oLuc+rL1tF8wVxXzGoeOK4SNetvq19JXwl",
+	"HvuGVdHRPrrLMrrAKj2ZNLBRRYBQR6zP/Q6At+VKo8fni2+OI6zc/y6SsDbjAk+UK8m7iLlaK06uM9KN",
+	"Z6woZGVrUcEZymjhGcfs9EJ/imv5mP+WwgYLAQ+49wB133u03zOGN3dckV9Oj6+EL1HlB3ZFvsDV7mrx",
+	"efMXobIRnvbZWNTgZssYyC/usRnpQcLdfJSq3ej/sMz9WeZY3olM0rRKV/b08OpRNaAOCXwdzgKJvcR4",
+	"xt+rBwjaB256KDcJWLb3riwlC2ZvZCAexUZHo31ibY6iwptlQmLfT6+VdsMLoDLM1E2j2IxyUcSf4lW0",
+	"LbjFg6+/XxYmELxggq/HjxnCJMh4cSUALpHPmOIJOT3GYhOAU3whhBhIzSyyp5GOEFfavxDCNdbLVSyh",
+	"JnzSWaR9r4edM8n9JnxNpn2STJPGegsnmVRVfceT+B9DllgK3eQE9i8OdksKaIGFJEuVsir8bWj670c/",
+	"nRz9fH365vrt2cmbi9+tU+FKlN8fvXp7cXLxu2fBIqSjTy6Lce+mkqQSaSWZShBIFAUNVM2y2vVESWFm",
+	"UhsyUQyTJNwFUTGGe1MSAHZTwSHuErJ16RmB0caSYu1MV3TLKwZYj/vF0YszYt3iXpJcCUXviD0ccVZb",
+	"Wef45c5wj4ypSmQaTtYAkE7FA8iMR3CPWNi+UqJkMXt3DMZlYGefrObXG4ikTqb2TrVWFvWJxadUBYl/",
+	"BVF65KQEeAZhdrwBB7swk5qlcUUYVEu2xrZiXsUSVfYVQEFnrr6393RCP0PfQ+3YP4ggBoy1hOb6chnu",
+	"R5iiatGfp5MlppGwpXXJXHFhUIEqetaldLeDAHKIwNb/73MCAqzezepIVvXSdMZIKpN85ur9UmOoe5DA",
+	"2lfuRTzcIpsq3mHvnHogz9LJU6pSDpmBe88xF1QtAoHc7STqIIL/YwM9mA1Uo+A6y0DVF8G0Xub+feXb",
+	"POK5dSbDtOHhI6pyolkkqVtP3vj8JT4seLC1lcmEZlOpzcH+YH8QfX73+X8GACD1iOdUxQAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -9,6 +9,17 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+const (
+	ApiKeyAuthScopes = "apiKeyAuth.Scopes"
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for APIKeyScope.
+const (
+	Booking APIKeyScope = "booking"
+	Search  APIKeyScope = "search"
+)
+
 // Defines values for FlightStatus.
 const (
 	FlightStatusCANCELLED  FlightStatus = "CANCELLED"
@@ -18,6 +29,13 @@ const (
 	FlightStatusSCHEDULED  FlightStatus = "SCHEDULED"
 )
 
+// Defines values for ImportChangeAction.
+const (
+	Create    ImportChangeAction = "create"
+	Unchanged ImportChangeAction = "unchanged"
+	Update    ImportChangeAction = "update"
+)
+
 // Defines values for OrderStatus.
 const (
 	OrderStatusCANCELLED OrderStatus = "CANCELLED"
@@ -26,6 +44,47 @@ const (
 	OrderStatusPENDING   OrderStatus = "PENDING"
 )
 
+// Defines values for OrderEntitlementKind.
+const (
+	REFUND OrderEntitlementKind = "REFUND"
+)
+
+// Defines values for OrderTicketStatus.
+const (
+	OrderTicketStatusCOMPLETED OrderTicketStatus = "COMPLETED"
+	OrderTicketStatusFAILED    OrderTicketStatus = "FAILED"
+	OrderTicketStatusPENDING   OrderTicketStatus = "PENDING"
+)
+
+// Defines values for UpdateFlightStatusRequestStatus.
+const (
+	UpdateFlightStatusRequestStatusCANCELLED  UpdateFlightStatusRequestStatus = "CANCELLED"
+	UpdateFlightStatusRequestStatusCOMPLETED  UpdateFlightStatusRequestStatus = "COMPLETED"
+	UpdateFlightStatusRequestStatusDELAYED    UpdateFlightStatusRequestStatus = "DELAYED"
+	UpdateFlightStatusRequestStatusINPROGRESS UpdateFlightStatusRequestStatus = "IN_PROGRESS"
+	UpdateFlightStatusRequestStatusSCHEDULED  UpdateFlightStatusRequestStatus = "SCHEDULED"
+)
+
+// Defines values for WebhookEventType.
+const (
+	FlightChanged  WebhookEventType = "flight.changed"
+	OrderCancelled WebhookEventType = "order.cancelled"
+	OrderConfirmed WebhookEventType = "order.confirmed"
+	OrderCreated   WebhookEventType = "order.created"
+)
+
+// Defines values for ImportFlightScheduleParamsFormat.
+const (
+	Csv  ImportFlightScheduleParamsFormat = "csv"
+	Ssim ImportFlightScheduleParamsFormat = "ssim"
+)
+
+// Defines values for AutocompleteFlightsParamsType.
+const (
+	Airline AutocompleteFlightsParamsType = "airline"
+	City    AutocompleteFlightsParamsType = "city"
+)
+
 // Defines values for SearchFlightsParamsSortBy.
 const (
 	ArrivalTime    SearchFlightsParamsSortBy = "arrival_time"
@@ -40,16 +99,212 @@ const (
 	Desc SearchFlightsParamsSortOrder = "desc"
 )
 
+// Defines values for SearchFlightsParamsMatch.
+const (
+	Exact  SearchFlightsParamsMatch = "exact"
+	Prefix SearchFlightsParamsMatch = "prefix"
+)
+
+// APIKey defines model for APIKey.
+type APIKey struct {
+	AgencyId  uint      `json:"agency_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// DailyBookingQuota Orders allowed per UTC day, 0 for no limit
+	DailyBookingQuota int    `json:"daily_booking_quota"`
+	Id                uint   `json:"id"`
+	Name              string `json:"name"`
+
+	// Prefix Start of the key, to tell keys apart
+	Prefix string `json:"prefix"`
+
+	// RateLimit Requests allowed per minute, 0 for no limit
+	RateLimit int           `json:"rate_limit"`
+	RevokedAt *time.Time    `json:"revoked_at,omitempty"`
+	Scopes    []APIKeyScope `json:"scopes"`
+}
+
+// APIKeyScope search to search flights, booking to book them
+type APIKeyScope string
+
+// Agency defines model for Agency.
+type Agency struct {
+	CreatedAt time.Time `json:"created_at"`
+	Id        uint      `json:"id"`
+	Name      string    `json:"name"`
+}
+
+// AuthTokens defines model for AuthTokens.
+type AuthTokens struct {
+	AccessToken string `json:"access_token"`
+
+	// ExpiresIn Seconds until the access token expires
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+}
+
+// AutocompleteResponse defines model for AutocompleteResponse.
+type AutocompleteResponse struct {
+	Data []Suggestion `json:"data"`
+}
+
+// BoardingPass defines model for BoardingPass.
+type BoardingPass struct {
+	// Barcode PDF417 barcode of the BCBP string, a base64 encoded PNG image
+	Barcode []byte `json:"barcode"`
+
+	// Bcbp Boarding pass in the IATA Bar Coded Boarding Pass format
+	Bcbp          string    `json:"bcbp"`
+	CheckedInAt   time.Time `json:"checked_in_at"`
+	DepartureTime time.Time `json:"departure_time"`
+	FirstName     string    `json:"first_name"`
+	FlightNumber  string    `json:"flight_number"`
+	LastName      string    `json:"last_name"`
+	Seat          string    `json:"seat"`
+
+	// SequenceNumber Check-in sequence number of the traveler on the flight
+	SequenceNumber int  `json:"sequence_number"`
+	TravelerId     uint `json:"traveler_id"`
+}
+
+// CalendarFeed defines model for CalendarFeed.
+type CalendarFeed struct {
+	// Url URL of the feed, shown only once
+	Url string `json:"url"`
+
+	// WebcalUrl The same URL with the webcal scheme, which opens calendar apps
+	WebcalUrl string `json:"webcal_url"`
+}
+
+// CheckInRequest defines model for CheckInRequest.
+type CheckInRequest struct {
+	// Travelers Travelers to check in, every traveler of the order if left out
+	Travelers *[]CheckInTraveler `json:"travelers,omitempty"`
+}
+
+// CheckInResponse defines model for CheckInResponse.
+type CheckInResponse struct {
+	BoardingPasses []BoardingPass `json:"boarding_passes"`
+}
+
+// CheckInTraveler defines model for CheckInTraveler.
+type CheckInTraveler struct {
+	// Seat Seat chosen by the traveler, any free seat if left out
+	Seat       *string `json:"seat,omitempty"`
+	TravelerId uint    `json:"traveler_id"`
+}
+
+// CreateAPIKeyRequest defines model for CreateAPIKeyRequest.
+type CreateAPIKeyRequest struct {
+	// DailyBookingQuota Orders allowed per UTC day, 0 for no limit
+	DailyBookingQuota *int   `json:"daily_booking_quota,omitempty"`
+	Name              string `json:"name"`
+
+	// RateLimit Requests allowed per minute, 0 for no limit
+	RateLimit *int          `json:"rate_limit,omitempty"`
+	Scopes    []APIKeyScope `json:"scopes"`
+}
+
+// CreateAgencyRequest defines model for CreateAgencyRequest.
+type CreateAgencyRequest struct {
+	Name string `json:"name"`
+}
+
 // CreateOrderRequest defines model for CreateOrderRequest.
 type CreateOrderRequest struct {
-	// CustomerId ID of the customer making the booking
-	CustomerId uint `json:"customer_id"`
+	// CustomerId ID of the customer to book for, only admins and agencies may book
+	// for another customer. Defaults to the signed in customer, agencies
+	// must always set it.
+	CustomerId *uint `json:"customer_id,omitempty"`
 
 	// FlightId ID of the flight to book
 	FlightId uint `json:"flight_id"`
 
 	// TicketAmount Number of tickets to book
 	TicketAmount int `json:"ticket_amount"`
+
+	// Travelers Passengers of the order, one per ticket, named in the Latin letters
+	// of their travel documents. May be left out.
+	Travelers *[]TravelerName `json:"travelers,omitempty"`
+}
+
+// CreateScheduleRequest defines model for CreateScheduleRequest.
+type CreateScheduleRequest struct {
+	// Aircraft Name or IATA type code of the aircraft
+	Aircraft string `json:"aircraft"`
+
+	// Airline IATA code of the airline, taken from the flight number when left out
+	Airline        *string `json:"airline,omitempty"`
+	ArrivalAirport string  `json:"arrival_airport"`
+
+	// ArrivalDayOffset Days between the local departure and arrival dates
+	ArrivalDayOffset *int `json:"arrival_day_offset,omitempty"`
+
+	// ArrivalTime Local time at the arrival airport
+	ArrivalTime string `json:"arrival_time"`
+
+	// BasePrice Price in smallest currency unit, priced by distance when left out
+	BasePrice *int `json:"base_price,omitempty"`
+
+	// DaysOfWeek Days of operation as digits, 1 for Monday to 7 for Sunday
+	DaysOfWeek string `json:"days_of_week"`
+
+	// DepartureAirport IATA code of the departure airport
+	DepartureAirport string `json:"departure_airport"`
+
+	// DepartureTime Local time at the departure airport
+	DepartureTime string             `json:"departure_time"`
+	EffectiveFrom openapi_types.Date `json:"effective_from"`
+
+	// EffectiveTo Last date of operation, left out until further notice
+	EffectiveTo  *openapi_types.Date `json:"effective_to,omitempty"`
+	FlightNumber string              `json:"flight_number"`
+
+	// TotalSeats Seats for sale, the seats of the aircraft when left out
+	TotalSeats *int `json:"total_seats,omitempty"`
+}
+
+// CreateWebhookRequest defines model for CreateWebhookRequest.
+type CreateWebhookRequest struct {
+	EventTypes *[]WebhookEventType `json:"event_types,omitempty"`
+	Url        string              `json:"url"`
+}
+
+// CreatedAPIKey defines model for CreatedAPIKey.
+type CreatedAPIKey struct {
+	AgencyId  uint      `json:"agency_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// DailyBookingQuota Orders allowed per UTC day, 0 for no limit
+	DailyBookingQuota int  `json:"daily_booking_quota"`
+	Id                uint `json:"id"`
+
+	// Key The API key, shown only once
+	Key  string `json:"key"`
+	Name string `json:"name"`
+
+	// Prefix Start of the key, to tell keys apart
+	Prefix string `json:"prefix"`
+
+	// RateLimit Requests allowed per minute, 0 for no limit
+	RateLimit int           `json:"rate_limit"`
+	RevokedAt *time.Time    `json:"revoked_at,omitempty"`
+	Scopes    []APIKeyScope `json:"scopes"`
+}
+
+// CreatedWebhook defines model for CreatedWebhook.
+type CreatedWebhook struct {
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// EventTypes Events sent to the webhook, all of them if empty
+	EventTypes []WebhookEventType `json:"event_types"`
+	Id         uint               `json:"id"`
+
+	// Secret The signing secret, shown only once
+	Secret string `json:"secret"`
+	Url    string `json:"url"`
 }
 
 // Customer defines model for Customer.
@@ -89,49 +344,348 @@ type Flight struct {
 // FlightStatus defines model for Flight.Status.
 type FlightStatus string
 
+// FlightAvailability defines model for FlightAvailability.
+type FlightAvailability struct {
+	AvailableSeats int  `json:"available_seats"`
+	FlightId       uint `json:"flight_id"`
+}
+
+// FlightStatusChange defines model for FlightStatusChange.
+type FlightStatusChange struct {
+	// AffectedOrders Active orders notified of the change
+	AffectedOrders int       `json:"affected_orders"`
+	ArrivalTime    time.Time `json:"arrival_time"`
+
+	// ChangedBy Admin who made the change
+	ChangedBy     *uint     `json:"changed_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	DepartureTime time.Time `json:"departure_time"`
+
+	// EntitlementsGranted Orders granted a refund entitlement by the change
+	EntitlementsGranted   int       `json:"entitlements_granted"`
+	FlightId              uint      `json:"flight_id"`
+	Id                    uint      `json:"id"`
+	PreviousArrivalTime   time.Time `json:"previous_arrival_time"`
+	PreviousDepartureTime time.Time `json:"previous_departure_time"`
+	PreviousStatus        string    `json:"previous_status"`
+	Reason                string    `json:"reason"`
+	Status                string    `json:"status"`
+}
+
+// ImportChange defines model for ImportChange.
+type ImportChange struct {
+	Action        ImportChangeAction `json:"action"`
+	DepartureDate openapi_types.Date `json:"departure_date"`
+
+	// Fields Fields an update changes
+	Fields       *[]ImportFieldChange `json:"fields,omitempty"`
+	FlightNumber string               `json:"flight_number"`
+
+	// Line Line of the schedule the flight was expanded from
+	Line int `json:"line"`
+}
+
+// ImportChangeAction defines model for ImportChange.Action.
+type ImportChangeAction string
+
+// ImportError defines model for ImportError.
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportFieldChange defines model for ImportFieldChange.
+type ImportFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ImportReport defines model for ImportReport.
+type ImportReport struct {
+	Changes []ImportChange `json:"changes"`
+
+	// Created Flights created, or to create on a dry run
+	Created   int           `json:"created"`
+	DryRun    bool          `json:"dry_run"`
+	Errors    []ImportError `json:"errors"`
+	Unchanged int           `json:"unchanged"`
+
+	// Updated Flights updated, or to update on a dry run
+	Updated int `json:"updated"`
+}
+
+// ListAPIKeysResponse defines model for ListAPIKeysResponse.
+type ListAPIKeysResponse struct {
+	Data []APIKey `json:"data"`
+}
+
+// ListAgenciesResponse defines model for ListAgenciesResponse.
+type ListAgenciesResponse struct {
+	Data []Agency `json:"data"`
+}
+
+// ListFlightStatusChangesResponse defines model for ListFlightStatusChangesResponse.
+type ListFlightStatusChangesResponse struct {
+	Data []FlightStatusChange `json:"data"`
+}
+
+// ListSchedulesResponse defines model for ListSchedulesResponse.
+type ListSchedulesResponse struct {
+	Data []Schedule `json:"data"`
+}
+
+// ListWebhooksResponse defines model for ListWebhooksResponse.
+type ListWebhooksResponse struct {
+	Data []Webhook `json:"data"`
+}
+
+// LoginRequest defines model for LoginRequest.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 // Order defines model for Order.
 type Order struct {
-	BookingTime time.Time   `json:"booking_time"`
-	Customer    *Customer   `json:"customer,omitempty"`
-	CustomerId  uint        `json:"customer_id"`
-	Flight      *Flight     `json:"flight,omitempty"`
-	FlightId    uint        `json:"flight_id"`
-	Id          uint        `json:"id"`
-	OrderNumber string      `json:"order_number"`
-	Status      OrderStatus `json:"status"`
+	// AgencyId Agency that booked the order with its API key
+	AgencyId     *uint               `json:"agency_id,omitempty"`
+	BookingTime  time.Time           `json:"booking_time"`
+	Customer     *Customer           `json:"customer,omitempty"`
+	CustomerId   uint                `json:"customer_id"`
+	Entitlements *[]OrderEntitlement `json:"entitlements,omitempty"`
+	Flight       *Flight             `json:"flight,omitempty"`
+	FlightId     uint                `json:"flight_id"`
+	Id           uint                `json:"id"`
+	OrderNumber  string              `json:"order_number"`
+	Status       OrderStatus         `json:"status"`
+
+	// TicketAmount Number of tickets booked
+	TicketAmount int `json:"ticket_amount"`
 
 	// TotalAmount Total amount in smallest currency unit (e.g., cents)
-	TotalAmount int `json:"total_amount"`
+	TotalAmount int         `json:"total_amount"`
+	Travelers   *[]Traveler `json:"travelers,omitempty"`
 }
 
 // OrderStatus defines model for Order.Status.
 type OrderStatus string
 
+// OrderEntitlement defines model for OrderEntitlement.
+type OrderEntitlement struct {
+	GrantedAt time.Time `json:"granted_at"`
+
+	// Kind Cancelling the order for a full refund, also after a cancelled
+	// flight was due to depart. Changing the booking to another flight
+	// is not offered, customers cancel and book again.
+	Kind   OrderEntitlementKind `json:"kind"`
+	Reason string               `json:"reason"`
+
+	// UsedAt When the entitlement was used, a cancellation uses it for the refund
+	UsedAt *time.Time `json:"used_at,omitempty"`
+}
+
+// OrderEntitlementKind Cancelling the order for a full refund, also after a cancelled
+// flight was due to depart. Changing the booking to another flight
+// is not offered, customers cancel and book again.
+type OrderEntitlementKind string
+
+// OrderTicket defines model for OrderTicket.
+type OrderTicket struct {
+	CreatedAt  time.Time `json:"created_at"`
+	CustomerId uint      `json:"customer_id"`
+
+	// Error Reason of a FAILED order
+	Error    *string `json:"error,omitempty"`
+	FlightId uint    `json:"flight_id"`
+	Id       string  `json:"id"`
+
+	// OrderNumber Number the order is persisted with once COMPLETED
+	OrderNumber  string            `json:"order_number"`
+	Status       OrderTicketStatus `json:"status"`
+	TicketAmount int               `json:"ticket_amount"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// OrderTicketStatus defines model for OrderTicket.Status.
+type OrderTicketStatus string
+
 // Pong defines model for Pong.
 type Pong struct {
 	StartTime string `json:"startTime"`
 }
 
+// RefreshRequest defines model for RefreshRequest.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ReplayWebhookRequest defines model for ReplayWebhookRequest.
+type ReplayWebhookRequest struct {
+	From time.Time `json:"from"`
+
+	// To End of the time range, now if not given
+	To *time.Time `json:"to,omitempty"`
+}
+
+// ReplayWebhookResponse defines model for ReplayWebhookResponse.
+type ReplayWebhookResponse struct {
+	// Events Number of events queued for delivery
+	Events int `json:"events"`
+}
+
+// Schedule defines model for Schedule.
+type Schedule struct {
+	Aircraft         string              `json:"aircraft"`
+	Airline          string              `json:"airline"`
+	ArrivalAirport   string              `json:"arrival_airport"`
+	ArrivalDayOffset int                 `json:"arrival_day_offset"`
+	ArrivalTime      string              `json:"arrival_time"`
+	BasePrice        int                 `json:"base_price"`
+	DaysOfWeek       string              `json:"days_of_week"`
+	DepartureAirport string              `json:"departure_airport"`
+	DepartureTime    string              `json:"departure_time"`
+	EffectiveFrom    openapi_types.Date  `json:"effective_from"`
+	EffectiveTo      *openapi_types.Date `json:"effective_to,omitempty"`
+	FlightNumber     string              `json:"flight_number"`
+	Id               uint                `json:"id"`
+	TotalSeats       int                 `json:"total_seats"`
+}
+
 // SearchFlightResponse defines model for SearchFlightResponse.
 type SearchFlightResponse struct {
 	Data []Flight `json:"data"`
 
+	// NextCursor Cursor of the next page, omitted on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+
 	// Page Current page number
 	Page int `json:"page"`
 
 	// PageSize Number of items per page
 	PageSize int `json:"pageSize"`
 
-	// TotalCount Total number of records
-	TotalCount int64 `json:"totalCount"`
+	// TotalCount Total number of records, omitted when includeTotal is false
+	TotalCount *int64 `json:"totalCount,omitempty"`
+}
+
+// SignupRequest defines model for SignupRequest.
+type SignupRequest struct {
+	Email    openapi_types.Email `json:"email"`
+	Name     string              `json:"name"`
+	Password string              `json:"password"`
+	Phone    string              `json:"phone"`
+}
+
+// Suggestion defines model for Suggestion.
+type Suggestion struct {
+	// Distance Number of typos corrected, 0 for a prefix match
+	Distance int    `json:"distance"`
+	Value    string `json:"value"`
+}
+
+// Traveler defines model for Traveler.
+type Traveler struct {
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+	FirstName   string     `json:"first_name"`
+	Id          uint       `json:"id"`
+	LastName    string     `json:"last_name"`
+
+	// Seat Seat assigned at check-in
+	Seat *string `json:"seat,omitempty"`
+}
+
+// TravelerName defines model for TravelerName.
+type TravelerName struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
 }
 
+// UpdateFlightStatusRequest defines model for UpdateFlightStatusRequest.
+type UpdateFlightStatusRequest struct {
+	ArrivalTime *time.Time `json:"arrival_time,omitempty"`
+
+	// DepartureTime New departure time, the arrival moves along unless arrival_time is given
+	DepartureTime *time.Time `json:"departure_time,omitempty"`
+
+	// Reason Reason told to customers
+	Reason *string                         `json:"reason,omitempty"`
+	Status UpdateFlightStatusRequestStatus `json:"status"`
+}
+
+// UpdateFlightStatusRequestStatus defines model for UpdateFlightStatusRequest.Status.
+type UpdateFlightStatusRequestStatus string
+
+// WaitingRoomStatus defines model for WaitingRoomStatus.
+type WaitingRoomStatus struct {
+	// Admitted Whether the token can be used to book now
+	Admitted bool `json:"admitted"`
+
+	// ExpiresIn Seconds left to book once admitted
+	ExpiresIn *int `json:"expires_in,omitempty"`
+	FlightId  uint `json:"flight_id"`
+
+	// Position Place in the queue while not admitted
+	Position *int64 `json:"position,omitempty"`
+	Token    string `json:"token"`
+}
+
+// Webhook defines model for Webhook.
+type Webhook struct {
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// EventTypes Events sent to the webhook, all of them if empty
+	EventTypes []WebhookEventType `json:"event_types"`
+	Id         uint               `json:"id"`
+	Url        string             `json:"url"`
+}
+
+// WebhookEventType defines model for WebhookEventType.
+type WebhookEventType string
+
+// AdmissionToken defines model for AdmissionToken.
+type AdmissionToken = string
+
+// OrderNumber defines model for OrderNumber.
+type OrderNumber = string
+
+// ImportFlightScheduleTextBody defines parameters for ImportFlightSchedule.
+type ImportFlightScheduleTextBody = string
+
+// ImportFlightScheduleParams defines parameters for ImportFlightSchedule.
+type ImportFlightScheduleParams struct {
+	// Format Format of the schedule
+	Format ImportFlightScheduleParamsFormat `form:"format" json:"format"`
+
+	// DryRun Report the changes without writing them
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
+// ImportFlightScheduleParamsFormat defines parameters for ImportFlightSchedule.
+type ImportFlightScheduleParamsFormat string
+
+// AutocompleteFlightsParams defines parameters for AutocompleteFlights.
+type AutocompleteFlightsParams struct {
+	// Q Text typed so far
+	Q string `form:"q" json:"q"`
+
+	// Type Kind of names to suggest
+	Type AutocompleteFlightsParamsType `form:"type" json:"type"`
+
+	// Limit Maximum number of suggestions
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// AutocompleteFlightsParamsType defines parameters for AutocompleteFlights.
+type AutocompleteFlightsParamsType string
+
 // SearchFlightsParams defines parameters for SearchFlights.
 type SearchFlightsParams struct {
 	// DepartureDate Date of departure (YYYY-MM-DD)
 	DepartureDate *openapi_types.Date `form:"departure_date,omitempty" json:"departure_date,omitempty"`
 
-	// Page Page number for pagination
+	// Page Page number for pagination, ignored when a cursor is given
 	Page *int `form:"page,omitempty" json:"page,omitempty"`
 
 	// PageSize Number of items per page
@@ -143,6 +697,14 @@ type SearchFlightsParams struct {
 	// SortOrder Sort order (ascending or descending)
 	SortOrder *SearchFlightsParamsSortOrder `form:"sortOrder,omitempty" json:"sortOrder,omitempty"`
 
+	// Cursor Opaque cursor returned as nextCursor by the previous page. When set,
+	// the page is read by keyset instead of by offset. sortBy and sortOrder
+	// must match the request that produced the cursor.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// IncludeTotal Whether to count the total number of matching records. Infinite-scroll clients can set it to false to skip the count.
+	IncludeTotal *bool `form:"includeTotal,omitempty" json:"includeTotal,omitempty"`
+
 	// Filters Key-value pairs for filtering records. Available filters:
 	// - departure_city: Departure city name
 	// - arrival_city: Arrival city name
@@ -150,7 +712,12 @@ type SearchFlightsParams struct {
 	// - flight_number: Flight number
 	//
 	// Example: filters[departure_city]=New York&filters[arrival_city]=London&filters[airline]=British Airways
+	//
+	// Values are matched case- and accent-insensitively, and % and _ only match themselves.
 	Filters *map[string]string `json:"filters,omitempty"`
+
+	// Match Whether filters match the whole value or only its beginning
+	Match *SearchFlightsParamsMatch `form:"match,omitempty" json:"match,omitempty"`
 }
 
 // SearchFlightsParamsSortBy defines parameters for SearchFlights.
@@ -159,5 +726,59 @@ type SearchFlightsParamsSortBy string
 // SearchFlightsParamsSortOrder defines parameters for SearchFlights.
 type SearchFlightsParamsSortOrder string
 
+// SearchFlightsParamsMatch defines parameters for SearchFlights.
+type SearchFlightsParamsMatch string
+
+// CreateOrderParams defines parameters for CreateOrder.
+type CreateOrderParams struct {
+	// XAdmissionToken Admitted waiting room token, required while the waiting room is enabled
+	XAdmissionToken *AdmissionToken `json:"X-Admission-Token,omitempty"`
+}
+
+// SubmitOrderParams defines parameters for SubmitOrder.
+type SubmitOrderParams struct {
+	// XAdmissionToken Admitted waiting room token, required while the waiting room is enabled
+	XAdmissionToken *AdmissionToken `json:"X-Admission-Token,omitempty"`
+}
+
+// CreateAgencyJSONRequestBody defines body for CreateAgency for application/json ContentType.
+type CreateAgencyJSONRequestBody = CreateAgencyRequest
+
+// CreateAPIKeyJSONRequestBody defines body for CreateAPIKey for application/json ContentType.
+type CreateAPIKeyJSONRequestBody = CreateAPIKeyRequest
+
+// ImportFlightScheduleTextRequestBody defines body for ImportFlightSchedule for text/plain ContentType.
+type ImportFlightScheduleTextRequestBody = ImportFlightScheduleTextBody
+
+// UpdateFlightStatusJSONRequestBody defines body for UpdateFlightStatus for application/json ContentType.
+type UpdateFlightStatusJSONRequestBody = UpdateFlightStatusRequest
+
+// CreateScheduleJSONRequestBody defines body for CreateSchedule for application/json ContentType.
+type CreateScheduleJSONRequestBody = CreateScheduleRequest
+
+// CreateWebhookJSONRequestBody defines body for CreateWebhook for application/json ContentType.
+type CreateWebhookJSONRequestBody = CreateWebhookRequest
+
+// ReplayWebhookJSONRequestBody defines body for ReplayWebhook for application/json ContentType.
+type ReplayWebhookJSONRequestBody = ReplayWebhookRequest
+
+// CreateAgencyCustomerJSONRequestBody defines body for CreateAgencyCustomer for application/json ContentType.
+type CreateAgencyCustomerJSONRequestBody = Customer
+
+// LoginJSONRequestBody defines body for Login for application/json ContentType.
+type LoginJSONRequestBody = LoginRequest
+
+// RefreshTokenJSONRequestBody defines body for RefreshToken for application/json ContentType.
+type RefreshTokenJSONRequestBody = RefreshRequest
+
+// SignupJSONRequestBody defines body for Signup for application/json ContentType.
+type SignupJSONRequestBody = SignupRequest
+
 // CreateOrderJSONRequestBody defines body for CreateOrder for application/json ContentType.
 type CreateOrderJSONRequestBody = CreateOrderRequest
+
+// SubmitOrderJSONRequestBody defines body for SubmitOrder for application/json ContentType.
+type SubmitOrderJSONRequestBody = CreateOrderRequest
+
+// CheckInJSONRequestBody defines body for CheckIn for application/json ContentType.
+type CheckInJSONRequestBody = CheckInRequest
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/model"
+)
+
+// grantAdmin gives a signed up customer the admin role. It takes effect on
+// their next login or token refresh.
+func grantAdmin(args []string) {
+	flags := flag.NewFlagSet("admin", flag.ExitOnError)
+	email := flags.String("email", "", "email of the customer to make an admin")
+	revoke := flags.Bool("revoke", false, "make the admin a customer again")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: admin -email address [-revoke]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if *email == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	role := auth.RoleAdmin
+	if *revoke {
+		role = auth.RoleCustomer
+	}
+	result := connectDatabase().Model(&model.Customer{}).Where("email = ?", *email).Update("role", role)
+	if result.Error != nil {
+		log.Fatal(result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		log.Fatalf("no customer with email %s or already %s", *email, role)
+	}
+	log.Printf("%s is now %s\n", *email, role)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joremysh/tonx/internal/ratelimit"
+)
+
+// getEnv returns the value of the environment variable key, or fallback when
+// it is not set.
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(getEnv(key, strconv.Itoa(fallback)))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(getEnv(key, strconv.FormatFloat(fallback, 'f', -1, 64)), 64)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return value
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(fallback)))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, fallback.String()))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return value
+}
+
+// getEnvList returns the comma separated values of the environment variable
+// key, or fallback when it is not set.
+func getEnvList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getEnvLimit returns the rate limit, like "100/m", of the environment
+// variable key, or fallback when it is not set.
+func getEnvLimit(key string, fallback string) ratelimit.Limit {
+	value, err := ratelimit.ParseLimit(getEnv(key, fallback))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return value
+}
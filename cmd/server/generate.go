@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joremysh/tonx/internal/service"
+)
+
+// generateFlights creates the flights of the recurring schedules once, like
+// the server does every SCHEDULE_INTERVAL.
+func generateFlights(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	days := flags.Int("days", getEnvInt("SCHEDULE_HORIZON_DAYS", 90), "number of days ahead to generate flights for")
+	_ = flags.Parse(args)
+
+	scheduleService := service.NewScheduleService(connectDatabase(), *days)
+	if _, err := scheduleService.Generate(context.Background(), time.Now()); err != nil {
+		log.Fatal(err.Error())
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joremysh/tonx/internal/importer"
+)
+
+// importSchedule imports the flights of a CSV or SSIM schedule file. It only
+// reports the changes unless -apply is set.
+func importSchedule(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", importer.FormatCSV, "schedule format, csv or ssim")
+	apply := flags.Bool("apply", false, "write the changes instead of only reporting them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import [-format csv|ssim] [-apply] file")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer file.Close()
+
+	report, err := importer.ImportSchedule(connectDatabase(), *format, file, !*apply)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	for _, change := range report.Changes {
+		if change.Action == importer.ActionUnchanged {
+			continue
+		}
+		fmt.Printf("line %d: %s %s on %s\n", change.Line, change.Action, change.FlightNumber, change.DepartureDate.Format(time.DateOnly))
+		for _, field := range change.Fields {
+			fmt.Printf("  %s: %s -> %s\n", field.Field, field.From, field.To)
+		}
+	}
+	for _, lineErr := range report.Errors {
+		fmt.Fprintln(os.Stderr, lineErr.Error())
+	}
+	if len(report.Errors) > 0 {
+		log.Fatalf("schedule has %d errors, nothing imported", len(report.Errors))
+	}
+	if report.DryRun {
+		log.Printf("would create %d, update %d and leave %d flights unchanged, run with -apply to import\n", report.Created, report.Updated, report.Unchanged)
+		return
+	}
+	log.Printf("created %d, updated %d and left %d flights unchanged\n", report.Created, report.Updated, report.Unchanged)
+}
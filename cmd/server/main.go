@@ -1,25 +1,38 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
+	"github.com/getkin/kin-openapi/openapi3filter"
 	middleware "github.com/oapi-codegen/gin-middleware"
 
 	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
 	"github.com/joremysh/tonx/internal/handler"
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/notify"
+	"github.com/joremysh/tonx/internal/ratelimit"
 	"github.com/joremysh/tonx/internal/repository"
+	"github.com/joremysh/tonx/internal/service"
+	"github.com/joremysh/tonx/pkg/breaker"
 	"github.com/joremysh/tonx/pkg/cache"
 	"github.com/joremysh/tonx/pkg/database"
+	"github.com/joremysh/tonx/pkg/metrics"
 )
 
-func NewServer(bookingSystem *handler.BookingSystem, port string) *http.Server {
+func NewServer(bookingSystem *handler.BookingSystem, issuer auth.TokenIssuer, keys auth.KeyVerifier, limiter ratelimit.Limiter, limits ratelimit.Config, port string) *http.Server {
 	swagger, err := api.GetSwagger()
 
 	if err != nil {
@@ -31,10 +44,28 @@ func NewServer(bookingSystem *handler.BookingSystem, port string) *http.Server {
 	// that server names match. We don't know how this thing will be run.
 	swagger.Servers = nil
 	r := gin.Default()
+	// Clients are rate limited by address, which only proxies in front of the
+	// server may forward.
+	if err := r.SetTrustedProxies(getEnvList("TRUSTED_PROXIES", nil)); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Use our validation middleware to check all requests against the
-	// OpenAPI schema.
-	r.Use(middleware.OapiRequestValidator(swagger))
+	// OpenAPI schema. It enforces the security requirements of the spec on
+	// the principal of the token or API key the auth middleware found.
+	r.Use(ratelimit.AddressMiddleware(limiter, limits))
+	r.Use(auth.Middleware(issuer, keys))
+	r.Use(ratelimit.Middleware(limiter, limits))
+	// Registered ahead of the validator, which rejects routes missing from
+	// the spec, so admins are checked here. Metrics expose the command line
+	// and memory of the server.
+	r.GET("/debug/vars", auth.RequireAdmin(), gin.WrapH(metrics.Handler()))
+	r.Use(middleware.OapiRequestValidatorWithOptions(swagger, &middleware.Options{
+		ErrorHandler: handler.ValidationErrorHandler,
+		Options: openapi3filter.Options{
+			AuthenticationFunc: auth.AuthenticationFunc,
+		},
+	}))
 
 	api.RegisterHandlers(r, bookingSystem)
 
@@ -46,31 +77,283 @@ func NewServer(bookingSystem *handler.BookingSystem, port string) *http.Server {
 }
 
 func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+		case "reconcile":
+			reconcile(os.Args[2:])
+			return
+		case "migrate":
+			migrateDatabase(os.Args[2:])
+			return
+		case "seed":
+			seedDatabase(os.Args[2:])
+			return
+		case "import":
+			importSchedule(os.Args[2:])
+			return
+		case "generate":
+			generateFlights(os.Args[2:])
+			return
+		case "admin":
+			grantAdmin(os.Args[2:])
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q, expected serve, migrate, seed, import, generate, admin or reconcile\n", os.Args[1])
+			os.Exit(2)
+		}
 	}
-	dsn := os.Getenv("DSN")
+	serve()
+}
+
+// connect opens the database and Redis connections configured in the
+// environment.
+func connect() (*gorm.DB, *cache.RedisClient) {
 	redisHost := os.Getenv("REDIS_HOST")
 	redisPort := os.Getenv("REDIS_PORT")
 
-	gdb, err := database.NewDatabase(dsn)
+	gdb := connectDatabase()
+
+	// REDIS_ADDRS lists the sentinels or cluster nodes, REDIS_HOST and
+	// REDIS_PORT remain for a single server.
+	redisClient, err := cache.NewRedisClient(cache.Config{
+		Mode:             getEnv("REDIS_MODE", cache.ModeStandalone),
+		Addrs:            getEnvList("REDIS_ADDRS", []string{net.JoinHostPort(redisHost, redisPort)}),
+		MasterName:       os.Getenv("REDIS_MASTER_NAME"),
+		Username:         os.Getenv("REDIS_USERNAME"),
+		Password:         os.Getenv("REDIS_PASSWORD"),
+		SentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+		DB:               getEnvInt("REDIS_DB", 0),
+		TLS:              getEnvBool("REDIS_TLS", false),
+		TLSServerName:    os.Getenv("REDIS_TLS_SERVER_NAME"),
+	})
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	err = repository.Migrate(gdb)
+	return gdb, redisClient
+}
+
+// connectDatabase opens the database connection configured in the
+// environment.
+func connectDatabase() *gorm.DB {
+	gdb, err := database.NewDatabase(os.Getenv("DSN"))
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+	return gdb
+}
+
+func serve() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	gdb, redisClient := connect()
+	// Replicas may all migrate on start, the migration lock lets one at a
+	// time through. Turn it off to run "migrate up" as a release step instead.
+	if getEnvBool("MIGRATE_ON_START", true) {
+		if err := repository.Migrate(gdb); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Workers outlive the signal until the server has drained its requests,
+	// so orders accepted while shutting down are still processed.
+	workers, stopWorkers := context.WithCancel(context.Background())
+	var running sync.WaitGroup
+	run := func(work func(ctx context.Context)) {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			work(workers)
+		}()
+	}
+
+	reconciler := service.NewReconciler(gdb, redisClient, getEnvDuration("RECONCILE_GRACE", 2*time.Second))
+
+	var seats inventory.SeatInventory
+	switch backend := getEnv("SEAT_INVENTORY", "redis"); backend {
+	case "redis":
+		seats = inventory.WithBreaker(inventory.NewRedisInventory(redisClient), newSeatBreaker(workers, reconciler))
+	case "memory":
+		// Seats are counted per process, only for a single server.
+		seats = inventory.NewMemoryInventory()
+	default:
+		log.Fatalf("unknown seat inventory %q, expected redis or memory", backend)
+	}
+
+	bookingLimits := service.BookingLimits{
+		MaxTicketsPerOrder:  getEnvInt("BOOKING_MAX_TICKETS_PER_ORDER", 9),
+		MaxTicketsPerFlight: getEnvInt("BOOKING_MAX_TICKETS_PER_FLIGHT", 20),
+		MaxPendingHolds:     getEnvInt("BOOKING_MAX_PENDING_HOLDS", 5),
+	}
+	orderQueue := service.NewOrderQueue(gdb, redisClient, seats, service.OrderQueueConfig{
+		BatchSize:     int64(getEnvInt("ORDER_BATCH_SIZE", 50)),
+		Block:         2 * time.Second,
+		ClaimIdle:     30 * time.Second,
+		MaxDeliveries: int64(getEnvInt("ORDER_MAX_DELIVERIES", 5)),
+		Limits:        bookingLimits,
+	})
+	hostname, _ := os.Hostname()
+	for i := 0; i < getEnvInt("ORDER_WORKERS", 4); i++ {
+		consumer := fmt.Sprintf("%s-%d", hostname, i)
+		run(func(ctx context.Context) {
+			if err := orderQueue.Run(ctx, consumer); err != nil {
+				log.Printf("order worker %s stopped: %v\n", consumer, err)
+			}
+		})
+	}
+
+	if interval := getEnvDuration("RECONCILE_INTERVAL", 5*time.Minute); interval > 0 {
+		run(func(ctx context.Context) { reconciler.Run(ctx, interval) })
+	}
 
-	redisClient, err := cache.NewRedisClient(net.JoinHostPort(redisHost, redisPort))
+	// Every replica generates, the unique flight number and date keep the
+	// flights from being created twice.
+	scheduleService := service.NewScheduleService(gdb, getEnvInt("SCHEDULE_HORIZON_DAYS", 90))
+	if interval := getEnvDuration("SCHEDULE_INTERVAL", time.Hour); interval > 0 {
+		run(func(ctx context.Context) { scheduleService.Run(ctx, interval) })
+	}
+
+	issuer, err := auth.NewTokenIssuer(auth.TokenConfig{
+		Secret:     []byte(os.Getenv("JWT_SECRET")),
+		Issuer:     getEnv("JWT_ISSUER", "tonx"),
+		AccessTTL:  getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTTL: getEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+	})
 	if err != nil {
-		log.Fatal(err.Error())
+		log.Fatalf("invalid JWT_SECRET: %v", err)
+	}
+
+	apiKeyService := service.NewAPIKeyService(gdb, redisClient)
+
+	webhookService := service.NewWebhookService(gdb, service.WebhookConfig{
+		BatchSize:   getEnvInt("WEBHOOK_BATCH_SIZE", 100),
+		MaxAttempts: getEnvInt("WEBHOOK_MAX_ATTEMPTS", 12),
+		Timeout:     getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		Backoff:     getEnvDuration("WEBHOOK_BACKOFF", 10*time.Second),
+		MaxBackoff:  getEnvDuration("WEBHOOK_MAX_BACKOFF", 6*time.Hour),
+	})
+	if interval := getEnvDuration("WEBHOOK_INTERVAL", time.Second); interval > 0 {
+		run(func(ctx context.Context) { webhookService.Run(ctx, interval) })
+	}
+
+	// Notifications are queued either way, and sent once SMTP is configured.
+	// Without it they expire, so the queue does not grow forever.
+	var mailer notify.Mailer
+	if smtpHost := getEnv("SMTP_HOST", ""); smtpHost != "" {
+		mailer, err = notify.NewSMTPMailer(notify.SMTPConfig{
+			Host:     smtpHost,
+			Port:     getEnvInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("SMTP_FROM", "Tonx <no-reply@tonx.example>"),
+			StartTLS: getEnvBool("SMTP_STARTTLS", false),
+		})
+		if err != nil {
+			log.Fatalf("invalid SMTP_FROM: %v", err)
+		}
+	} else {
+		log.Println("SMTP_HOST is not set, booking emails are queued but not sent")
+	}
+	notifier := service.NewNotifier(gdb, mailer, service.NotificationConfig{
+		BatchSize:   getEnvInt("NOTIFICATION_BATCH_SIZE", 50),
+		MaxAttempts: getEnvInt("NOTIFICATION_MAX_ATTEMPTS", 8),
+		Timeout:     getEnvDuration("SMTP_TIMEOUT", 30*time.Second),
+		Backoff:     getEnvDuration("NOTIFICATION_BACKOFF", 30*time.Second),
+		MaxBackoff:  getEnvDuration("NOTIFICATION_MAX_BACKOFF", time.Hour),
+		MaxAge:      getEnvDuration("NOTIFICATION_MAX_AGE", 24*time.Hour),
+	})
+	if interval := getEnvDuration("NOTIFICATION_INTERVAL", 2*time.Second); interval > 0 {
+		run(func(ctx context.Context) { notifier.Run(ctx, interval) })
 	}
 
 	handler.StartUp = time.Now().Format(time.RFC3339)
-	bookingSystem := handler.NewBookingSystem(gdb, redisClient)
-	s := NewServer(bookingSystem, port)
+	bookingSystem := handler.NewBookingSystem(gdb, redisClient, seats, orderQueue, scheduleService, issuer, apiKeyService, webhookService, handler.Config{
+		MaxStreams:      getEnvInt("SSE_MAX_CONNECTIONS", 1000),
+		StreamHeartbeat: getEnvDuration("SSE_HEARTBEAT", 15*time.Second),
+		WaitingRoom: service.WaitingRoomConfig{
+			Enabled:      getEnvBool("WAITING_ROOM_ENABLED", false),
+			Rate:         getEnvFloat("WAITING_ROOM_RATE", 50),
+			Burst:        getEnvInt("WAITING_ROOM_BURST", 100),
+			AdmissionTTL: getEnvDuration("WAITING_ROOM_ADMISSION_TTL", 5*time.Minute),
+		},
+		MaxImportBytes: int64(getEnvInt("IMPORT_MAX_BYTES", 10<<20)),
+		BookingLimits:  bookingLimits,
+		CheckIn: service.CheckInConfig{
+			Opens:  getEnvDuration("CHECK_IN_OPENS", 24*time.Hour),
+			Closes: getEnvDuration("CHECK_IN_CLOSES", time.Hour),
+		},
+		FlightStatus: service.FlightStatusConfig{
+			BigDelay: getEnvDuration("FLIGHT_BIG_DELAY", 3*time.Hour),
+		},
+		PublicURL: getEnv("PUBLIC_URL", ""),
+	})
+	limits := ratelimit.Config{
+		Enabled: getEnvBool("RATE_LIMIT_ENABLED", true),
+		Default: getEnvLimit("RATE_LIMIT_DEFAULT", "300/m"),
+		Rules: []ratelimit.Rule{{
+			Name:   "orders",
+			Method: http.MethodPost,
+			Path:   "/api/v1/orders",
+			Limit:  getEnvLimit("RATE_LIMIT_ORDERS", "20/m"),
+		}, {
+			Name:   "auth",
+			Method: http.MethodPost,
+			Path:   "/api/v1/auth",
+			Limit:  getEnvLimit("RATE_LIMIT_AUTH", "10/m"),
+		}},
+		Address: getEnvLimit("RATE_LIMIT_ADDRESS", "600/m"),
+	}
+	s := NewServer(bookingSystem, issuer, apiKeyService, ratelimit.NewRedisLimiter(redisClient), limits, port)
+	// Availability streams never end by themselves and would hold shutdown up
+	s.RegisterOnShutdown(bookingSystem.CloseStreams)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Println("shutting down, draining requests")
+	shutdown, cancel := context.WithTimeout(context.Background(), getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+	if err := s.Shutdown(shutdown); err != nil {
+		log.Printf("failed to drain requests: %v\n", err)
+	}
+	stopWorkers()
+	running.Wait()
+	log.Println("server stopped")
+}
 
-	log.Fatal(s.ListenAndServe())
+// newSeatBreaker creates the circuit breaker around the Redis seat counters.
+// Bookings go to the database alone while it is open, so the counters are
+// rebuilt from the database once Redis answers again.
+func newSeatBreaker(ctx context.Context, reconciler service.Reconciler) *breaker.Breaker {
+	return breaker.New(breaker.Settings{
+		MaxFailures: getEnvInt("SEAT_BREAKER_MAX_FAILURES", 5),
+		OpenTimeout: getEnvDuration("SEAT_BREAKER_OPEN_TIMEOUT", 10*time.Second),
+		OnStateChange: func(from, to breaker.State) {
+			log.Printf("seat inventory breaker changed from %s to %s\n", from, to)
+			switch to {
+			case breaker.StateOpen:
+				metrics.Inc("seat_breaker_opened")
+			case breaker.StateClosed:
+				metrics.Inc("seat_breaker_closed")
+				go func() {
+					if err := reconciler.Rebuild(ctx); err != nil {
+						log.Printf("failed to rebuild seat counters: %v\n", err)
+					}
+				}()
+			}
+		},
+	})
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+// migrateDatabase applies, rolls back or lists the schema migrations.
+func migrateDatabase(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	steps := flags.Int("steps", 1, "number of migrations to roll back with down")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: migrate [-steps n] up|down|status")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	migrator, err := repository.NewMigrator(connectDatabase())
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	ctx := context.Background()
+
+	switch flags.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		log.Printf("applied %d migrations\n", applied)
+	case "down":
+		rolledBack, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		log.Printf("rolled back %d migrations\n", rolledBack)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+		_ = w.Flush()
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joremysh/tonx/internal/service"
+)
+
+// reconcile repairs seat inventory drift of all active flights once.
+func reconcile(args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report drift without repairing it")
+	grace := flags.Duration("grace", 2*time.Second, "time for bookings in progress to settle before Redis is repaired")
+	_ = flags.Parse(args)
+
+	gdb, redisClient := connect()
+	report, err := service.NewReconciler(gdb, redisClient, *grace).Reconcile(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if len(report.Drifts) > 0 && *dryRun {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joremysh/tonx/internal/seed"
+)
+
+// seedDatabase inserts a generated dataset of flights, customers and orders.
+func seedDatabase(args []string) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	seedValue := flags.Uint64("seed", 1, "seed of the generator, the same seed generates the same dataset")
+	flights := flags.Int("flights", 50, "number of flights to generate")
+	customers := flags.Int("customers", 20, "number of customers to generate")
+	from := flags.String("from", today.Format(time.DateOnly), "first departure date")
+	to := flags.String("to", today.AddDate(0, 0, 30).Format(time.DateOnly), "last departure date")
+	orders := flags.Bool("orders", false, "also generate the orders booked until -now")
+	now := flags.String("now", today.Format(time.DateOnly), "date orders are booked until, fixed for reproducible orders")
+	_ = flags.Parse(args)
+
+	opts := seed.Options{
+		Seed:      *seedValue,
+		Flights:   *flights,
+		Customers: *customers,
+		From:      parseDate("from", *from),
+		// The last departure date is inclusive
+		To:     parseDate("to", *to).AddDate(0, 0, 1),
+		Orders: *orders,
+		Now:    parseDate("now", *now),
+	}
+	dataset, err := seed.Generate(opts)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	result, err := seed.Load(connectDatabase(), dataset)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	log.Printf("seeded %d flights, %d customers and %d orders\n", result.Flights, result.Customers, result.Orders)
+}
+
+func parseDate(name, value string) time.Time {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		log.Fatalf("invalid -%s: %v", name, err)
+	}
+	return date
+}
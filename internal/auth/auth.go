@@ -0,0 +1,73 @@
+// Package auth issues and verifies the tokens of signed in customers and
+// admins, and carries who made a request in its context.
+package auth
+
+import (
+	"context"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Roles
+const (
+	RoleCustomer = "CUSTOMER"
+	RoleAdmin    = "ADMIN"
+	RoleAgency   = "AGENCY"
+)
+
+// API key scopes
+const (
+	ScopeSearch  = "search"
+	ScopeBooking = "booking"
+)
+
+// Principal is who a request is made by, a customer or admin signed in with a
+// token, or an agency calling with an API key
+type Principal struct {
+	CustomerID uint
+	Role       string
+	AgencyID   uint
+	APIKeyID   uint
+	// Scopes are the scopes of the API key
+	Scopes []string
+	// BookingQuota is the orders the API key may book per UTC day, 0 for no
+	// limit
+	BookingQuota int
+}
+
+// IsAdmin reports whether the principal may use the admin routes
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// IsAgency reports whether the principal called with an API key
+func (p Principal) IsAgency() bool {
+	return p.Role == RoleAgency
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal carried by ctx, if any
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// HashPassword returns the bcrypt hash of a password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches a hash from HashPassword
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
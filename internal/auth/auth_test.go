@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+	middleware "github.com/oapi-codegen/gin-middleware"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/api"
+)
+
+func testIssuer(t *testing.T, accessTTL time.Duration) TokenIssuer {
+	issuer, err := NewTokenIssuer(TokenConfig{
+		Secret:     []byte(strings.Repeat("s", 32)),
+		Issuer:     "tonx",
+		AccessTTL:  accessTTL,
+		RefreshTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	return issuer
+}
+
+func TestTokenIssuer(t *testing.T) {
+	issuer := testIssuer(t, time.Minute)
+	tokens, err := issuer.Issue(Principal{CustomerID: 42, Role: RoleAdmin})
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, tokens.ExpiresIn)
+
+	principal, err := issuer.Verify(tokens.AccessToken, TokenAccess)
+	require.NoError(t, err)
+	require.Equal(t, Principal{CustomerID: 42, Role: RoleAdmin}, principal)
+
+	principal, err = issuer.Verify(tokens.RefreshToken, TokenRefresh)
+	require.NoError(t, err)
+	require.Equal(t, uint(42), principal.CustomerID)
+
+	_, err = issuer.Verify(tokens.RefreshToken, TokenAccess)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = issuer.Verify(tokens.AccessToken+"x", TokenAccess)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	other, err := NewTokenIssuer(TokenConfig{Secret: []byte(strings.Repeat("o", 32)), Issuer: "tonx", AccessTTL: time.Minute})
+	require.NoError(t, err)
+	_, err = other.Verify(tokens.AccessToken, TokenAccess)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	expired := testIssuer(t, -time.Minute)
+	tokens, err = expired.Issue(Principal{CustomerID: 42, Role: RoleCustomer})
+	require.NoError(t, err)
+	_, err = issuer.Verify(tokens.AccessToken, TokenAccess)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewTokenIssuer(TokenConfig{Secret: []byte("short")})
+	require.Error(t, err)
+}
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	require.True(t, CheckPassword(hash, "correct horse"))
+	require.False(t, CheckPassword(hash, "battery staple"))
+}
+
+// stubKeys verifies the API keys of a fixed set of principals.
+type stubKeys map[string]Principal
+
+func (k stubKeys) VerifyKey(_ context.Context, key string) (Principal, error) {
+	if key == "limited" {
+		return Principal{}, ErrRateLimited
+	}
+	principal, ok := k[key]
+	if !ok {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	issuer := testIssuer(t, time.Minute)
+	customer, err := issuer.Issue(Principal{CustomerID: 1, Role: RoleCustomer})
+	require.NoError(t, err)
+	admin, err := issuer.Issue(Principal{CustomerID: 2, Role: RoleAdmin})
+	require.NoError(t, err)
+
+	swagger, err := api.GetSwagger()
+	require.NoError(t, err)
+	swagger.Servers = nil
+
+	keys := stubKeys{
+		"booking": {AgencyID: 1, APIKeyID: 1, Role: RoleAgency, Scopes: []string{ScopeBooking}},
+		"search":  {AgencyID: 1, APIKeyID: 2, Role: RoleAgency, Scopes: []string{ScopeSearch}},
+	}
+
+	r := gin.New()
+	r.Use(Middleware(issuer, keys))
+	r.Use(middleware.OapiRequestValidatorWithOptions(swagger, &middleware.Options{
+		ErrorHandler: func(c *gin.Context, message string, statusCode int) {
+			if status, ok := c.Get(StatusKey); ok {
+				statusCode = status.(int)
+			}
+			c.AbortWithStatusJSON(statusCode, api.Error{Code: statusCode, Message: message})
+		},
+		Options: openapi3filter.Options{AuthenticationFunc: AuthenticationFunc},
+	}))
+	handle := func(c *gin.Context) {
+		principal, _ := PrincipalFrom(c.Request.Context())
+		c.JSON(http.StatusOK, principal)
+	}
+	r.POST("/api/v1/orders", handle)
+	r.GET("/api/v1/admin/schedules", handle)
+	r.GET("/liveness", handle)
+
+	request := func(method, path, token, key string) int {
+		var body *strings.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(`{"flight_id":1,"ticket_amount":1}`)
+		} else {
+			body = strings.NewReader("")
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/liveness", "", ""))
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/liveness", "garbage", ""))
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/api/v1/orders", "", ""))
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/api/v1/orders", customer.RefreshToken, ""))
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "/api/v1/orders", customer.AccessToken, ""))
+	require.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api/v1/admin/schedules", customer.AccessToken, ""))
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/admin/schedules", admin.AccessToken, ""))
+
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/api/v1/orders", "", "unknown"))
+	require.Equal(t, http.StatusTooManyRequests, request(http.MethodPost, "/api/v1/orders", "", "limited"))
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "/api/v1/orders", "", "booking"))
+	require.Equal(t, http.StatusForbidden, request(http.MethodPost, "/api/v1/orders", "", "search"))
+	require.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api/v1/admin/schedules", "", "booking"))
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	issuer := testIssuer(t, time.Minute)
+	customer, err := issuer.Issue(Principal{CustomerID: 1, Role: RoleCustomer})
+	require.NoError(t, err)
+	admin, err := issuer.Issue(Principal{CustomerID: 2, Role: RoleAdmin})
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(Middleware(issuer, stubKeys{}))
+	r.GET("/debug/vars", RequireAdmin(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized, request(""))
+	require.Equal(t, http.StatusForbidden, request(customer.AccessToken))
+	require.Equal(t, http.StatusOK, request(admin.AccessToken))
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+	middleware "github.com/oapi-codegen/gin-middleware"
+
+	"github.com/joremysh/tonx/api"
+)
+
+// ScopeAdmin is the security requirement scope of admin routes
+const ScopeAdmin = "admin"
+
+// StatusKey is the gin context key of the status code a failed security
+// requirement should be answered with
+const StatusKey = "auth/status"
+
+// APIKeyHeader is the header agencies send their API key in
+const APIKeyHeader = "X-API-Key"
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("not allowed to use this route")
+	ErrInvalidAPIKey   = errors.New("invalid API key")
+	ErrRateLimited     = errors.New("rate limit exceeded")
+)
+
+// KeyVerifier defines the interface for looking up the principal of an API
+// key. It returns ErrInvalidAPIKey for unknown or revoked keys and
+// ErrRateLimited for keys over their rate limit.
+type KeyVerifier interface {
+	VerifyKey(ctx context.Context, key string) (Principal, error)
+}
+
+// Middleware puts the principal of the bearer token or API key of a request,
+// if there is one, into the request context. Requests with an invalid token
+// or key are refused, whether a route needs a principal is up to
+// AuthenticationFunc.
+func Middleware(issuer TokenIssuer, keys KeyVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			principal, err := keys.VerifyKey(c.Request.Context(), key)
+			if err != nil {
+				switch {
+				case errors.Is(err, ErrInvalidAPIKey):
+					abort(c, http.StatusUnauthorized, err.Error())
+				case errors.Is(err, ErrRateLimited):
+					abort(c, http.StatusTooManyRequests, err.Error())
+				default:
+					abort(c, http.StatusInternalServerError, err.Error())
+				}
+				return
+			}
+			c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			abort(c, http.StatusUnauthorized, "authorization must be a bearer token")
+			return
+		}
+		principal, err := issuer.Verify(token, TokenAccess)
+		if err != nil {
+			abort(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireAdmin refuses requests that Middleware found no admin for, for
+// routes outside the API spec, which AuthenticationFunc does not see.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFrom(c.Request.Context())
+		switch {
+		case !ok:
+			abort(c, http.StatusUnauthorized, ErrUnauthenticated.Error())
+		case !principal.IsAdmin():
+			abort(c, http.StatusForbidden, ErrForbidden.Error())
+		default:
+			c.Next()
+		}
+	}
+}
+
+// AuthenticationFunc enforces the security requirements of the API spec for
+// the OpenAPI validator. Bearer routes with the admin scope need an admin,
+// API key routes need a key with all of their scopes.
+func AuthenticationFunc(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+	status, err := authenticate(input)
+	if err != nil {
+		if c := middleware.GetGinContext(ctx); c != nil {
+			c.Set(StatusKey, status)
+		}
+	}
+	return err
+}
+
+func authenticate(input *openapi3filter.AuthenticationInput) (int, error) {
+	principal, ok := PrincipalFrom(input.RequestValidationInput.Request.Context())
+	if !ok {
+		return http.StatusUnauthorized, ErrUnauthenticated
+	}
+	switch scheme := input.SecurityScheme; {
+	case scheme.Type == "http" && scheme.Scheme == "bearer":
+		if principal.IsAgency() || slices.Contains(input.Scopes, ScopeAdmin) && !principal.IsAdmin() {
+			return http.StatusForbidden, ErrForbidden
+		}
+	case scheme.Type == "apiKey":
+		if !principal.IsAgency() {
+			return http.StatusForbidden, ErrForbidden
+		}
+		for _, scope := range input.Scopes {
+			if !slices.Contains(principal.Scopes, scope) {
+				return http.StatusForbidden, fmt.Errorf("API key lacks the %s scope", scope)
+			}
+		}
+	default:
+		return http.StatusForbidden, ErrForbidden
+	}
+	return http.StatusOK, nil
+}
+
+func abort(c *gin.Context, code int, message string) {
+	c.AbortWithStatusJSON(code, api.Error{
+		Code:    code,
+		Message: message,
+	})
+}
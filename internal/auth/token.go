@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token types
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Tokens are the tokens issued on sign in
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn is how long the access token is valid for
+	ExpiresIn time.Duration
+}
+
+// TokenIssuer defines the interface for issuing and verifying signed tokens
+type TokenIssuer interface {
+	// Issue returns a new access and refresh token for p
+	Issue(p Principal) (*Tokens, error)
+	// Verify returns the principal of a token of the given type
+	Verify(token, tokenType string) (Principal, error)
+}
+
+// TokenConfig holds the signing key and lifetimes of tokens
+type TokenConfig struct {
+	Secret     []byte
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// claims are the JWT claims of a token, the subject is the customer ID
+type claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+	Type string `json:"typ"`
+}
+
+// tokenIssuer implements TokenIssuer with HMAC signed JWTs
+type tokenIssuer struct {
+	cfg TokenConfig
+}
+
+// NewTokenIssuer creates a new instance of TokenIssuer
+func NewTokenIssuer(cfg TokenConfig) (TokenIssuer, error) {
+	if len(cfg.Secret) < 32 {
+		return nil, errors.New("token secret must be at least 32 bytes")
+	}
+	return &tokenIssuer{cfg: cfg}, nil
+}
+
+func (t *tokenIssuer) Issue(p Principal) (*Tokens, error) {
+	now := time.Now()
+	access, err := t.sign(p, TokenAccess, now, t.cfg.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := t.sign(p, TokenRefresh, now, t.cfg.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    t.cfg.AccessTTL,
+	}, nil
+}
+
+func (t *tokenIssuer) sign(p Principal, tokenType string, now time.Time, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.cfg.Issuer,
+			Subject:   strconv.FormatUint(uint64(p.CustomerID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Role: p.Role,
+		Type: tokenType,
+	})
+	signed, err := token.SignedString(t.cfg.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+func (t *tokenIssuer) Verify(token, tokenType string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return t.cfg.Secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if c.Type != tokenType {
+		return Principal{}, fmt.Errorf("%w: expected %s token, got %q", ErrInvalidToken, tokenType, c.Type)
+	}
+	customerID, err := strconv.ParseUint(c.Subject, 10, 0)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+	return Principal{CustomerID: uint(customerID), Role: c.Role}, nil
+}
@@ -0,0 +1,44 @@
+package boarding
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/pdf417"
+)
+
+// BarcodeContentType is the media type of boarding pass barcodes
+const BarcodeContentType = "image/png"
+
+// Barcode encodes a BCBP string as a PDF417 barcode PNG, the symbology
+// boarding pass scanners read. Modules are scaled to 3 pixels so the code
+// prints and displays sharply.
+func Barcode(bcbp string) ([]byte, error) {
+	// Security level 5 is the level of error correction BCBP recommends
+	code, err := pdf417.Encode(bcbp, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pdf417: %w", err)
+	}
+	bounds := code.Bounds()
+	return BarcodePNG(code, bounds.Dx()*3, bounds.Dy()*3)
+}
+
+// BarcodePNG scales a barcode and encodes it as an 8-bit grayscale PNG, the
+// barcodes are 16-bit which PDFs cannot embed.
+func BarcodePNG(code barcode.Barcode, width, height int) ([]byte, error) {
+	scaled, err := barcode.Scale(code, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale %s: %w", code.Metadata().CodeKind, err)
+	}
+	gray := image.NewGray(scaled.Bounds())
+	draw.Draw(gray, gray.Bounds(), scaled, scaled.Bounds().Min, draw.Src)
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, gray); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", code.Metadata().CodeKind, err)
+	}
+	return buf.Bytes(), nil
+}
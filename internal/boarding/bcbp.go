@@ -0,0 +1,176 @@
+// Package boarding lays out the seats of flights and encodes boarding passes
+// in the IATA Bar Coded Boarding Pass (BCBP) format.
+package boarding
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var ErrInvalidPass = errors.New("invalid boarding pass")
+
+// Passenger statuses of BCBP
+const (
+	StatusNotCheckedIn = '0'
+	StatusCheckedIn    = '1'
+)
+
+// Pass is a single leg boarding pass
+type Pass struct {
+	FirstName string
+	LastName  string
+	// RecordLocator is the booking reference, at most 7 characters
+	RecordLocator string
+	From          string // IATA airport codes
+	To            string
+	// FlightNumber is the carrier designator followed by the number and an
+	// optional suffix, like "BR198"
+	FlightNumber string
+	// Date is the local departure date
+	Date time.Time
+	// Compartment is the cabin class code, Y for economy
+	Compartment byte
+	Seat        string
+	// Sequence is the check-in sequence number of the passenger on the flight
+	Sequence int
+	Status   byte
+}
+
+// Encode returns the mandatory items of the pass in the BCBP format, 60
+// characters for one leg without conditional items.
+func (p Pass) Encode() (string, error) {
+	carrier, number, suffix, err := SplitFlightNumber(p.FlightNumber)
+	if err != nil {
+		return "", err
+	}
+	seat, err := bcbpSeat(p.Seat)
+	if err != nil {
+		return "", err
+	}
+	name, err := passengerName(p.FirstName, p.LastName)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(p.RecordLocator) == 0 || len(p.RecordLocator) > 7:
+		return "", fmt.Errorf("%w: record locator %q", ErrInvalidPass, p.RecordLocator)
+	case len(p.From) != 3 || len(p.To) != 3:
+		return "", fmt.Errorf("%w: airports %q and %q", ErrInvalidPass, p.From, p.To)
+	case p.Sequence < 0 || p.Sequence > 9999:
+		return "", fmt.Errorf("%w: sequence number %d", ErrInvalidPass, p.Sequence)
+	}
+	compartment := p.Compartment
+	if compartment == 0 {
+		compartment = 'Y'
+	}
+	status := p.Status
+	if status == 0 {
+		status = StatusCheckedIn
+	}
+
+	var b strings.Builder
+	b.WriteString("M1") // format code and number of legs
+	b.WriteString(pad(name, 20))
+	b.WriteByte('E') // electronic ticket
+	b.WriteString(pad(strings.ToUpper(p.RecordLocator), 7))
+	b.WriteString(strings.ToUpper(p.From))
+	b.WriteString(strings.ToUpper(p.To))
+	b.WriteString(pad(carrier, 3))
+	fmt.Fprintf(&b, "%04d%s", number, pad(suffix, 1))
+	fmt.Fprintf(&b, "%03d", p.Date.YearDay())
+	b.WriteByte(compartment)
+	b.WriteString(seat)
+	fmt.Fprintf(&b, "%04d ", p.Sequence)
+	b.WriteByte(status)
+	b.WriteString("00") // no conditional items
+	return b.String(), nil
+}
+
+// SplitFlightNumber splits a flight number into the two character carrier
+// designator, the number and the operational suffix, if any
+func SplitFlightNumber(flightNumber string) (string, int, string, error) {
+	flightNumber = strings.ToUpper(strings.ReplaceAll(flightNumber, " ", ""))
+	if len(flightNumber) < 3 {
+		return "", 0, "", fmt.Errorf("%w: flight number %q", ErrInvalidPass, flightNumber)
+	}
+	carrier, rest := flightNumber[:2], flightNumber[2:]
+	suffix := ""
+	if last := rest[len(rest)-1]; last >= 'A' && last <= 'Z' {
+		rest, suffix = rest[:len(rest)-1], string(last)
+	}
+	number, err := strconv.Atoi(rest)
+	if err != nil || number < 1 || number > 9999 {
+		return "", 0, "", fmt.Errorf("%w: flight number %q", ErrInvalidPass, flightNumber)
+	}
+	return carrier, number, suffix, nil
+}
+
+// bcbpSeat formats a seat like "12A" as the four characters "012A"
+func bcbpSeat(seat string) (string, error) {
+	seat = strings.ToUpper(seat)
+	if len(seat) < 2 || len(seat) > 4 {
+		return "", fmt.Errorf("%w: seat %q", ErrInvalidPass, seat)
+	}
+	row, err := strconv.Atoi(seat[:len(seat)-1])
+	if err != nil || row < 1 || row > 999 {
+		return "", fmt.Errorf("%w: seat %q", ErrInvalidPass, seat)
+	}
+	return fmt.Sprintf("%03d%c", row, seat[len(seat)-1]), nil
+}
+
+// passengerName returns the name as "LAST/FIRST" in upper case letters,
+// which is all BCBP allows
+func passengerName(first, last string) (string, error) {
+	latinFirst, ok := LatinName(first)
+	if !ok {
+		return "", fmt.Errorf("%w: first name %q", ErrInvalidPass, first)
+	}
+	latinLast, ok := LatinName(last)
+	if !ok {
+		return "", fmt.Errorf("%w: last name %q", ErrInvalidPass, last)
+	}
+	return latinLast + "/" + latinFirst, nil
+}
+
+// latinLetters transliterates the Latin letters that do not decompose into
+// a letter and accents, as travel documents do
+var latinLetters = strings.NewReplacer("Æ", "AE", "Ø", "OE", "ß", "SS", "ẞ", "SS", "Ł", "L", "Đ", "D", "Þ", "TH", "Œ", "OE")
+
+// LatinName returns a name in the upper case letters A to Z of boarding
+// passes. Accents are dropped from letters, other characters separate the
+// words of the name. It reports false if the name has no letters, or letters
+// of another script, like Chinese or Cyrillic, which travelers are to give
+// as written in the Latin letters of their travel documents instead.
+func LatinName(name string) (string, bool) {
+	var letters strings.Builder
+	for _, r := range norm.NFD.String(strings.ToUpper(name)) {
+		if !unicode.Is(unicode.Mn, r) {
+			letters.WriteRune(r)
+		}
+	}
+	words := strings.FieldsFunc(latinLetters.Replace(letters.String()), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		for _, r := range word {
+			if r < 'A' || r > 'Z' {
+				return "", false
+			}
+		}
+	}
+	return strings.Join(words, " "), len(words) > 0
+}
+
+// pad pads s with spaces to n characters, or truncates it
+func pad(s string, n int) string {
+	if len(s) >= n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
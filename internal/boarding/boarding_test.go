@@ -0,0 +1,107 @@
+package boarding
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPass_Encode(t *testing.T) {
+	// The example of the IATA BCBP implementation guide
+	pass := Pass{
+		FirstName:     "Luc",
+		LastName:      "Desmarais",
+		RecordLocator: "abc123",
+		From:          "YUL",
+		To:            "FRA",
+		FlightNumber:  "AC834",
+		Date:          time.Date(2025, 11, 22, 0, 0, 0, 0, time.UTC),
+		Compartment:   'J',
+		Seat:          "1a",
+		Sequence:      25,
+	}
+	bcbp, err := pass.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 326J001A0025 100", bcbp)
+	require.Len(t, bcbp, 60)
+
+	// Names are cut to 20 characters without accents
+	pass.FirstName, pass.LastName = "Jean-Éric", "Lefèvre-Desmarais-Beaulieu"
+	pass.FlightNumber, pass.Compartment = "BR12A", 0
+	bcbp, err = pass.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "M1LEFEVRE DESMARAIS BE", bcbp[:22])
+	require.Equal(t, "BR 0012A326Y", bcbp[36:48])
+
+	for _, invalid := range []Pass{
+		{RecordLocator: "ABC123", From: "YUL", To: "FRA", FlightNumber: "AC", Seat: "1A"},
+		{RecordLocator: "ABC123", From: "YUL", To: "FRA", FlightNumber: "AC12345", Seat: "1A"},
+		{RecordLocator: "ABC123", From: "YUL", To: "FRA", FlightNumber: "AC834", Seat: "A"},
+		{RecordLocator: "ABC12345", From: "YUL", To: "FRA", FlightNumber: "AC834", Seat: "1A"},
+		{RecordLocator: "ABC123", From: "", To: "FRA", FlightNumber: "AC834", Seat: "1A"},
+		{FirstName: "美玲", LastName: "林", RecordLocator: "ABC123", From: "TPE", To: "NRT", FlightNumber: "BR198", Seat: "1A"},
+		{FirstName: "Luc", LastName: "-", RecordLocator: "ABC123", From: "YUL", To: "FRA", FlightNumber: "AC834", Seat: "1A"},
+	} {
+		_, err = invalid.Encode()
+		require.ErrorIs(t, err, ErrInvalidPass)
+	}
+}
+
+func TestLatinName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Desmarais":        "DESMARAIS",
+		"Jean-Éric":        "JEAN ERIC",
+		"Dvořák":           "DVORAK",
+		"Łukasz":           "LUKASZ",
+		"Søren Kierkegård": "SOEREN KIERKEGARD",
+		"O'Brien":          "O BRIEN",
+	} {
+		latin, ok := LatinName(name)
+		require.True(t, ok, name)
+		require.Equal(t, want, latin)
+	}
+	for _, name := range []string{"林", "Лена", "Mei 林", "", " - "} {
+		_, ok := LatinName(name)
+		require.False(t, ok, name)
+	}
+}
+
+func TestSeatMap(t *testing.T) {
+	seats := NewSeatMap(50)
+	require.Equal(t, "1A", seats.Seat(0))
+	require.Equal(t, "1F", seats.Seat(3))
+	require.Equal(t, "2A", seats.Seat(4))
+	require.Equal(t, "13C", seats.Seat(49))
+
+	i, ok := seats.Index("13c")
+	require.True(t, ok)
+	require.Equal(t, 49, i)
+	for _, seat := range []string{"13D", "1B", "0A", "01A", "A", "", "-1A"} {
+		_, ok = seats.Index(seat)
+		require.False(t, ok, seat)
+	}
+
+	next, ok := seats.Next(map[string]bool{"1A": true, "1C": true})
+	require.True(t, ok)
+	require.Equal(t, "1D", next)
+	taken := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		taken[seats.Seat(i)] = true
+	}
+	_, ok = seats.Next(taken)
+	require.False(t, ok)
+
+	require.Equal(t, "ABCDEF", NewSeatMap(180).Letters)
+	require.Equal(t, "34K", NewSeatMap(300).Seat(305))
+}
+
+func TestBarcode(t *testing.T) {
+	code, err := Barcode("M1DESMARAIS/LUC       EABC123 YULFRAAC 0834 326J001A0025 100")
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(code))
+	require.NoError(t, err)
+	require.Greater(t, img.Bounds().Dx(), img.Bounds().Dy())
+}
@@ -0,0 +1,61 @@
+package boarding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SeatMap is the cabin layout of a flight, rows of seats lettered from the
+// left. Aircraft of more than 220 seats are taken as wide-bodies.
+type SeatMap struct {
+	// Letters are the seats of a row, I is skipped as in IATA layouts
+	Letters string
+	Total   int
+}
+
+// NewSeatMap returns the seat map of a flight with totalSeats seats
+func NewSeatMap(totalSeats int) SeatMap {
+	switch {
+	case totalSeats <= 100:
+		return SeatMap{Letters: "ACDF", Total: totalSeats}
+	case totalSeats <= 220:
+		return SeatMap{Letters: "ABCDEF", Total: totalSeats}
+	default:
+		return SeatMap{Letters: "ABCDEFGHK", Total: totalSeats}
+	}
+}
+
+// Seat returns the seat at index i counting from the front left, like "12A"
+func (m SeatMap) Seat(i int) string {
+	return fmt.Sprintf("%d%c", i/len(m.Letters)+1, m.Letters[i%len(m.Letters)])
+}
+
+// Index returns the index of a seat, and false if the flight has no such seat
+func (m SeatMap) Index(seat string) (int, bool) {
+	seat = strings.ToUpper(strings.TrimSpace(seat))
+	if len(seat) < 2 {
+		return 0, false
+	}
+	row, err := strconv.Atoi(seat[:len(seat)-1])
+	letter := strings.IndexByte(m.Letters, seat[len(seat)-1])
+	if err != nil || row < 1 || letter < 0 || seat[0] == '0' {
+		return 0, false
+	}
+	i := (row-1)*len(m.Letters) + letter
+	if i >= m.Total {
+		return 0, false
+	}
+	return i, true
+}
+
+// Next returns the frontmost seat that is not taken, and false if every seat
+// is
+func (m SeatMap) Next(taken map[string]bool) (string, bool) {
+	for i := 0; i < m.Total; i++ {
+		if seat := m.Seat(i); !taken[seat] {
+			return seat, true
+		}
+	}
+	return "", false
+}
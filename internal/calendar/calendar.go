@@ -0,0 +1,258 @@
+// Package calendar writes booked flights as iCalendar (RFC 5545) files, for
+// travelers to import or subscribe to in their calendar apps.
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of iCalendar files
+const ContentType = "text/calendar; charset=utf-8"
+
+// prodID identifies the product that wrote a calendar
+const prodID = "-//TONX//Flight Booking//EN"
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Layouts of DATE-TIME values, in UTC and in a named time zone
+const (
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+)
+
+// maxLineOctets is the longest content line before it is folded
+const maxLineOctets = 75
+
+// Event is a VEVENT. Start and End are written in their own time zones.
+type Event struct {
+	// UID identifies the event across updates, so calendars replace it
+	// instead of adding another one
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	// Latitude and Longitude are the GEO of Location, if both are set
+	Latitude  float64
+	Longitude float64
+	Start     time.Time
+	End       time.Time
+	Status    string
+	// Modified is when the event last changed
+	Modified time.Time
+	// Sequence counts the changes of the event, calendars keep the event
+	// of the highest sequence
+	Sequence int
+}
+
+// Calendar is a VCALENDAR of events
+type Calendar struct {
+	// Name is shown by calendar apps for subscribed calendars
+	Name string
+	// RefreshInterval suggests how often subscribers fetch the calendar
+	// again, zero to leave it to them
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Encode returns the calendar as an iCalendar file. Every time zone of the
+// events is described by a VTIMEZONE over the span of its events.
+func (c Calendar) Encode() []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + prodID)
+	w.line("CALSCALE:GREGORIAN")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		duration := formatDuration(c.RefreshInterval)
+		w.line("REFRESH-INTERVAL;VALUE=DURATION:" + duration)
+		w.line("X-PUBLISHED-TTL:" + duration)
+	}
+	for _, zone := range timeZones(c.Events) {
+		zone.write(w)
+	}
+	for _, event := range c.Events {
+		event.write(w)
+	}
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func (e Event) write(w *writer) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + escape(e.UID))
+	// Without a METHOD, DTSTAMP is when the event last changed
+	w.line("DTSTAMP:" + e.Modified.UTC().Format(utcLayout))
+	w.line("LAST-MODIFIED:" + e.Modified.UTC().Format(utcLayout))
+	w.line("SEQUENCE:" + strconv.Itoa(e.Sequence))
+	w.line("DTSTART" + formatTime(e.Start))
+	w.line("DTEND" + formatTime(e.End))
+	w.line("SUMMARY:" + escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION:" + escape(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION:" + escape(e.Location))
+	}
+	if e.Latitude != 0 || e.Longitude != 0 {
+		w.line(fmt.Sprintf("GEO:%.6f;%.6f", e.Latitude, e.Longitude))
+	}
+	if e.Status != "" {
+		w.line("STATUS:" + e.Status)
+	}
+	// Flights keep travelers busy
+	w.line("TRANSP:OPAQUE")
+	w.line("END:VEVENT")
+}
+
+// formatTime returns the parameters and value of a DATE-TIME property, in
+// UTC or with the TZID of its time zone
+func formatTime(t time.Time) string {
+	if t.Location() == time.UTC {
+		return ":" + t.Format(utcLayout)
+	}
+	return ";TZID=" + t.Location().String() + ":" + t.Format(localLayout)
+}
+
+// timeZone is a time zone used by events, from the earliest to the latest
+// time it is used at
+type timeZone struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// timeZones returns the named time zones of the events, by name
+func timeZones(events []Event) []timeZone {
+	zones := make(map[string]*timeZone)
+	for _, event := range events {
+		for _, t := range []time.Time{event.Start, event.End} {
+			if t.Location() == time.UTC {
+				continue
+			}
+			name := t.Location().String()
+			zone, ok := zones[name]
+			if !ok {
+				zones[name] = &timeZone{loc: t.Location(), from: t, to: t}
+				continue
+			}
+			if t.Before(zone.from) {
+				zone.from = t
+			}
+			if t.After(zone.to) {
+				zone.to = t
+			}
+		}
+	}
+
+	names := make([]string, 0, len(zones))
+	for name := range zones {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	list := make([]timeZone, 0, len(names))
+	for _, name := range names {
+		list = append(list, *zones[name])
+	}
+	return list
+}
+
+// write writes the VTIMEZONE of the zone, with an observance for each offset
+// in effect between from and to
+func (z timeZone) write(w *writer) {
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + z.loc.String())
+	t := z.from.In(z.loc)
+	for {
+		name, offset := t.Zone()
+		start, end := t.ZoneBounds()
+		// The onset is in the local time of the offset before it
+		before, onset := offset, time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if !start.IsZero() {
+			_, before = start.Add(-time.Second).Zone()
+			onset = start.In(time.FixedZone("", before))
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		w.line("BEGIN:" + kind)
+		w.line("DTSTART:" + onset.Format(localLayout))
+		w.line("TZOFFSETFROM:" + formatOffset(before))
+		w.line("TZOFFSETTO:" + formatOffset(offset))
+		w.line("TZNAME:" + escape(name))
+		w.line("END:" + kind)
+
+		if end.IsZero() || end.After(z.to) {
+			break
+		}
+		t = end.In(z.loc)
+	}
+	w.line("END:VTIMEZONE")
+}
+
+// formatOffset formats a UTC offset in seconds like "+0800"
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+// formatDuration formats a duration of whole minutes like "PT1H30M"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours := int(d / time.Hour); hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+	}
+	if minutes := int(d % time.Hour / time.Minute); minutes > 0 || d < time.Hour {
+		fmt.Fprintf(&b, "%dM", minutes)
+	}
+	return b.String()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "")
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writer writes content lines, folded at maxLineOctets and ended by CRLF
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) line(s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		// Fold between characters, never inside one
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation line counts
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
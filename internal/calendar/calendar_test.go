@@ -0,0 +1,110 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+func testOrder() *model.Order {
+	departure := time.Date(2026, time.October, 24, 23, 30, 0, 0, time.UTC)
+	return &model.Order{
+		OrderNumber:  "ORD-20261001-abcd1234",
+		Status:       "CONFIRMED",
+		TicketAmount: 2,
+		FlightID:     42,
+		UpdatedAt:    time.Date(2026, time.October, 1, 8, 0, 0, 0, time.UTC),
+		Flight: &model.Flight{
+			FlightNumber:  "BR67",
+			Airline:       "EVA Air",
+			DepartureCity: "Taipei",
+			ArrivalCity:   "London",
+			DepartureTime: departure,
+			ArrivalTime:   departure.Add(15 * time.Hour),
+			Aircraft:      "Boeing 777-300ER",
+			Status:        "SCHEDULED",
+		},
+		Travelers: []model.Traveler{
+			{FirstName: "Mei", LastName: "Lin"},
+			{FirstName: "Wei", LastName: "Chen"},
+		},
+	}
+}
+
+// unfold joins folded content lines and splits them
+func unfold(ics []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(ics), "\r\n ", ""), "\r\n")
+}
+
+func TestFlightEvent(t *testing.T) {
+	event := FlightEvent(testOrder())
+	require.Equal(t, "ORD-20261001-abcd1234-42@tonx", event.UID)
+	require.Equal(t, "BR67 Taipei (TPE) to London (LHR)", event.Summary)
+	require.Equal(t, "Taipei (TPE), TW", event.Location)
+	require.Equal(t, StatusConfirmed, event.Status)
+	require.Equal(t, "Asia/Taipei", event.Start.Location().String())
+	require.Equal(t, "Europe/London", event.End.Location().String())
+	require.Contains(t, event.Description, "Travelers: Mei Lin, Wei Chen")
+
+	require.Equal(t, 1, event.Sequence)
+
+	// Every change of the flight or the order raises the sequence
+	order := testOrder()
+	order.Flight.Status = "CANCELLED"
+	order.Flight.Revision = 1
+	require.Equal(t, StatusCancelled, FlightEvent(order).Status)
+	require.Equal(t, 2, FlightEvent(order).Sequence)
+	order.Status = "CANCELLED"
+	require.Equal(t, 3, FlightEvent(order).Sequence)
+}
+
+func TestCalendar_Encode(t *testing.T) {
+	order := testOrder()
+	order.Flight.FlightNumber = "BR67, the long way round; via Bangkok"
+	earlier := testOrder()
+	earlier.OrderNumber = "ORD-20261001-efgh5678"
+	earlier.Flight.DepartureTime = earlier.Flight.DepartureTime.AddDate(0, 0, -7)
+	earlier.Flight.ArrivalTime = earlier.Flight.ArrivalTime.AddDate(0, 0, -7)
+	ics := Calendar{
+		Name:            "TONX flights",
+		RefreshInterval: time.Hour,
+		Events:          []Event{FlightEvent(earlier), FlightEvent(order)},
+	}.Encode()
+
+	for _, line := range strings.Split(string(ics), "\r\n") {
+		require.LessOrEqual(t, len(line), maxLineOctets, line)
+	}
+	require.True(t, strings.HasSuffix(string(ics), "END:VCALENDAR\r\n"))
+
+	lines := unfold(ics)
+	require.Contains(t, lines, "REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	require.Contains(t, lines, "UID:ORD-20261001-abcd1234-42@tonx")
+	require.Contains(t, lines, "DTSTAMP:20261001T080000Z")
+	require.Contains(t, lines, "SEQUENCE:1")
+	require.Contains(t, lines, "DTSTART;TZID=Asia/Taipei:20261025T073000")
+	require.Contains(t, lines, "DTEND;TZID=Europe/London:20261025T143000")
+	require.Contains(t, lines, `SUMMARY:BR67\, the long way round\; via Bangkok Taipei (TPE) to London (LHR)`)
+	require.Contains(t, lines, "GEO:25.079700;121.234200")
+
+	// London leaves summer time between the flights
+	london := strings.Join(lines, "\n")
+	london = london[strings.Index(london, "TZID:Europe/London"):]
+	london = london[:strings.Index(london, "END:VTIMEZONE")]
+	require.Contains(t, london, "BEGIN:DAYLIGHT\nDTSTART:20260329T010000\nTZOFFSETFROM:+0000\nTZOFFSETTO:+0100\nTZNAME:BST\nEND:DAYLIGHT")
+	require.Contains(t, london, "BEGIN:STANDARD\nDTSTART:20261025T020000\nTZOFFSETFROM:+0100\nTZOFFSETTO:+0000\nTZNAME:GMT\nEND:STANDARD")
+	require.Equal(t, 1, strings.Count(string(ics), "TZID:Asia/Taipei\r\n"))
+}
+
+func TestWriter_Fold(t *testing.T) {
+	w := &writer{}
+	w.line("DESCRIPTION:" + strings.Repeat("航班", 40))
+	for _, line := range strings.Split(strings.TrimSuffix(w.buf.String(), "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), maxLineOctets)
+		require.True(t, strings.ToValidUTF8(line, "") == line, line)
+	}
+	require.Equal(t, []string{"DESCRIPTION:" + strings.Repeat("航班", 40), ""}, unfold(w.buf.Bytes()))
+}
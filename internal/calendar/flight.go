@@ -0,0 +1,79 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/notify"
+)
+
+// FlightEvent returns the event of the flight of an order, which must be
+// loaded with its flight and travelers. It starts at departure in the time
+// zone of the departure airport and ends at arrival in that of the arrival
+// airport.
+func FlightEvent(order *model.Order) Event {
+	itinerary := notify.NewItinerary(order)
+	segment := itinerary.Flight
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "Order %s\n", order.OrderNumber)
+	fmt.Fprintf(&description, "%s %s, %s\n", segment.Airline, segment.FlightNumber, segment.Aircraft)
+	fmt.Fprintf(&description, "Departs %s at %s\n", segment.From, segment.Departure.Format("Mon 2 Jan 15:04 MST"))
+	fmt.Fprintf(&description, "Arrives %s at %s\n", segment.To, segment.Arrival.Format("Mon 2 Jan 15:04 MST"))
+	if len(itinerary.Travelers) > 0 {
+		fmt.Fprintf(&description, "Travelers: %s\n", strings.Join(itinerary.Travelers, ", "))
+	}
+
+	event := Event{
+		UID:         FlightEventUID(order),
+		Summary:     fmt.Sprintf("%s %s to %s", segment.FlightNumber, segment.From, segment.To),
+		Description: strings.TrimSuffix(description.String(), "\n"),
+		Location:    segment.From.String(),
+		Start:       segment.Departure,
+		End:         segment.Arrival,
+		Status:      eventStatus(order),
+		Modified:    order.UpdatedAt,
+		Sequence:    eventSequence(order),
+	}
+	if order.Flight.UpdatedAt.After(event.Modified) {
+		event.Modified = order.Flight.UpdatedAt
+	}
+	if airport, ok := order.Flight.Origin(); ok {
+		event.Location = fmt.Sprintf("%s (%s), %s", airport.City, airport.Code, airport.Country)
+		event.Latitude, event.Longitude = airport.Latitude, airport.Longitude
+	}
+	return event
+}
+
+// FlightEventUID returns the UID of the event of the flight of an order. It
+// does not change with the times of the flight, so calendars update the
+// event when the flight is delayed.
+func FlightEventUID(order *model.Order) string {
+	return fmt.Sprintf("%s-%d@tonx", order.OrderNumber, order.FlightID)
+}
+
+// eventSequence returns the sequence of the event of the flight of an order,
+// which grows with every change of the flight and of the order status. Orders
+// only move from pending to confirmed to cancelled.
+func eventSequence(order *model.Order) int {
+	sequence := order.Flight.Revision
+	switch order.Status {
+	case string(api.OrderStatusCONFIRMED):
+		sequence++
+	case string(api.OrderStatusCANCELLED):
+		sequence += 2
+	}
+	return sequence
+}
+
+func eventStatus(order *model.Order) string {
+	switch {
+	case order.Status == string(api.OrderStatusCANCELLED) || order.Flight.Status == string(api.FlightStatusCANCELLED):
+		return StatusCancelled
+	case order.Status == string(api.OrderStatusPENDING):
+		return StatusTentative
+	}
+	return StatusConfirmed
+}
@@ -0,0 +1,176 @@
+// Package catalog lists the airports, airlines and aircraft the booking
+// system knows about.
+package catalog
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	// Airport time zones must resolve in containers without zoneinfo.
+	_ "time/tzdata"
+)
+
+// Airport is an airport and the time zone its schedules are published in
+type Airport struct {
+	Code      string
+	City      string
+	Country   string
+	TimeZone  string
+	Latitude  float64
+	Longitude float64
+}
+
+// Location returns the time zone of the airport
+func (a Airport) Location() *time.Location {
+	loc, err := time.LoadLocation(a.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Airline is an airline and the airport it operates from
+type Airline struct {
+	Code string
+	Name string
+	Hub  string
+}
+
+// Aircraft is an aircraft type in a typical two-class layout
+type Aircraft struct {
+	// Code is the IATA aircraft type code used in SSIM schedules
+	Code  string
+	Name  string
+	Seats int
+	// RangeKm is the longest route the type is scheduled on
+	RangeKm float64
+}
+
+var Airports = []Airport{
+	{Code: "TPE", City: "Taipei", Country: "TW", TimeZone: "Asia/Taipei", Latitude: 25.0797, Longitude: 121.2342},
+	{Code: "KHH", City: "Kaohsiung", Country: "TW", TimeZone: "Asia/Taipei", Latitude: 22.5771, Longitude: 120.3500},
+	{Code: "HKG", City: "Hong Kong", Country: "HK", TimeZone: "Asia/Hong_Kong", Latitude: 22.3080, Longitude: 113.9185},
+	{Code: "NRT", City: "Tokyo", Country: "JP", TimeZone: "Asia/Tokyo", Latitude: 35.7720, Longitude: 140.3929},
+	{Code: "KIX", City: "Osaka", Country: "JP", TimeZone: "Asia/Tokyo", Latitude: 34.4347, Longitude: 135.2440},
+	{Code: "ICN", City: "Seoul", Country: "KR", TimeZone: "Asia/Seoul", Latitude: 37.4602, Longitude: 126.4407},
+	{Code: "PVG", City: "Shanghai", Country: "CN", TimeZone: "Asia/Shanghai", Latitude: 31.1443, Longitude: 121.8083},
+	{Code: "SIN", City: "Singapore", Country: "SG", TimeZone: "Asia/Singapore", Latitude: 1.3644, Longitude: 103.9915},
+	{Code: "BKK", City: "Bangkok", Country: "TH", TimeZone: "Asia/Bangkok", Latitude: 13.6900, Longitude: 100.7501},
+	{Code: "MNL", City: "Manila", Country: "PH", TimeZone: "Asia/Manila", Latitude: 14.5086, Longitude: 121.0194},
+	{Code: "SGN", City: "Ho Chi Minh City", Country: "VN", TimeZone: "Asia/Ho_Chi_Minh", Latitude: 10.8188, Longitude: 106.6520},
+	{Code: "SFO", City: "San Francisco", Country: "US", TimeZone: "America/Los_Angeles", Latitude: 37.6213, Longitude: -122.3790},
+	{Code: "LAX", City: "Los Angeles", Country: "US", TimeZone: "America/Los_Angeles", Latitude: 33.9416, Longitude: -118.4085},
+	{Code: "SEA", City: "Seattle", Country: "US", TimeZone: "America/Los_Angeles", Latitude: 47.4502, Longitude: -122.3088},
+	{Code: "JFK", City: "New York", Country: "US", TimeZone: "America/New_York", Latitude: 40.6413, Longitude: -73.7781},
+	{Code: "YVR", City: "Vancouver", Country: "CA", TimeZone: "America/Vancouver", Latitude: 49.1967, Longitude: -123.1815},
+	{Code: "LHR", City: "London", Country: "GB", TimeZone: "Europe/London", Latitude: 51.4700, Longitude: -0.4543},
+	{Code: "CDG", City: "Paris", Country: "FR", TimeZone: "Europe/Paris", Latitude: 49.0097, Longitude: 2.5479},
+	{Code: "AMS", City: "Amsterdam", Country: "NL", TimeZone: "Europe/Amsterdam", Latitude: 52.3105, Longitude: 4.7683},
+	{Code: "SYD", City: "Sydney", Country: "AU", TimeZone: "Australia/Sydney", Latitude: -33.9399, Longitude: 151.1753},
+	{Code: "BNE", City: "Brisbane", Country: "AU", TimeZone: "Australia/Brisbane", Latitude: -27.3842, Longitude: 153.1175},
+}
+
+var Airlines = []Airline{
+	{Code: "BR", Name: "EVA AIR", Hub: "TPE"},
+	{Code: "CI", Name: "China Airlines", Hub: "TPE"},
+	{Code: "JX", Name: "Starlux Airlines", Hub: "TPE"},
+	{Code: "CX", Name: "Cathay Pacific", Hub: "HKG"},
+	{Code: "NH", Name: "All Nippon Airways", Hub: "NRT"},
+	{Code: "KE", Name: "Korean Air", Hub: "ICN"},
+	{Code: "SQ", Name: "Singapore Airlines", Hub: "SIN"},
+	{Code: "UA", Name: "United Airlines", Hub: "SFO"},
+}
+
+// Aircrafts are ordered by range, so the first one covering a route is the
+// smallest that can fly it.
+var Aircrafts = []Aircraft{
+	{Code: "AT7", Name: "ATR 72-600", Seats: 70, RangeKm: 800},
+	{Code: "32Q", Name: "Airbus A321neo", Seats: 180, RangeKm: 4000},
+	{Code: "738", Name: "Boeing 737-800", Seats: 162, RangeKm: 4000},
+	{Code: "333", Name: "Airbus A330-300", Seats: 309, RangeKm: 8000},
+	{Code: "789", Name: "Boeing 787-9", Seats: 304, RangeKm: 12000},
+	{Code: "359", Name: "Airbus A350-900", Seats: 306, RangeKm: 14000},
+	{Code: "77W", Name: "Boeing 777-300ER", Seats: 358, RangeKm: 14000},
+}
+
+// AirportByCode returns the airport with an IATA code
+func AirportByCode(code string) (Airport, bool) {
+	for _, airport := range Airports {
+		if strings.EqualFold(airport.Code, code) {
+			return airport, true
+		}
+	}
+	return Airport{}, false
+}
+
+// AirportByCity returns the first airport serving a city
+func AirportByCity(city string) (Airport, bool) {
+	for _, airport := range Airports {
+		if strings.EqualFold(airport.City, city) {
+			return airport, true
+		}
+	}
+	return Airport{}, false
+}
+
+// AirlineByCode returns the airline with an IATA code
+func AirlineByCode(code string) (Airline, bool) {
+	for _, airline := range Airlines {
+		if strings.EqualFold(airline.Code, code) {
+			return airline, true
+		}
+	}
+	return Airline{}, false
+}
+
+// AircraftByName returns the aircraft type with a name or IATA code
+func AircraftByName(name string) (Aircraft, bool) {
+	for _, aircraft := range Aircrafts {
+		if strings.EqualFold(aircraft.Name, name) || strings.EqualFold(aircraft.Code, name) {
+			return aircraft, true
+		}
+	}
+	return Aircraft{}, false
+}
+
+// AircraftsFor returns the aircraft types scheduled on a route of distanceKm,
+// wide-bodies are not wasted on short hops.
+func AircraftsFor(distanceKm float64) []Aircraft {
+	var fits []Aircraft
+	for _, aircraft := range Aircrafts {
+		if aircraft.RangeKm < distanceKm {
+			continue
+		}
+		if len(fits) > 0 && aircraft.RangeKm > fits[0].RangeKm*2 {
+			break
+		}
+		fits = append(fits, aircraft)
+	}
+	if len(fits) == 0 {
+		fits = append(fits, Aircrafts[len(Aircrafts)-1])
+	}
+	return fits
+}
+
+// Distance returns the great-circle distance between two airports in km
+func Distance(from, to Airport) float64 {
+	const earthRadiusKm = 6371
+	lat1, lat2 := from.Latitude*math.Pi/180, to.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (to.Longitude - from.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// BlockTime estimates the gate to gate time of a route
+func BlockTime(distanceKm float64) time.Duration {
+	minutes := 30 + distanceKm/800*60
+	// Schedules are published in five-minute steps
+	return time.Duration(math.Round(minutes/5)*5) * time.Minute
+}
+
+// BaseFare returns the usual base price of a route, in whole hundreds
+func BaseFare(distanceKm float64) int {
+	return int(math.Round((1500+distanceKm*2.5)/100) * 100)
+}
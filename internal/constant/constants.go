@@ -1,6 +1,44 @@
 package constant
 
+import "time"
+
 const (
 	ORD_PREFIX = "ORD"
-	FLIGHT_KEY = "flight:%d:available_seats"
+
+	// Keys of a flight share the {id} hash tag, so scripts touching several of
+	// them run on a single Redis Cluster slot.
+	FLIGHT_KEY          = "flight:{%d}:available_seats"
+	FLIGHT_HELD_KEY     = "flight:{%d}:holds"
+	RESERVATION_KEY     = "flight:{%d}:reservation:%s"
+	FLIGHT_CUSTOMER_KEY = "flight:{%d}:customer:%d:seats"
+	FLIGHT_CHANNEL      = "flight:{%d}:availability"
+	FLIGHT_SEARCH_KEY   = "flight:search:%s"
+	FLIGHT_SUGGEST_KEY  = "flight:suggest:%s"
+	ORDER_TICKET_KEY    = "order:ticket:%s"
+	CUSTOMER_HOLDS_KEY  = "customer:{%d}:holds"
+
+	WAITING_ROOM_QUEUE_KEY     = "waitingroom:flight:{%d}:queue"
+	WAITING_ROOM_SEQ_KEY       = "waitingroom:flight:{%d}:seq"
+	WAITING_ROOM_BUCKET_KEY    = "waitingroom:flight:{%d}:bucket"
+	WAITING_ROOM_ADMISSION_KEY = "waitingroom:flight:{%d}:admission:"
+
+	APIKEY_RATE_KEY  = "apikey:{%d}:rate:%d"
+	APIKEY_QUOTA_KEY = "apikey:{%d}:bookings:%s"
+
+	RATE_LIMIT_KEY = "ratelimit:%s:{%s}"
+
+	RECONCILE_LOCK_KEY = "reconcile:lock"
+
+	ORDER_STREAM             = "order:queue"
+	ORDER_DEAD_LETTER_STREAM = "order:queue:dead"
+	ORDER_CONSUMER_GROUP     = "order-workers"
+	// OrderDeadLetterMaxLen is about the number of dead-lettered orders kept
+	OrderDeadLetterMaxLen = 10000
+
+	FlightSearchCacheTTL  = 30 * time.Second
+	FlightSuggestCacheTTL = 5 * time.Minute
+	OrderTicketTTL        = 24 * time.Hour
+	ReservationTTL        = 10 * time.Minute
+	WaitingRoomQueueTTL   = time.Hour
+	APIKeyCacheTTL        = 30 * time.Second
 )
@@ -1,9 +1,15 @@
 package constant
 
 // CheckAndDecrementSeatsScript is a Lua script that checks seat availability and decrements if available
+// The decrement is recorded under a reservation key, which ReleaseSeatsScript consumes to give the seats back
+// The seats a customer holds and booked on the flight are counted too, and checked against their limit if there is one
 const CheckAndDecrementSeatsScript = `
 local flightKey = KEYS[1]
+local reservationKey = KEYS[2]
+local customerKey = KEYS[3]
 local requiredSeats = tonumber(ARGV[1])
+local reservationTTL = tonumber(ARGV[2])
+local maxCustomerSeats = tonumber(ARGV[3])  -- 0 for no limit
 
 -- Get current available seats
 local availableSeats = tonumber(redis.call('GET', flightKey))
@@ -16,7 +22,289 @@ if availableSeats < requiredSeats then
     return 0  -- Not enough seats
 end
 
--- Decrement seats
+-- Check the seats of the customer on the flight
+local customerSeats = tonumber(redis.call('GET', customerKey))
+if maxCustomerSeats > 0 then
+    if not customerSeats then
+        return -2  -- Customer not found in Redis
+    end
+    if customerSeats + requiredSeats > maxCustomerSeats then
+        return -3  -- Over the customer's limit
+    end
+end
+
+-- Decrement seats and record the reservation
 redis.call('DECRBY', flightKey, requiredSeats)
+redis.call('SET', reservationKey, requiredSeats, 'EX', reservationTTL)
+if customerSeats then
+    redis.call('INCRBY', customerKey, requiredSeats)
+end
+return 1  -- Success
+`
+
+// ReleaseSeatsScript is a Lua script that gives the seats of a reservation back to its flight and customer.
+// The reservation key is consumed, so a reservation is released at most once however often the script runs.
+const ReleaseSeatsScript = `
+local flightKey = KEYS[1]
+local reservationKey = KEYS[2]
+local customerKey = KEYS[3]
+
+local reservedSeats = tonumber(redis.call('GET', reservationKey))
+if not reservedSeats then
+    return 0  -- Already released, confirmed or expired
+end
+redis.call('DEL', reservationKey)
+
+-- A missing counter is initialized from the database on next use
+if redis.call('EXISTS', flightKey) == 1 then
+    redis.call('INCRBY', flightKey, reservedSeats)
+end
+if redis.call('EXISTS', customerKey) == 1 then
+    redis.call('DECRBY', customerKey, reservedSeats)
+end
+return 1  -- Released
+`
+
+// RestockSeatsScript is a Lua script that gives the seats of a cancelled booking back to its flight and customer.
+const RestockSeatsScript = `
+local flightKey = KEYS[1]
+local customerKey = KEYS[2]
+local seats = tonumber(ARGV[1])
+
+-- A missing counter is initialized from the database on next use
+if redis.call('EXISTS', customerKey) == 1 then
+    redis.call('DECRBY', customerKey, seats)
+end
+if redis.call('EXISTS', flightKey) == 1 then
+    redis.call('INCRBY', flightKey, seats)
+    return 1  -- Restocked
+end
+return 0  -- Flight not found in Redis
+`
+
+// AcquireHoldScript is a Lua script that counts a reservation as a pending hold of its customer, unless the customer
+// has too many already. Holds are dropped when their reservation is confirmed or released, or once it expired.
+const AcquireHoldScript = `
+local holdsKey = KEYS[1]
+local reservation = ARGV[1]
+local maxHolds = tonumber(ARGV[2])
+local holdTTL = tonumber(ARGV[3])  -- milliseconds
+
+-- Use the Redis clock, so servers with skewed clocks agree
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', holdsKey, '-inf', now)
+if redis.call('ZCARD', holdsKey) >= maxHolds then
+    return 0  -- Too many holds
+end
+
+redis.call('ZADD', holdsKey, now + holdTTL, reservation)
+if redis.call('PTTL', holdsKey) < holdTTL then
+    redis.call('PEXPIRE', holdsKey, holdTTL)
+end
+return 1  -- Held
+`
+
+// PublishSeatsScript is a Lua script that publishes the current available seats of a flight to its availability channel.
+// Reading and publishing in one script keeps the published values in the same order as the changes.
+const PublishSeatsScript = `
+local flightKey = KEYS[1]
+local channel = ARGV[1]
+
+local availableSeats = redis.call('GET', flightKey)
+if not availableSeats then
+    return -1  -- Flight not found in Redis
+end
+
+redis.call('PUBLISH', channel, availableSeats)
+return tonumber(availableSeats)
+`
+
+// AdmitFromWaitingRoomScript is a Lua script that lets visitors through a flight's waiting room at a fixed rate.
+// Admissions refill like a token bucket and are handed to the visitors at the head of the queue. The caller reads the
+// head beforehand and passes the admission key of each of those visitors, so the script only touches declared keys.
+// Visitors admitted meanwhile by another caller are skipped.
+const AdmitFromWaitingRoomScript = `
+local queueKey = KEYS[1]
+local bucketKey = KEYS[2]
+local rate = tonumber(ARGV[1])             -- admissions per second
+local burst = tonumber(ARGV[2])
+local admissionTTL = tonumber(ARGV[3])     -- seconds
+-- KEYS[3..] are the admission keys of the visitors ARGV[4..] at the head of the queue
+
+-- Use the Redis clock, so servers with skewed clocks agree
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+-- Refill admissions for the time passed since the last run
+local state = redis.call('HMGET', bucketKey, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) / 1000 * rate)
+
+-- Admit visitors from the head of the queue
+local admitted = 0
+for i = 3, #KEYS do
+    if tokens < 1 then
+        break
+    end
+    if redis.call('ZREM', queueKey, ARGV[i + 1]) == 1 then
+        redis.call('SET', KEYS[i], '1', 'EX', admissionTTL)
+        tokens = tokens - 1
+        admitted = admitted + 1
+    end
+end
+
+redis.call('HSET', bucketKey, 'tokens', tokens, 'ts', now)
+redis.call('EXPIRE', bucketKey, 3600)
+return admitted
+`
+
+// liveHeldSeats is a Lua function summing the seats held by queued orders of a flight. Each hold is a field of the
+// held key, by ticket, with the seats and when the hold expires. Expired holds, of orders whose drop was lost, are
+// deleted rather than counted.
+const liveHeldSeats = `
+local function liveHeldSeats(heldKey)
+    local now = tonumber(redis.call('TIME')[1])
+    local holds = redis.call('HGETALL', heldKey)
+    local held = 0
+    for i = 1, #holds, 2 do
+        local seats, expiresAt = string.match(holds[i + 1], '^(%d+):(%d+)$')
+        if seats and tonumber(expiresAt) > now then
+            held = held + tonumber(seats)
+        else
+            redis.call('HDEL', heldKey, holds[i])
+        end
+    end
+    return held
+end
+`
+
+// HoldSeatsScript is a Lua script that counts the seats of a queued order as held by its flight until the hold
+// expires. The held seats are recorded per ticket, so dropping them with HDEL happens at most once.
+const HoldSeatsScript = `
+local heldKey = KEYS[1]
+local ticketID = ARGV[1]
+local heldSeats = tonumber(ARGV[2])
+local ticketTTL = tonumber(ARGV[3])
+
+local expiresAt = tonumber(redis.call('TIME')[1]) + ticketTTL
+if redis.call('HSETNX', heldKey, ticketID, heldSeats .. ':' .. expiresAt) == 0 then
+    return 0  -- Held already
+end
+redis.call('EXPIRE', heldKey, ticketTTL)
+return 1  -- Held
+`
+
+// ReadSeatsScript is a Lua script that returns the available seats of a flight, an empty string if there is no
+// counter, and the seats held by its live holds.
+const ReadSeatsScript = liveHeldSeats + `
+local flightKey = KEYS[1]
+local heldKey = KEYS[2]
+
+return {redis.call('GET', flightKey) or '', liveHeldSeats(heldKey)}
+`
+
+// CompareAndSetSeatsScript is a Lua script that overwrites the available seats of a flight only if they did not change
+// since they were read, so a repair never discards a concurrent booking.
+const CompareAndSetSeatsScript = `
+local flightKey = KEYS[1]
+local expectedSeats = ARGV[1]
+local newSeats = ARGV[2]
+
+if redis.call('GET', flightKey) ~= expectedSeats then
+    return 0  -- Changed since read
+end
+
+redis.call('SET', flightKey, newSeats, 'KEEPTTL')
+return 1  -- Success
+`
+
+// RebuildSeatsScript is a Lua script that sets the available seats of a flight computed from the database, only if
+// neither they nor the seats held by queued orders changed since they were read, so a booking made meanwhile is not
+// discarded. An empty expected counter means it was missing, and it is created with the given TTL in seconds.
+const RebuildSeatsScript = liveHeldSeats + `
+local flightKey = KEYS[1]
+local heldKey = KEYS[2]
+local expectedSeats = ARGV[1]
+local expectedHeld = tonumber(ARGV[2])
+local newSeats = ARGV[3]
+local ttl = tonumber(ARGV[4])
+
+local seats = redis.call('GET', flightKey)
+if (seats or '') ~= expectedSeats or liveHeldSeats(heldKey) ~= expectedHeld then
+    return 0  -- Changed since read
+end
+
+if seats then
+    redis.call('SET', flightKey, newSeats, 'KEEPTTL')
+else
+    redis.call('SET', flightKey, newSeats, 'EX', ttl)
+end
 return 1  -- Success
 `
+
+// CountUpToLimitScript is a Lua script that counts a use of a limited allowance, like the requests of an API key in a
+// minute or its bookings in a day. The counter expires with its window.
+const CountUpToLimitScript = `
+local counterKey = KEYS[1]
+local limit = tonumber(ARGV[1])
+local windowTTL = tonumber(ARGV[2])  -- seconds
+
+local used = tonumber(redis.call('GET', counterKey) or '0')
+if used >= limit then
+    return 0  -- Over the limit
+end
+
+if redis.call('INCR', counterKey) == 1 then
+    redis.call('EXPIRE', counterKey, windowTTL)
+end
+return 1  -- Counted
+`
+
+// UncountScript is a Lua script that gives back a use counted by CountUpToLimitScript, unless its window expired.
+const UncountScript = `
+local counterKey = KEYS[1]
+
+if tonumber(redis.call('GET', counterKey) or '0') > 0 then
+    redis.call('DECR', counterKey)
+    return 1  -- Given back
+end
+return 0  -- Window expired
+`
+
+// TokenBucketScript is a Lua script that takes a request from a client's token bucket.
+// It returns whether the request is allowed, the whole tokens left, and the milliseconds until the next token and until
+// the bucket is full again.
+const TokenBucketScript = `
+local bucketKey = KEYS[1]
+local rate = tonumber(ARGV[1])    -- tokens per second
+local burst = tonumber(ARGV[2])
+
+-- Use the Redis clock, so servers with skewed clocks agree
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+-- Refill tokens for the time passed since the last request
+local state = redis.call('HMGET', bucketKey, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+
+redis.call('HSET', bucketKey, 'tokens', tokens, 'ts', now)
+-- A bucket left alone until it is full again is the same as no bucket
+redis.call('PEXPIRE', bucketKey, math.ceil(burst / rate * 1000) + 1000)
+
+local retryAfter = 0
+if tokens < 1 then
+    retryAfter = math.ceil((1 - tokens) / rate * 1000)
+end
+return {allowed, math.floor(tokens), retryAfter, math.ceil((burst - tokens) / rate * 1000)}
+`
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) ListAgencies(c *gin.Context) {
+	agencies, err := s.apiKeyService.ListAgencies(c.Request.Context())
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &api.ListAgenciesResponse{
+		Data: make([]api.Agency, len(agencies)),
+	}
+	for i := range agencies {
+		resp.Data[i] = *ConvertToAgencyResponse(&agencies[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *BookingSystem) CreateAgency(c *gin.Context) {
+	var req api.CreateAgencyRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for agency")
+		return
+	}
+
+	agency, err := s.apiKeyService.CreateAgency(c.Request.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrAgencyExists) {
+			sendErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, ConvertToAgencyResponse(agency))
+}
+
+func (s *BookingSystem) ListAPIKeys(c *gin.Context, agencyID uint) {
+	keys, err := s.apiKeyService.ListKeys(c.Request.Context(), agencyID)
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &api.ListAPIKeysResponse{
+		Data: make([]api.APIKey, len(keys)),
+	}
+	for i := range keys {
+		resp.Data[i] = *ConvertToAPIKeyResponse(&keys[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *BookingSystem) CreateAPIKey(c *gin.Context, agencyID uint) {
+	var req api.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for API key")
+		return
+	}
+
+	keyReq := service.CreateAPIKeyRequest{
+		Name:   req.Name,
+		Scopes: make([]string, len(req.Scopes)),
+	}
+	for i, scope := range req.Scopes {
+		keyReq.Scopes[i] = string(scope)
+	}
+	if req.RateLimit != nil {
+		keyReq.RateLimit = *req.RateLimit
+	}
+	if req.DailyBookingQuota != nil {
+		keyReq.DailyBookingQuota = *req.DailyBookingQuota
+	}
+
+	key, rawKey, err := s.apiKeyService.CreateKey(c.Request.Context(), agencyID, keyReq)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAgencyNotFound):
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidScope):
+			sendErrorResponse(c, http.StatusBadRequest, err.Error())
+		default:
+			sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	converted := ConvertToAPIKeyResponse(key)
+	c.JSON(http.StatusCreated, &api.CreatedAPIKey{
+		Id:                converted.Id,
+		AgencyId:          converted.AgencyId,
+		Name:              converted.Name,
+		Prefix:            converted.Prefix,
+		Scopes:            converted.Scopes,
+		RateLimit:         converted.RateLimit,
+		DailyBookingQuota: converted.DailyBookingQuota,
+		CreatedAt:         converted.CreatedAt,
+		Key:               rawKey,
+	})
+}
+
+func (s *BookingSystem) RevokeAPIKey(c *gin.Context, keyID uint) {
+	if err := s.apiKeyService.RevokeKey(c.Request.Context(), keyID); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func ConvertToAgencyResponse(agency *model.Agency) *api.Agency {
+	return &api.Agency{
+		Id:        agency.ID,
+		Name:      agency.Name,
+		CreatedAt: agency.CreatedAt,
+	}
+}
+
+func ConvertToAPIKeyResponse(key *model.APIKey) *api.APIKey {
+	resp := &api.APIKey{
+		Id:                key.ID,
+		AgencyId:          key.AgencyID,
+		Name:              key.Name,
+		Prefix:            key.Prefix,
+		Scopes:            []api.APIKeyScope{},
+		RateLimit:         key.RateLimit,
+		DailyBookingQuota: key.DailyBookingQuota,
+		RevokedAt:         key.RevokedAt,
+		CreatedAt:         key.CreatedAt,
+	}
+	for _, scope := range key.ScopeList() {
+		resp.Scopes = append(resp.Scopes, api.APIKeyScope(scope))
+	}
+	return resp
+}
+
+func (s *BookingSystem) CreateAgencyCustomer(c *gin.Context) {
+	var req api.Customer
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for customer")
+		return
+	}
+
+	principal, _ := auth.PrincipalFrom(c.Request.Context())
+	customer := &model.Customer{
+		Name:   req.Name,
+		Email:  string(req.Email),
+		Phone:  req.Phone,
+		Status: "ACTIVE",
+	}
+	if err := s.apiKeyService.CreateAgencyCustomer(c.Request.Context(), principal.AgencyID, customer); err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			sendErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, api.Customer{
+		Id:    &customer.ID,
+		Name:  customer.Name,
+		Email: openapi_types.Email(customer.Email),
+		Phone: customer.Phone,
+	})
+}
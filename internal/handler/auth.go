@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) Signup(c *gin.Context) {
+	var req api.SignupRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for signup")
+		return
+	}
+
+	_, tokens, err := s.authService.Signup(c.Request.Context(), service.SignupRequest{
+		Name:     req.Name,
+		Email:    string(req.Email),
+		Phone:    req.Phone,
+		Password: req.Password,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			sendErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, ConvertToAuthTokensResponse(tokens))
+}
+
+func (s *BookingSystem) Login(c *gin.Context) {
+	var req api.LoginRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for login")
+		return
+	}
+
+	tokens, err := s.authService.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		sendErrorResponse(c, authErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, ConvertToAuthTokensResponse(tokens))
+}
+
+func (s *BookingSystem) RefreshToken(c *gin.Context) {
+	var req api.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for refresh")
+		return
+	}
+
+	tokens, err := s.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		sendErrorResponse(c, authErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, ConvertToAuthTokensResponse(tokens))
+}
+
+// authErrorStatus maps errors of signing in to response status codes.
+func authErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, auth.ErrInvalidToken):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ValidationErrorHandler answers requests the OpenAPI validator rejects in the
+// shape of api.Error, with the status of failed security requirements.
+func ValidationErrorHandler(c *gin.Context, message string, statusCode int) {
+	if status, ok := c.Get(auth.StatusKey); ok {
+		statusCode = status.(int)
+	}
+	sendErrorResponse(c, statusCode, message)
+	c.Abort()
+}
+
+func ConvertToAuthTokensResponse(tokens *auth.Tokens) *api.AuthTokens {
+	return &api.AuthTokens{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	}
+}
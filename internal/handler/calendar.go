@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/calendar"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+// calendarFeedRefresh is how often subscribers are asked to fetch feeds, so
+// delays show up in their calendars the same day
+const calendarFeedRefresh = time.Hour
+
+func (s *BookingSystem) GetOrderCalendar(c *gin.Context, orderNumber api.OrderNumber) {
+	order, ok := s.ownOrder(c, orderNumber)
+	if !ok {
+		return
+	}
+
+	ics := calendar.Calendar{Events: []calendar.Event{calendar.FlightEvent(order)}}.Encode()
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": order.OrderNumber + ".ics",
+	}))
+	c.Data(http.StatusOK, calendar.ContentType, ics)
+}
+
+func (s *BookingSystem) CreateCalendarFeed(c *gin.Context) {
+	principal, _ := auth.PrincipalFrom(c.Request.Context())
+	token, err := s.calendarFeedService.CreateFeed(c.Request.Context(), principal.CustomerID)
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	feedURL := s.publicURL(c) + "/api/v1/calendar/feeds/" + url.PathEscape(token) + "/flights.ics"
+	_, rest, _ := strings.Cut(feedURL, "://")
+	c.JSON(http.StatusCreated, &api.CalendarFeed{
+		Url:       feedURL,
+		WebcalUrl: "webcal://" + rest,
+	})
+}
+
+func (s *BookingSystem) DeleteCalendarFeed(c *gin.Context) {
+	principal, _ := auth.PrincipalFrom(c.Request.Context())
+	if err := s.calendarFeedService.DeleteFeed(c.Request.Context(), principal.CustomerID); err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *BookingSystem) GetCalendarFeed(c *gin.Context, feedToken string) {
+	customer, orders, err := s.calendarFeedService.FeedOrders(c.Request.Context(), feedToken)
+	if err != nil {
+		if errors.Is(err, service.ErrCalendarFeedNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	feed := calendar.Calendar{
+		Name:            "Flights of " + customer.Name,
+		RefreshInterval: calendarFeedRefresh,
+		Events:          make([]calendar.Event, len(orders)),
+	}
+	for i := range orders {
+		feed.Events[i] = calendar.FlightEvent(&orders[i])
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(http.StatusOK, calendar.ContentType, feed.Encode())
+}
+
+// publicURL returns the URL clients reach the API at, without a trailing
+// slash. It is taken from the request unless configured.
+func (s *BookingSystem) publicURL(c *gin.Context) string {
+	if s.cfg.PublicURL != "" {
+		return strings.TrimSuffix(s.cfg.PublicURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/boarding"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) CheckIn(c *gin.Context, orderNumber api.OrderNumber) {
+	var req api.CheckInRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for check-in")
+		return
+	}
+
+	order, ok := s.ownOrder(c, orderNumber)
+	if !ok {
+		return
+	}
+
+	checkIn := service.CheckInRequest{OrderID: order.ID}
+	if req.Travelers != nil {
+		checkIn.Travelers = make(map[uint]string, len(*req.Travelers))
+		for _, traveler := range *req.Travelers {
+			seat := ""
+			if traveler.Seat != nil {
+				seat = *traveler.Seat
+			}
+			checkIn.Travelers[traveler.TravelerId] = seat
+		}
+	}
+
+	passes, err := s.checkInService.CheckIn(c.Request.Context(), checkIn)
+	if err != nil {
+		sendErrorResponse(c, checkInErrorStatus(err), err.Error())
+		return
+	}
+
+	resp := api.CheckInResponse{BoardingPasses: make([]api.BoardingPass, len(passes))}
+	for i := range passes {
+		pass, err := ConvertToBoardingPassResponse(&passes[i])
+		if err != nil {
+			sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.BoardingPasses[i] = *pass
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func checkInErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidSeat):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrOrderNotFound), errors.Is(err, service.ErrTravelerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCheckInNotOpen), errors.Is(err, service.ErrCheckInClosed),
+		errors.Is(err, service.ErrOrderCancelled), errors.Is(err, service.ErrTravelersNotNamed),
+		errors.Is(err, service.ErrSeatTaken), errors.Is(err, service.ErrFlightFull):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func ConvertToBoardingPassResponse(pass *service.BoardingPass) (*api.BoardingPass, error) {
+	barcode, err := boarding.Barcode(pass.BCBP)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BoardingPass{
+		TravelerId:     pass.Traveler.ID,
+		FirstName:      pass.Traveler.FirstName,
+		LastName:       pass.Traveler.LastName,
+		Seat:           pass.Traveler.Seat,
+		SequenceNumber: pass.Traveler.BoardingSequence,
+		CheckedInAt:    *pass.Traveler.CheckedInAt,
+		FlightNumber:   pass.Flight.FlightNumber,
+		DepartureTime:  pass.Flight.DepartureTime,
+		Bcbp:           pass.BCBP,
+		Barcode:        barcode,
+	}, nil
+}
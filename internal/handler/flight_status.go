@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) ListFlightStatusHistory(c *gin.Context, id uint) {
+	history, err := s.flightStatusService.History(c.Request.Context(), id)
+	if err != nil {
+		sendErrorResponse(c, flightStatusErrorStatus(err), err.Error())
+		return
+	}
+
+	resp := &api.ListFlightStatusChangesResponse{
+		Data: make([]api.FlightStatusChange, len(history)),
+	}
+	for i := range history {
+		resp.Data[i] = *ConvertToFlightStatusChangeResponse(&history[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *BookingSystem) UpdateFlightStatus(c *gin.Context, id uint) {
+	var req api.UpdateFlightStatusRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for flight status")
+		return
+	}
+
+	update := service.UpdateFlightStatusRequest{
+		FlightID:      id,
+		Status:        string(req.Status),
+		DepartureTime: req.DepartureTime,
+		ArrivalTime:   req.ArrivalTime,
+	}
+	if req.Reason != nil {
+		update.Reason = *req.Reason
+	}
+	if principal, ok := auth.PrincipalFrom(c.Request.Context()); ok {
+		update.ChangedBy = &principal.CustomerID
+	}
+	history, err := s.flightStatusService.UpdateStatus(c.Request.Context(), update)
+	if err != nil {
+		sendErrorResponse(c, flightStatusErrorStatus(err), err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, ConvertToFlightStatusChangeResponse(history))
+}
+
+// flightStatusErrorStatus maps errors of flight changes to response status
+// codes.
+func flightStatusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrFlightNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidFlightTimes):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrFlightClosed), errors.Is(err, service.ErrNoFlightChange):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func ConvertToFlightStatusChangeResponse(history *model.FlightStatusHistory) *api.FlightStatusChange {
+	return &api.FlightStatusChange{
+		Id:                    history.ID,
+		FlightId:              history.FlightID,
+		PreviousStatus:        history.PreviousStatus,
+		Status:                history.Status,
+		PreviousDepartureTime: history.PreviousDepartureTime,
+		PreviousArrivalTime:   history.PreviousArrivalTime,
+		DepartureTime:         history.DepartureTime,
+		ArrivalTime:           history.ArrivalTime,
+		Reason:                history.Reason,
+		ChangedBy:             history.ChangedBy,
+		AffectedOrders:        history.AffectedOrders,
+		EntitlementsGranted:   history.EntitlementsGranted,
+		CreatedAt:             history.CreatedAt,
+	}
+}
@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/inventory"
 	"github.com/joremysh/tonx/internal/model"
 	"github.com/joremysh/tonx/internal/repository"
 	"github.com/joremysh/tonx/internal/service"
@@ -17,20 +22,85 @@ import (
 var _ api.ServerInterface = (*BookingSystem)(nil)
 var StartUp string
 
-func NewBookingSystem(gdb *gorm.DB, redisClient *cache.RedisClient) *BookingSystem {
+// Config holds the tunables of the booking API.
+type Config struct {
+	// MaxStreams is the number of availability streams that may be open at
+	// once, further streams are refused with 503. Zero does not limit them.
+	MaxStreams int
+	// StreamHeartbeat is the interval of heartbeats on idle streams. Zero
+	// sends none.
+	StreamHeartbeat time.Duration
+	// WaitingRoom configures admission control in front of booking.
+	WaitingRoom service.WaitingRoomConfig
+	// MaxImportBytes is the largest schedule accepted for import.
+	MaxImportBytes int64
+	// BookingLimits are the limits of a customer's bookings.
+	BookingLimits service.BookingLimits
+	// CheckIn is the online check-in window.
+	CheckIn service.CheckInConfig
+	// FlightStatus is when flight changes entitle orders to a refund.
+	FlightStatus service.FlightStatusConfig
+	// PublicURL is the URL clients reach the API at, for links such as
+	// calendar feeds. It is taken from requests when empty.
+	PublicURL string
+}
+
+func NewBookingSystem(gdb *gorm.DB, redisClient *cache.RedisClient, seats inventory.SeatInventory, orderQueue service.OrderQueue, scheduleService service.Schedule, issuer auth.TokenIssuer, apiKeyService service.APIKey, webhookService service.Webhook, cfg Config) *BookingSystem {
 	flightRepo := repository.NewFlightRepo(gdb)
 	orderRepo := repository.NewOrderRepo(gdb)
+	customerRepo := repository.NewCustomerRepo(gdb)
 	return &BookingSystem{
-		gdb:           gdb,
-		flightService: service.NewFlightService(flightRepo, redisClient),
-		orderService:  service.NewOrderService(gdb, redisClient, orderRepo),
+		gdb:                 gdb,
+		cfg:                 cfg,
+		authService:         service.NewAuthService(customerRepo, issuer),
+		apiKeyService:       apiKeyService,
+		flightService:       service.NewFlightService(flightRepo, redisClient),
+		orderService:        service.NewOrderService(gdb, seats, orderRepo, cfg.BookingLimits),
+		orderQueue:          orderQueue,
+		checkInService:      service.NewCheckInService(gdb, cfg.CheckIn),
+		flightStatusService: service.NewFlightStatusService(gdb, seats, cfg.FlightStatus),
+		calendarFeedService: service.NewCalendarFeedService(gdb),
+		scheduleService:     scheduleService,
+		webhookService:      webhookService,
+		waitingRoom:         service.NewWaitingRoom(flightRepo, redisClient, cfg.WaitingRoom),
+		streams:             streamSlots(cfg.MaxStreams),
+		closing:             make(chan struct{}),
+	}
+}
+
+// streamSlots returns the semaphore of max open availability streams, nil
+// for no limit
+func streamSlots(limit int) chan struct{} {
+	if limit <= 0 {
+		return nil
 	}
+	return make(chan struct{}, limit)
 }
 
 type BookingSystem struct {
-	gdb           *gorm.DB
-	flightService service.Flight
-	orderService  service.Order
+	gdb                 *gorm.DB
+	cfg                 Config
+	authService         service.Auth
+	apiKeyService       service.APIKey
+	flightService       service.Flight
+	orderService        service.Order
+	orderQueue          service.OrderQueue
+	checkInService      service.CheckIn
+	flightStatusService service.FlightStatus
+	calendarFeedService service.CalendarFeed
+	scheduleService     service.Schedule
+	webhookService      service.Webhook
+	waitingRoom         service.WaitingRoom
+	streams             chan struct{}
+	// closing is closed when the server shuts down, to end open streams
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// CloseStreams ends the open availability streams, for the server to shut
+// down.
+func (s *BookingSystem) CloseStreams() {
+	s.closeOnce.Do(func() { close(s.closing) })
 }
 
 func (s *BookingSystem) GetLiveness(c *gin.Context) {
@@ -44,17 +114,28 @@ func (s *BookingSystem) SearchFlights(c *gin.Context, params api.SearchFlightsPa
 	if params.DepartureDate != nil {
 		departureDate = &params.DepartureDate.Time
 	}
-	result, err := s.flightService.ListFlights(c.Request.Context(), parseListParams(params), departureDate)
+	listParams := parseListParams(params)
+	result, err := s.flightService.ListFlights(c.Request.Context(), listParams, departureDate)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) || errors.Is(err, repository.ErrInvalidSortColumn) ||
+			errors.Is(err, repository.ErrInvalidSortOrder) {
+			sendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	resp := &api.SearchFlightResponse{
-		Data:       make([]api.Flight, len(result.Data)),
-		Page:       result.Page,
-		PageSize:   result.PageSize,
-		TotalCount: result.TotalCount,
+		Data:     make([]api.Flight, len(result.Data)),
+		Page:     result.Page,
+		PageSize: result.PageSize,
+	}
+	if !listParams.SkipCount {
+		resp.TotalCount = &result.TotalCount
+	}
+	if result.NextCursor != "" {
+		resp.NextCursor = &result.NextCursor
 	}
 	for i, Flight := range result.Data {
 		converted := ConvertToFlightResponse(&Flight)
@@ -64,6 +145,34 @@ func (s *BookingSystem) SearchFlights(c *gin.Context, params api.SearchFlightsPa
 	c.JSON(http.StatusOK, resp)
 }
 
+func (s *BookingSystem) AutocompleteFlights(c *gin.Context, params api.AutocompleteFlightsParams) {
+	limit := 10
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	matches, err := s.flightService.Suggest(c.Request.Context(), params.Q, string(params.Type), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSuggestionKind) {
+			sendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &api.AutocompleteResponse{
+		Data: make([]api.Suggestion, len(matches)),
+	}
+	for i, match := range matches {
+		resp.Data[i] = api.Suggestion{
+			Value:    match.Value,
+			Distance: match.Distance,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func ConvertToFlightResponse(flight *model.Flight) *api.Flight {
 	return &api.Flight{
 		Id:             flight.ID,
@@ -81,7 +190,10 @@ func ConvertToFlightResponse(flight *model.Flight) *api.Flight {
 }
 
 func parseListParams(params api.SearchFlightsParams) *model.ListParams {
-	listParams := &model.ListParams{}
+	listParams := &model.ListParams{
+		Page:     1,
+		PageSize: 10,
+	}
 	if params.PageSize != nil {
 		listParams.PageSize = *params.PageSize
 	}
@@ -97,6 +209,15 @@ func parseListParams(params api.SearchFlightsParams) *model.ListParams {
 	if params.Filters != nil {
 		listParams.Filters = *params.Filters
 	}
+	if params.Match != nil {
+		listParams.Match = string(*params.Match)
+	}
+	if params.Cursor != nil {
+		listParams.Cursor = *params.Cursor
+	}
+	if params.IncludeTotal != nil {
+		listParams.SkipCount = !*params.IncludeTotal
+	}
 	return listParams
 }
 
@@ -107,7 +228,7 @@ func sendErrorResponse(c *gin.Context, code int, errMsg string) {
 	})
 }
 
-func (s *BookingSystem) CreateOrder(c *gin.Context) {
+func (s *BookingSystem) CreateOrder(c *gin.Context, params api.CreateOrderParams) {
 	var order api.CreateOrderRequest
 	err := c.Bind(&order)
 	if err != nil {
@@ -115,27 +236,309 @@ func (s *BookingSystem) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	customerID, ok := s.orderCustomer(c, order.CustomerId)
+	if !ok {
+		return
+	}
+
+	agencyID, restoreQuota, ok := s.useBookingQuota(c)
+	if !ok {
+		return
+	}
+
+	restoreAdmission, ok := s.admit(c, order.FlightId, params.XAdmissionToken)
+	if !ok {
+		restoreQuota()
+		return
+	}
+
 	created, err := s.orderService.CreateOrder(c.Request.Context(), service.CreateOrderRequest{
 		FlightID:     order.FlightId,
-		CustomerID:   order.CustomerId,
+		CustomerID:   customerID,
 		TicketAmount: order.TicketAmount,
+		AgencyID:     agencyID,
+		Travelers:    ConvertToTravelerNames(order.Travelers),
 	})
 	if err != nil {
-		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		restoreQuota()
+		restoreAdmission()
+		sendErrorResponse(c, orderErrorStatus(err), err.Error())
 		return
 	}
 
 	c.JSON(http.StatusCreated, ConvertToOrderResponse(created))
 }
 
+func (s *BookingSystem) SubmitOrder(c *gin.Context, params api.SubmitOrderParams) {
+	var order api.CreateOrderRequest
+	err := c.Bind(&order)
+	if err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for order")
+		return
+	}
+
+	customerID, ok := s.orderCustomer(c, order.CustomerId)
+	if !ok {
+		return
+	}
+
+	// Queued orders that fail later still count against the quota
+	agencyID, restoreQuota, ok := s.useBookingQuota(c)
+	if !ok {
+		return
+	}
+
+	restoreAdmission, ok := s.admit(c, order.FlightId, params.XAdmissionToken)
+	if !ok {
+		restoreQuota()
+		return
+	}
+
+	ticket, err := s.orderQueue.Submit(c.Request.Context(), service.CreateOrderRequest{
+		FlightID:     order.FlightId,
+		CustomerID:   customerID,
+		TicketAmount: order.TicketAmount,
+		AgencyID:     agencyID,
+		Travelers:    ConvertToTravelerNames(order.Travelers),
+	})
+	if err != nil {
+		restoreQuota()
+		restoreAdmission()
+		sendErrorResponse(c, orderErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, ConvertToOrderTicketResponse(ticket))
+}
+
+func (s *BookingSystem) GetOrderTicket(c *gin.Context, ticketID string) {
+	ticket, err := s.orderQueue.Ticket(c.Request.Context(), ticketID)
+	if err != nil {
+		if errors.Is(err, service.ErrTicketNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Tickets of others are not found rather than forbidden, so ticket IDs
+	// cannot be probed
+	if principal, _ := auth.PrincipalFrom(c.Request.Context()); !ownsTicket(principal, ticket) {
+		sendErrorResponse(c, http.StatusNotFound, service.ErrTicketNotFound.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, ConvertToOrderTicketResponse(ticket))
+}
+
+func (s *BookingSystem) GetOrder(c *gin.Context, orderNumber api.OrderNumber) {
+	order, ok := s.ownOrder(c, orderNumber)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, ConvertToOrderResponse(order))
+}
+
+func (s *BookingSystem) CancelOrder(c *gin.Context, orderNumber api.OrderNumber) {
+	// Clients of the route before order numbers cancel by the numeric order
+	// ID, which no order number looks like
+	if id, err := strconv.ParseUint(orderNumber, 10, 0); err == nil {
+		order, err := s.orderService.GetOrder(c.Request.Context(), uint(id))
+		if err != nil {
+			sendErrorResponse(c, orderErrorStatus(err), err.Error())
+			return
+		}
+		orderNumber = order.OrderNumber
+	}
+
+	order, ok := s.ownOrder(c, orderNumber)
+	if !ok {
+		return
+	}
+
+	cancelled, err := s.orderService.CancelOrder(c.Request.Context(), order.ID)
+	if err != nil {
+		sendErrorResponse(c, orderErrorStatus(err), err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, ConvertToOrderResponse(cancelled))
+}
+
+// ownOrder returns an order of the caller with its flight, customer,
+// travelers and entitlements. Orders of others are not found rather than forbidden, like
+// tickets.
+func (s *BookingSystem) ownOrder(c *gin.Context, orderNumber string) (*model.Order, bool) {
+	order, err := s.orderService.GetOrderByNumber(c.Request.Context(), orderNumber)
+	if err != nil {
+		sendErrorResponse(c, orderErrorStatus(err), err.Error())
+		return nil, false
+	}
+	if principal, _ := auth.PrincipalFrom(c.Request.Context()); !owns(principal, order.CustomerID, order.AgencyID) {
+		sendErrorResponse(c, http.StatusNotFound, service.ErrOrderNotFound.Error())
+		return nil, false
+	}
+	return order, true
+}
+
+// orderCustomer returns the customer an order is booked for, the signed in
+// customer unless an admin or agency books for another one. Agencies only
+// book for their own customers.
+func (s *BookingSystem) orderCustomer(c *gin.Context, requested *uint) (uint, bool) {
+	principal, ok := auth.PrincipalFrom(c.Request.Context())
+	if !ok {
+		sendErrorResponse(c, http.StatusUnauthorized, auth.ErrUnauthenticated.Error())
+		return 0, false
+	}
+	if principal.IsAgency() {
+		if requested == nil {
+			sendErrorResponse(c, http.StatusBadRequest, "customer_id is required for agency bookings")
+			return 0, false
+		}
+		own, err := s.apiKeyService.IsAgencyCustomer(c.Request.Context(), principal.AgencyID, *requested)
+		if err != nil {
+			sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return 0, false
+		}
+		if !own {
+			sendErrorResponse(c, http.StatusForbidden, "cannot book for a customer of another agency")
+			return 0, false
+		}
+		return *requested, true
+	}
+	if requested == nil || *requested == principal.CustomerID {
+		return principal.CustomerID, true
+	}
+	if !principal.IsAdmin() {
+		sendErrorResponse(c, http.StatusForbidden, "cannot book for another customer")
+		return 0, false
+	}
+	return *requested, true
+}
+
+// useBookingQuota counts an agency booking against the daily quota of its API
+// key. It returns the agency, nil for other bookings, and a func giving the
+// quota back.
+func (s *BookingSystem) useBookingQuota(c *gin.Context) (*uint, func(), bool) {
+	principal, _ := auth.PrincipalFrom(c.Request.Context())
+	if !principal.IsAgency() {
+		return nil, func() {}, true
+	}
+	restore, err := s.apiKeyService.UseBookingQuota(c.Request.Context(), principal.APIKeyID, principal.BookingQuota)
+	if err != nil {
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			sendErrorResponse(c, http.StatusTooManyRequests, err.Error())
+			return nil, nil, false
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	return &principal.AgencyID, restore, true
+}
+
+// ownsTicket reports whether a principal may see an order ticket.
+func ownsTicket(principal auth.Principal, ticket *service.OrderTicket) bool {
+	return owns(principal, ticket.CustomerID, ticket.AgencyID)
+}
+
+// owns reports whether a principal may act on the orders of a customer,
+// booked by an agency or not.
+func owns(principal auth.Principal, customerID uint, agencyID *uint) bool {
+	switch {
+	case principal.IsAdmin():
+		return true
+	case principal.IsAgency():
+		return agencyID != nil && *agencyID == principal.AgencyID
+	default:
+		return principal.CustomerID == customerID
+	}
+}
+
+// orderErrorStatus maps errors of order submission to response status codes.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrFlightNotFound), errors.Is(err, service.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrOrderNotCancellable), errors.Is(err, service.ErrFlightClosed):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrNoAvailableSeats), errors.Is(err, service.ErrFlightSeatLimit):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrTooManyTickets), errors.Is(err, service.ErrInvalidTravelers):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPendingHoldLimit):
+		return http.StatusTooManyRequests
+	case errors.Is(err, inventory.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func ConvertToOrderTicketResponse(ticket *service.OrderTicket) *api.OrderTicket {
+	resp := &api.OrderTicket{
+		Id:           ticket.ID,
+		Status:       api.OrderTicketStatus(ticket.Status),
+		FlightId:     ticket.FlightID,
+		CustomerId:   ticket.CustomerID,
+		TicketAmount: ticket.TicketAmount,
+		OrderNumber:  ticket.OrderNumber,
+		CreatedAt:    ticket.CreatedAt,
+		UpdatedAt:    ticket.UpdatedAt,
+	}
+	if ticket.Error != "" {
+		resp.Error = &ticket.Error
+	}
+	return resp
+}
+
 func ConvertToOrderResponse(order *model.Order) *api.Order {
-	return &api.Order{
-		BookingTime: order.BookingTime,
-		CustomerId:  order.CustomerID,
-		FlightId:    order.FlightID,
-		Id:          order.ID,
-		OrderNumber: order.OrderNumber,
-		Status:      api.OrderStatus(order.Status),
-		TotalAmount: order.TotalAmount,
+	resp := &api.Order{
+		BookingTime:  order.BookingTime,
+		CustomerId:   order.CustomerID,
+		FlightId:     order.FlightID,
+		Id:           order.ID,
+		OrderNumber:  order.OrderNumber,
+		Status:       api.OrderStatus(order.Status),
+		TotalAmount:  order.TotalAmount,
+		TicketAmount: order.TicketAmount,
+		AgencyId:     order.AgencyID,
+	}
+	if len(order.Travelers) > 0 {
+		travelers := make([]api.Traveler, len(order.Travelers))
+		for i, traveler := range order.Travelers {
+			travelers[i] = api.Traveler{
+				Id:          traveler.ID,
+				FirstName:   traveler.FirstName,
+				LastName:    traveler.LastName,
+				CheckedInAt: traveler.CheckedInAt,
+			}
+			if traveler.Seat != "" {
+				travelers[i].Seat = &traveler.Seat
+			}
+		}
+		resp.Travelers = &travelers
+	}
+	if len(order.Entitlements) > 0 {
+		entitlements := make([]api.OrderEntitlement, len(order.Entitlements))
+		for i, entitlement := range order.Entitlements {
+			entitlements[i] = api.OrderEntitlement{
+				Kind:      api.OrderEntitlementKind(entitlement.Kind),
+				Reason:    entitlement.Reason,
+				GrantedAt: entitlement.CreatedAt,
+				UsedAt:    entitlement.UsedAt,
+			}
+		}
+		resp.Entitlements = &entitlements
+	}
+	return resp
+}
+
+func ConvertToTravelerNames(names *[]api.TravelerName) []service.TravelerName {
+	if names == nil {
+		return nil
+	}
+	travelers := make([]service.TravelerName, len(*names))
+	for i, name := range *names {
+		travelers[i] = service.TravelerName{FirstName: name.FirstName, LastName: name.LastName}
 	}
+	return travelers
 }
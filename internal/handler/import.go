@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/importer"
+)
+
+func (s *BookingSystem) ImportFlightSchedule(c *gin.Context, params api.ImportFlightScheduleParams) {
+	if c.Request.ContentLength > s.cfg.MaxImportBytes {
+		sendErrorResponse(c, http.StatusRequestEntityTooLarge, "schedule is too large")
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, s.cfg.MaxImportBytes)
+
+	dryRun := true
+	if params.DryRun != nil {
+		dryRun = *params.DryRun
+	}
+	report, err := importer.ImportSchedule(s.gdb.WithContext(c.Request.Context()), string(params.Format), body, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			sendErrorResponse(c, http.StatusRequestEntityTooLarge, "schedule is too large")
+			return
+		}
+		if errors.Is(err, importer.ErrInvalidSchedule) {
+			sendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, ConvertToImportReportResponse(report))
+}
+
+func ConvertToImportReportResponse(report *importer.Report) *api.ImportReport {
+	resp := &api.ImportReport{
+		DryRun:    report.DryRun,
+		Created:   report.Created,
+		Updated:   report.Updated,
+		Unchanged: report.Unchanged,
+		Changes:   make([]api.ImportChange, len(report.Changes)),
+		Errors:    make([]api.ImportError, len(report.Errors)),
+	}
+	for i, change := range report.Changes {
+		resp.Changes[i] = api.ImportChange{
+			Action:        api.ImportChangeAction(change.Action),
+			Line:          change.Line,
+			FlightNumber:  change.FlightNumber,
+			DepartureDate: openapi_types.Date{Time: change.DepartureDate},
+		}
+		if len(change.Fields) > 0 {
+			fields := make([]api.ImportFieldChange, len(change.Fields))
+			for j, field := range change.Fields {
+				fields[j] = api.ImportFieldChange{Field: field.Field, From: field.From, To: field.To}
+			}
+			resp.Changes[i].Fields = &fields
+		}
+	}
+	for i, lineErr := range report.Errors {
+		resp.Errors[i] = api.ImportError{Line: lineErr.Line, Message: lineErr.Message}
+	}
+	return resp
+}
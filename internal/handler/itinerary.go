@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/notify"
+)
+
+func (s *BookingSystem) GetItineraryPdf(c *gin.Context, orderNumber api.OrderNumber) {
+	order, ok := s.ownOrder(c, orderNumber)
+	if !ok {
+		return
+	}
+
+	pdf, err := notify.ItineraryPDF(notify.NewItinerary(order))
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{
+		"filename": notify.ItineraryFilename(order.OrderNumber),
+	}))
+	c.Data(http.StatusOK, notify.ItineraryContentType, pdf)
+}
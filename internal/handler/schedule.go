@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) ListSchedules(c *gin.Context) {
+	schedules, err := s.scheduleService.ListSchedules(c.Request.Context())
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &api.ListSchedulesResponse{
+		Data: make([]api.Schedule, len(schedules)),
+	}
+	for i := range schedules {
+		resp.Data[i] = *ConvertToScheduleResponse(&schedules[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *BookingSystem) CreateSchedule(c *gin.Context) {
+	var req api.CreateScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for schedule")
+		return
+	}
+
+	schedule := &model.Schedule{
+		FlightNumber:     req.FlightNumber,
+		DepartureAirport: req.DepartureAirport,
+		ArrivalAirport:   req.ArrivalAirport,
+		DaysOfWeek:       req.DaysOfWeek,
+		DepartureTime:    req.DepartureTime,
+		ArrivalTime:      req.ArrivalTime,
+		EffectiveFrom:    req.EffectiveFrom.Time,
+		Aircraft:         req.Aircraft,
+	}
+	if req.Airline != nil {
+		schedule.Airline = *req.Airline
+	}
+	if req.ArrivalDayOffset != nil {
+		schedule.ArrivalDayOffset = *req.ArrivalDayOffset
+	}
+	if req.EffectiveTo != nil {
+		schedule.EffectiveTo = &req.EffectiveTo.Time
+	}
+	if req.TotalSeats != nil {
+		schedule.TotalSeats = *req.TotalSeats
+	}
+	if req.BasePrice != nil {
+		schedule.BasePrice = *req.BasePrice
+	}
+
+	if err := s.scheduleService.CreateSchedule(c.Request.Context(), schedule); err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) {
+			sendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, ConvertToScheduleResponse(schedule))
+}
+
+func ConvertToScheduleResponse(schedule *model.Schedule) *api.Schedule {
+	resp := &api.Schedule{
+		Id:               schedule.ID,
+		FlightNumber:     schedule.FlightNumber,
+		Airline:          schedule.Airline,
+		DepartureAirport: schedule.DepartureAirport,
+		ArrivalAirport:   schedule.ArrivalAirport,
+		DaysOfWeek:       schedule.DaysOfWeek,
+		DepartureTime:    schedule.DepartureTime,
+		ArrivalTime:      schedule.ArrivalTime,
+		ArrivalDayOffset: schedule.ArrivalDayOffset,
+		EffectiveFrom:    openapi_types.Date{Time: schedule.EffectiveFrom},
+		Aircraft:         schedule.Aircraft,
+		TotalSeats:       schedule.TotalSeats,
+		BasePrice:        schedule.BasePrice,
+	}
+	if schedule.EffectiveTo != nil {
+		resp.EffectiveTo = &openapi_types.Date{Time: *schedule.EffectiveTo}
+	}
+	return resp
+}
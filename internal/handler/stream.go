@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) StreamFlightAvailability(c *gin.Context, id uint) {
+	// Every stream holds a Redis subscription, so their number is capped.
+	if s.streams != nil {
+		select {
+		case s.streams <- struct{}{}:
+			defer func() { <-s.streams }()
+		default:
+			sendErrorResponse(c, http.StatusServiceUnavailable, "too many open availability streams")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	seats, updates, err := s.flightService.WatchAvailability(ctx, id)
+	if err != nil {
+		if errors.Is(err, service.ErrFlightNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("availability", api.FlightAvailability{FlightId: id, AvailableSeats: seats})
+	c.Writer.Flush()
+
+	// A nil channel never ticks, for streams without heartbeats
+	var heartbeats <-chan time.Time
+	if s.cfg.StreamHeartbeat > 0 {
+		heartbeat := time.NewTicker(s.cfg.StreamHeartbeat)
+		defer heartbeat.Stop()
+		heartbeats = heartbeat.C
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.closing:
+			return false
+		case seats, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("availability", api.FlightAvailability{FlightId: id, AvailableSeats: seats})
+		case <-heartbeats:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
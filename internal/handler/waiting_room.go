@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) JoinWaitingRoom(c *gin.Context, id uint) {
+	status, err := s.waitingRoom.Join(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrFlightNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, ConvertToWaitingRoomStatusResponse(status))
+}
+
+func (s *BookingSystem) GetWaitingRoomStatus(c *gin.Context, id uint, token string) {
+	status, err := s.waitingRoom.Status(c.Request.Context(), id, token)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownWaitingToken) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, ConvertToWaitingRoomStatusResponse(status))
+}
+
+// admit checks the admission token of a booking while the waiting room is
+// enabled, and sends the error response when it is not valid. The returned
+// function gives the admission back and must be called if the booking fails.
+func (s *BookingSystem) admit(c *gin.Context, flightID uint, token *string) (func(), bool) {
+	if !s.cfg.WaitingRoom.Enabled {
+		return func() {}, true
+	}
+
+	var admissionToken string
+	if token != nil {
+		admissionToken = *token
+	}
+	restore, err := s.waitingRoom.Admit(c.Request.Context(), flightID, admissionToken)
+	if err != nil {
+		if errors.Is(err, service.ErrNotAdmitted) {
+			sendErrorResponse(c, http.StatusForbidden, err.Error())
+			return nil, false
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return restore, true
+}
+
+func ConvertToWaitingRoomStatusResponse(status *service.WaitingRoomStatus) *api.WaitingRoomStatus {
+	resp := &api.WaitingRoomStatus{
+		Token:    status.Token,
+		FlightId: status.FlightID,
+		Admitted: status.Admitted,
+	}
+	if status.Admitted {
+		expiresIn := int(status.ExpiresIn.Seconds())
+		resp.ExpiresIn = &expiresIn
+	} else {
+		resp.Position = &status.Position
+	}
+	return resp
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/service"
+)
+
+func (s *BookingSystem) ListWebhooks(c *gin.Context) {
+	webhooks, err := s.webhookService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := &api.ListWebhooksResponse{
+		Data: make([]api.Webhook, len(webhooks)),
+	}
+	for i := range webhooks {
+		resp.Data[i] = *ConvertToWebhookResponse(&webhooks[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *BookingSystem) CreateWebhook(c *gin.Context) {
+	var req api.CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for webhook")
+		return
+	}
+
+	webhookReq := service.CreateWebhookRequest{URL: req.Url}
+	if req.EventTypes != nil {
+		for _, eventType := range *req.EventTypes {
+			webhookReq.EventTypes = append(webhookReq.EventTypes, string(eventType))
+		}
+	}
+
+	webhook, err := s.webhookService.CreateWebhook(c.Request.Context(), webhookReq)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			sendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	converted := ConvertToWebhookResponse(webhook)
+	c.JSON(http.StatusCreated, &api.CreatedWebhook{
+		Id:         converted.Id,
+		Url:        converted.Url,
+		EventTypes: converted.EventTypes,
+		Active:     converted.Active,
+		CreatedAt:  converted.CreatedAt,
+		Secret:     webhook.Secret,
+	})
+}
+
+func (s *BookingSystem) DeleteWebhook(c *gin.Context, webhookID uint) {
+	if err := s.webhookService.DeleteWebhook(c.Request.Context(), webhookID); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *BookingSystem) ReplayWebhook(c *gin.Context, webhookID uint) {
+	var req api.ReplayWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		sendErrorResponse(c, http.StatusBadRequest, "Invalid format for replay")
+		return
+	}
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	if !req.From.Before(to) {
+		sendErrorResponse(c, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	events, err := s.webhookService.Replay(c.Request.Context(), webhookID, req.From, to)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			sendErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		sendErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusAccepted, &api.ReplayWebhookResponse{Events: events})
+}
+
+func ConvertToWebhookResponse(webhook *model.Webhook) *api.Webhook {
+	resp := &api.Webhook{
+		Id:         webhook.ID,
+		Url:        webhook.URL,
+		EventTypes: []api.WebhookEventType{},
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+	}
+	for _, eventType := range webhook.EventTypeList() {
+		resp.EventTypes = append(resp.EventTypes, api.WebhookEventType(eventType))
+	}
+	return resp
+}
@@ -0,0 +1,219 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joremysh/tonx/internal/catalog"
+)
+
+// csvColumns are the columns of a CSV schedule, the header names them in any
+// order. Columns marked optional may be left out or empty.
+var csvColumns = []struct {
+	name     string
+	optional bool
+}{
+	{name: "flight_number"},
+	{name: "airline", optional: true},
+	{name: "departure_airport"},
+	{name: "arrival_airport"},
+	{name: "period_start"},
+	{name: "period_end"},
+	{name: "days"},
+	{name: "departure_time"},
+	{name: "arrival_time"},
+	{name: "arrival_day_offset", optional: true},
+	{name: "aircraft"},
+	{name: "total_seats", optional: true},
+	{name: "base_price", optional: true},
+}
+
+// parseCSV reads a CSV schedule with a header row and one period per row.
+// Dates are YYYY-MM-DD, times are local HH:MM and days list the days of
+// operation as digits, 1 for Monday.
+func parseCSV(r io.Reader) ([]Period, []LineError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("schedule is empty")
+		}
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range csvColumns {
+		if _, ok := index[column.name]; !ok && !column.optional {
+			return nil, nil, fmt.Errorf("header is missing column %s", column.name)
+		}
+	}
+
+	var periods []Period
+	var errs []LineError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("failed to read schedule: %w", err)
+			}
+			errs = append(errs, LineError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		period, err := csvPeriod(field)
+		if err != nil {
+			errs = append(errs, LineError{Line: line, Message: err.Error()})
+			continue
+		}
+		period.Line = line
+		periods = append(periods, period)
+	}
+	return periods, errs, nil
+}
+
+// csvPeriod builds the period of a row from its fields.
+func csvPeriod(field func(name string) string) (Period, error) {
+	var period Period
+	var err error
+
+	period.FlightNumber = strings.ToUpper(strings.ReplaceAll(field("flight_number"), " ", ""))
+	if len(period.FlightNumber) < 3 {
+		return period, fmt.Errorf("invalid flight number %q", field("flight_number"))
+	}
+	airlineCode := field("airline")
+	if airlineCode == "" {
+		airlineCode = period.FlightNumber[:2]
+	}
+	if period.Airline, err = airlineName(airlineCode); err != nil {
+		return period, err
+	}
+	if period.From, err = airport(field("departure_airport")); err != nil {
+		return period, err
+	}
+	if period.To, err = airport(field("arrival_airport")); err != nil {
+		return period, err
+	}
+	if period.Start, err = time.Parse(time.DateOnly, field("period_start")); err != nil {
+		return period, fmt.Errorf("invalid period start %q", field("period_start"))
+	}
+	if period.End, err = time.Parse(time.DateOnly, field("period_end")); err != nil {
+		return period, fmt.Errorf("invalid period end %q", field("period_end"))
+	}
+	if err = checkPeriod(period.Start, period.End); err != nil {
+		return period, err
+	}
+	if period.Days, err = parseDays(field("days")); err != nil {
+		return period, err
+	}
+	if period.Departure, err = parseClock(field("departure_time"), "15:04"); err != nil {
+		return period, err
+	}
+	if period.Arrival, err = parseClock(field("arrival_time"), "15:04"); err != nil {
+		return period, err
+	}
+	if offset := field("arrival_day_offset"); offset != "" {
+		if period.ArrivalDayOffset, err = strconv.Atoi(offset); err != nil || period.ArrivalDayOffset < -1 || period.ArrivalDayOffset > 3 {
+			return period, fmt.Errorf("invalid arrival day offset %q", offset)
+		}
+	}
+	if period.Aircraft, err = aircraft(field("aircraft")); err != nil {
+		return period, err
+	}
+	if seats := field("total_seats"); seats != "" {
+		if period.Seats, err = strconv.Atoi(seats); err != nil || period.Seats <= 0 {
+			return period, fmt.Errorf("invalid total seats %q", seats)
+		}
+	}
+	if price := field("base_price"); price != "" {
+		if period.BasePrice, err = strconv.Atoi(price); err != nil || period.BasePrice <= 0 {
+			return period, fmt.Errorf("invalid base price %q", price)
+		}
+	}
+	return period, nil
+}
+
+// parseDays reads days of operation as digits from 1 for Monday to 7 for
+// Sunday. Other characters are placeholders, so "1.3.5.." and "135" match.
+func parseDays(s string) ([7]bool, error) {
+	var days [7]bool
+	var any bool
+	for _, c := range s {
+		switch {
+		case c >= '1' && c <= '7':
+			days[c-'1'] = true
+			any = true
+		case c == '.' || c == '-' || c == ' ' || c == '0':
+		default:
+			return days, fmt.Errorf("invalid days of operation %q", s)
+		}
+	}
+	if !any {
+		return days, fmt.Errorf("invalid days of operation %q", s)
+	}
+	return days, nil
+}
+
+// parseClock returns a local time of day in minutes after midnight.
+func parseClock(s, layout string) (int, error) {
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// checkPeriod rejects periods that end before they start or expand too far.
+func checkPeriod(start, end time.Time) error {
+	if end.Before(start) {
+		return fmt.Errorf("period ends on %s before it starts on %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+	if end.Sub(start) > maxPeriod {
+		return fmt.Errorf("period from %s to %s is longer than a year", start.Format(time.DateOnly), end.Format(time.DateOnly))
+	}
+	return nil
+}
+
+func airlineName(code string) (string, error) {
+	airline, ok := catalog.AirlineByCode(strings.ToUpper(code))
+	if !ok {
+		return "", fmt.Errorf("unknown airline %q", code)
+	}
+	return airline.Name, nil
+}
+
+func airport(code string) (catalog.Airport, error) {
+	airport, ok := catalog.AirportByCode(strings.ToUpper(code))
+	if !ok {
+		return airport, fmt.Errorf("unknown airport %q", code)
+	}
+	return airport, nil
+}
+
+func aircraft(name string) (catalog.Aircraft, error) {
+	aircraft, ok := catalog.AircraftByName(name)
+	if !ok {
+		return aircraft, fmt.Errorf("unknown aircraft %q", name)
+	}
+	return aircraft, nil
+}
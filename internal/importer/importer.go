@@ -0,0 +1,376 @@
+// Package importer reads airline schedules in bulk and turns their operating
+// periods into dated flights.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/catalog"
+	"github.com/joremysh/tonx/internal/model"
+)
+
+// Schedule formats
+const (
+	FormatCSV  = "csv"
+	FormatSSIM = "ssim"
+)
+
+// Change actions
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+)
+
+const (
+	// maxPeriod bounds how far a single line expands, open-ended SSIM
+	// periods run this long
+	maxPeriod = 366 * 24 * time.Hour
+	batchSize = 500
+)
+
+// ErrInvalidSchedule is returned for schedule files that cannot be read at all
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Period is a flight operating on some days of the week between two dates
+type Period struct {
+	Line         int
+	Airline      string
+	FlightNumber string
+	From         catalog.Airport
+	To           catalog.Airport
+	// Start and End are the first and last departure dates, as midnight UTC
+	Start time.Time
+	End   time.Time
+	// Days are the days of operation, Monday first
+	Days [7]bool
+	// Fortnightly operates every other week, counted from Start
+	Fortnightly bool
+	// Departure and Arrival are local times in minutes after midnight
+	Departure        int
+	Arrival          int
+	ArrivalDayOffset int
+	// DepartureZone and ArrivalZone override the time zones of the airports,
+	// SSIM states the UTC offsets that apply
+	DepartureZone *time.Location
+	ArrivalZone   *time.Location
+	Aircraft      catalog.Aircraft
+	Seats         int
+	// BasePrice is zero to keep the price of existing flights, and price new
+	// ones from the distance
+	BasePrice int
+}
+
+// LineError is a problem with a line of a schedule file
+type LineError struct {
+	Line    int
+	Message string
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// FieldChange is a field an import changes on an existing flight
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// Change is what an import does to a flight on a date
+type Change struct {
+	Action        string
+	Line          int
+	FlightNumber  string
+	DepartureDate time.Time
+	Fields        []FieldChange
+}
+
+// Report describes an import. Nothing is written when it has errors.
+type Report struct {
+	DryRun    bool
+	Created   int
+	Updated   int
+	Unchanged int
+	Changes   []Change
+	Errors    []LineError
+}
+
+// Parse reads the operating periods of a schedule file. Lines that cannot be
+// parsed are returned as errors, the other periods are still returned.
+func Parse(format string, r io.Reader) ([]Period, []LineError, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatSSIM:
+		return parseSSIM(r)
+	}
+	return nil, nil, fmt.Errorf("unknown schedule format %q", format)
+}
+
+// Instance is a dated flight expanded from the period on Line
+type Instance struct {
+	Line   int
+	Flight model.Flight
+}
+
+// Expand turns periods into one flight per operating date. A flight number
+// operating twice on a date is an error.
+func Expand(periods []Period) ([]Instance, []LineError) {
+	var instances []Instance
+	var errs []LineError
+	seen := make(map[string]int)
+	for _, period := range periods {
+		for date := period.Start; !date.After(period.End); date = date.AddDate(0, 0, 1) {
+			// time.Weekday starts on Sunday
+			if !period.Days[(int(date.Weekday())+6)%7] {
+				continue
+			}
+			if period.Fortnightly && int(date.Sub(period.Start).Hours()/24/7)%2 == 1 {
+				continue
+			}
+			flight := period.flight(date)
+			key := flightKey(flight.FlightNumber, flight.DepartureDate)
+			if line, ok := seen[key]; ok {
+				errs = append(errs, LineError{Line: period.Line, Message: fmt.Sprintf("%s operates on %s already on line %d", flight.FlightNumber, flight.DepartureDate.Format(time.DateOnly), line)})
+				continue
+			}
+			seen[key] = period.Line
+			instances = append(instances, Instance{Line: period.Line, Flight: flight})
+		}
+	}
+	return instances, errs
+}
+
+// flight builds the flight of the period departing on date.
+func (p Period) flight(date time.Time) model.Flight {
+	departureZone, arrivalZone := p.DepartureZone, p.ArrivalZone
+	if departureZone == nil {
+		departureZone = p.From.Location()
+	}
+	if arrivalZone == nil {
+		arrivalZone = p.To.Location()
+	}
+	departure := time.Date(date.Year(), date.Month(), date.Day(), 0, p.Departure, 0, 0, departureZone)
+	arrivalDate := date.AddDate(0, 0, p.ArrivalDayOffset)
+	arrival := time.Date(arrivalDate.Year(), arrivalDate.Month(), arrivalDate.Day(), 0, p.Arrival, 0, 0, arrivalZone)
+
+	seats := p.Seats
+	if seats == 0 {
+		seats = p.Aircraft.Seats
+	}
+	return model.Flight{
+		FlightNumber:     p.FlightNumber,
+		Airline:          p.Airline,
+		DepartureCity:    p.From.City,
+		ArrivalCity:      p.To.City,
+		DepartureAirport: p.From.Code,
+		ArrivalAirport:   p.To.Code,
+		DepartureTime:    departure,
+		DepartureDate:    date,
+		ArrivalTime:      arrival,
+		Aircraft:         p.Aircraft.Name,
+		Status:           string(api.FlightStatusSCHEDULED),
+		TotalSeats:       seats,
+		AvailableSeats:   seats,
+		BasePrice:        p.BasePrice,
+	}
+}
+
+// ImportSchedule parses a schedule file and imports its flights. Nothing is
+// written when any line has an error, they are all in the report.
+func ImportSchedule(gdb *gorm.DB, format string, r io.Reader, dryRun bool) (*Report, error) {
+	periods, errs, err := Parse(format, r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+	instances, expandErrs := Expand(periods)
+	errs = append(errs, expandErrs...)
+
+	// Still plan the lines that parsed, so one run reports everything
+	report, err := Import(gdb, instances, dryRun || len(errs) > 0)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = dryRun
+	report.Errors = append(errs, report.Errors...)
+	slices.SortStableFunc(report.Errors, func(a, b LineError) int {
+		return a.Line - b.Line
+	})
+	return report, nil
+}
+
+// Import creates the flights that do not exist yet and updates the ones that
+// do, matched by flight number and departure date. A dry run only reports
+// what it would do. Capacity changes keep the booked seats booked, the
+// reconciler then carries them over to Redis.
+func Import(gdb *gorm.DB, instances []Instance, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun, Changes: []Change{}}
+	err := gdb.Transaction(func(tx *gorm.DB) error {
+		// Bookings of the flights to update wait for the import, so the
+		// seats they take are not overwritten
+		existing, err := existingFlights(tx, instances, !dryRun)
+		if err != nil {
+			return fmt.Errorf("failed to find existing flights: %w", err)
+		}
+
+		var creates []*model.Flight
+		var updates []*model.Flight
+		for i := range instances {
+			instance := &instances[i]
+			planned := &instance.Flight
+			change := Change{
+				Line:          instance.Line,
+				FlightNumber:  planned.FlightNumber,
+				DepartureDate: planned.DepartureDate,
+			}
+
+			current, ok := existing[flightKey(planned.FlightNumber, planned.DepartureDate)]
+			if !ok {
+				if planned.BasePrice == 0 {
+					from, _ := planned.Origin()
+					to, _ := planned.Destination()
+					planned.BasePrice = catalog.BaseFare(catalog.Distance(from, to))
+				}
+				change.Action = ActionCreate
+				report.Created++
+				creates = append(creates, planned)
+				report.Changes = append(report.Changes, change)
+				continue
+			}
+
+			change.Fields = diff(current, planned)
+			if len(change.Fields) == 0 {
+				change.Action = ActionUnchanged
+				report.Unchanged++
+				report.Changes = append(report.Changes, change)
+				continue
+			}
+			booked := current.TotalSeats - current.AvailableSeats
+			if planned.TotalSeats < booked {
+				report.Errors = append(report.Errors, LineError{Line: instance.Line, Message: fmt.Sprintf("%s on %s has %d seats booked, more than the %d seats imported", planned.FlightNumber, change.DepartureDate.Format(time.DateOnly), booked, planned.TotalSeats)})
+				continue
+			}
+			apply(current, planned)
+			change.Action = ActionUpdate
+			report.Updated++
+			updates = append(updates, current)
+			report.Changes = append(report.Changes, change)
+		}
+
+		if dryRun || len(report.Errors) > 0 {
+			return nil
+		}
+		if len(creates) > 0 {
+			if err = tx.CreateInBatches(creates, batchSize).Error; err != nil {
+				return fmt.Errorf("failed to create flights: %w", err)
+			}
+		}
+		for _, flight := range updates {
+			if err = tx.Save(flight).Error; err != nil {
+				return fmt.Errorf("failed to update flight %s: %w", flight.FlightNumber, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// diff lists the fields of current that planned changes.
+func diff(current, planned *model.Flight) []FieldChange {
+	var changes []FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("airline", current.Airline, planned.Airline)
+	add("departure_city", current.DepartureCity, planned.DepartureCity)
+	add("arrival_city", current.ArrivalCity, planned.ArrivalCity)
+	add("departure_airport", current.DepartureAirport, planned.DepartureAirport)
+	add("arrival_airport", current.ArrivalAirport, planned.ArrivalAirport)
+	add("departure_time", current.DepartureTime.UTC().Format(time.RFC3339), planned.DepartureTime.UTC().Format(time.RFC3339))
+	add("arrival_time", current.ArrivalTime.UTC().Format(time.RFC3339), planned.ArrivalTime.UTC().Format(time.RFC3339))
+	add("aircraft", current.Aircraft, planned.Aircraft)
+	add("total_seats", strconv.Itoa(current.TotalSeats), strconv.Itoa(planned.TotalSeats))
+	if planned.BasePrice != 0 {
+		add("base_price", strconv.Itoa(current.BasePrice), strconv.Itoa(planned.BasePrice))
+	}
+	return changes
+}
+
+// apply copies the scheduled fields of planned to current.
+func apply(current, planned *model.Flight) {
+	current.AvailableSeats += planned.TotalSeats - current.TotalSeats
+	current.Revision++
+	current.Airline = planned.Airline
+	current.DepartureCity = planned.DepartureCity
+	current.ArrivalCity = planned.ArrivalCity
+	current.DepartureAirport = planned.DepartureAirport
+	current.ArrivalAirport = planned.ArrivalAirport
+	current.DepartureTime = planned.DepartureTime
+	current.ArrivalTime = planned.ArrivalTime
+	current.Aircraft = planned.Aircraft
+	current.TotalSeats = planned.TotalSeats
+	if planned.BasePrice != 0 {
+		current.BasePrice = planned.BasePrice
+	}
+}
+
+// existingFlights returns the stored flights of the instances by flight number
+// and departure date, locked for update if lock is set. Only the dates the
+// instances span are read, so the history of a flight number is not locked.
+func existingFlights(tx *gorm.DB, instances []Instance, lock bool) (map[string]*model.Flight, error) {
+	if len(instances) == 0 {
+		return map[string]*model.Flight{}, nil
+	}
+	var numbers []string
+	seen := make(map[string]bool)
+	first, last := instances[0].Flight.DepartureDate, instances[0].Flight.DepartureDate
+	for _, instance := range instances {
+		if !seen[instance.Flight.FlightNumber] {
+			seen[instance.Flight.FlightNumber] = true
+			numbers = append(numbers, instance.Flight.FlightNumber)
+		}
+		if date := instance.Flight.DepartureDate; date.Before(first) {
+			first = date
+		} else if date.After(last) {
+			last = date
+		}
+	}
+
+	found := make(map[string]*model.Flight)
+	for start := 0; start < len(numbers); start += batchSize {
+		var flights []*model.Flight
+		end := min(start+batchSize, len(numbers))
+		query := tx.Where("flight_number IN ? AND departure_date BETWEEN ? AND ?", numbers[start:end],
+			first.Format(time.DateOnly), last.Format(time.DateOnly))
+		if lock {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := query.Find(&flights).Error; err != nil {
+			return nil, err
+		}
+		for _, flight := range flights {
+			found[flightKey(flight.FlightNumber, flight.DepartureDate)] = flight
+		}
+	}
+	return found, nil
+}
+
+func flightKey(number string, date time.Time) string {
+	return number + "/" + date.Format(time.DateOnly)
+}
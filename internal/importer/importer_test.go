@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testCSV = `flight_number,departure_airport,arrival_airport,period_start,period_end,days,departure_time,arrival_time,arrival_day_offset,aircraft,total_seats,base_price
+BR12,TPE,YVR,2026-11-02,2026-11-15,1.3.5..,23:40,19:30,0,789,,
+BR11,YVR,TPE,2026-11-02,2026-11-15,135,01:30,05:40,1,Boeing 787-9,280,21000
+XX1,TPE,YVR,2026-11-02,2026-11-15,1,23:40,19:30,0,789,,
+BR13,TPE,ZZZ,2026-11-02,2026-11-15,1,23:40,19:30,0,789,,
+BR14,TPE,YVR,2026-11-15,2026-11-02,1,23:40,19:30,0,789,,
+`
+
+func TestParseCSV(t *testing.T) {
+	periods, errs, err := Parse(FormatCSV, strings.NewReader(testCSV))
+	require.NoError(t, err)
+	require.Len(t, periods, 2)
+	require.Equal(t, []int{4, 5, 6}, []int{errs[0].Line, errs[1].Line, errs[2].Line})
+	require.Contains(t, errs[0].Message, "unknown airline")
+	require.Contains(t, errs[1].Message, "unknown airport")
+	require.Contains(t, errs[2].Message, "before it starts")
+
+	br12 := periods[0]
+	require.Equal(t, 2, br12.Line)
+	require.Equal(t, "EVA AIR", br12.Airline)
+	require.Equal(t, "TPE", br12.From.Code)
+	require.Equal(t, "YVR", br12.To.Code)
+	require.Equal(t, [7]bool{true, false, true, false, true, false, false}, br12.Days)
+	require.Equal(t, 23*60+40, br12.Departure)
+	require.Equal(t, "789", br12.Aircraft.Code)
+	require.Zero(t, br12.Seats)
+	require.Zero(t, br12.BasePrice)
+
+	br11 := periods[1]
+	require.Equal(t, br12.Days, br11.Days)
+	require.Equal(t, 1, br11.ArrivalDayOffset)
+	require.Equal(t, 280, br11.Seats)
+	require.Equal(t, 21000, br11.BasePrice)
+}
+
+func TestParseCSV_MissingColumn(t *testing.T) {
+	_, _, err := Parse(FormatCSV, strings.NewReader("flight_number,departure_airport\nBR12,TPE\n"))
+	require.ErrorContains(t, err, "missing column")
+}
+
+// ssimRecord lays fields out at their 1-based SSIM positions.
+func ssimRecord(fields map[int]string) string {
+	record := []byte(strings.Repeat(" ", 200))
+	record[0] = '3'
+	for position, value := range fields {
+		copy(record[position-1:], value)
+	}
+	return string(record)
+}
+
+func TestParseSSIM(t *testing.T) {
+	leg := map[int]string{
+		3: "BR ", 6: "0012", 12: "01", 14: "J",
+		15: "02NOV26", 22: "15NOV26", 29: "1 3 5  ",
+		37: "TPE", 40: "2340", 44: "2340", 48: "+0800",
+		55: "YVR", 58: "1930", 62: "1930", 66: "-0800",
+		73: "789", 193: "00",
+	}
+	nextDay := map[int]string{}
+	for k, v := range leg {
+		nextDay[k] = v
+	}
+	nextDay[6], nextDay[193], nextDay[36] = "0011", "01", "2"
+	secondLeg := map[int]string{}
+	for k, v := range leg {
+		secondLeg[k] = v
+	}
+	secondLeg[12] = "02"
+
+	file := strings.Join([]string{
+		"1AIRLINE STANDARD SCHEDULE DATA SET",
+		ssimRecord(leg),
+		ssimRecord(nextDay),
+		ssimRecord(secondLeg),
+		"3 BR",
+	}, "\n")
+	periods, errs, err := Parse(FormatSSIM, strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, periods, 2)
+	require.Len(t, errs, 2)
+	require.Equal(t, 4, errs[0].Line)
+	require.Contains(t, errs[0].Message, "multi-leg")
+	require.Equal(t, 5, errs[1].Line)
+
+	br12 := periods[0]
+	require.Equal(t, 2, br12.Line)
+	require.Equal(t, "BR12", br12.FlightNumber)
+	require.Equal(t, "EVA AIR", br12.Airline)
+	require.Equal(t, time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC), br12.Start)
+	require.Equal(t, time.Date(2026, time.November, 15, 0, 0, 0, 0, time.UTC), br12.End)
+	require.Equal(t, [7]bool{true, false, true, false, true, false, false}, br12.Days)
+	require.False(t, br12.Fortnightly)
+	require.Equal(t, 19*60+30, br12.Arrival)
+	require.Zero(t, br12.ArrivalDayOffset)
+	_, offset := time.Date(2026, time.November, 2, 0, 0, 0, 0, br12.ArrivalZone).Zone()
+	require.Equal(t, -8*3600, offset)
+
+	br11 := periods[1]
+	require.Equal(t, "BR11", br11.FlightNumber)
+	require.Equal(t, 1, br11.ArrivalDayOffset)
+	require.True(t, br11.Fortnightly)
+}
+
+func TestExpand(t *testing.T) {
+	periods, errs, err := Parse(FormatCSV, strings.NewReader(testCSV))
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+
+	instances, errs := Expand(periods)
+	require.Empty(t, errs)
+	// Monday, Wednesday and Friday of two weeks
+	require.Len(t, instances, 12)
+
+	first := instances[0].Flight
+	require.Equal(t, "BR12", first.FlightNumber)
+	require.Equal(t, "Taipei", first.DepartureCity)
+	require.Equal(t, "Vancouver", first.ArrivalCity)
+	require.Equal(t, "Boeing 787-9", first.Aircraft)
+	require.Equal(t, time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC), first.DepartureDate)
+	require.Equal(t, time.Date(2026, time.November, 2, 15, 40, 0, 0, time.UTC), first.DepartureTime.UTC())
+	require.Equal(t, time.Date(2026, time.November, 3, 3, 30, 0, 0, time.UTC), first.ArrivalTime.UTC())
+	require.Equal(t, first.TotalSeats, first.AvailableSeats)
+	require.Equal(t, "SCHEDULED", first.Status)
+
+	returning := instances[6]
+	require.Equal(t, "BR11", returning.Flight.FlightNumber)
+	require.Equal(t, 3, returning.Line)
+	require.Equal(t, 280, returning.Flight.TotalSeats)
+	require.Equal(t, time.Date(2026, time.November, 3, 5, 40, 0, 0, time.FixedZone("", 8*3600)).UTC(), returning.Flight.ArrivalTime.UTC())
+
+	fortnightly := periods[0]
+	fortnightly.Fortnightly = true
+	instances, _ = Expand([]Period{fortnightly})
+	require.Len(t, instances, 3)
+
+	_, errs = Expand([]Period{periods[0], periods[0]})
+	require.Len(t, errs, 6)
+	require.Contains(t, errs[0].Message, "already on line 2")
+}
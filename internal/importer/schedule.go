@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joremysh/tonx/internal/catalog"
+	"github.com/joremysh/tonx/internal/model"
+)
+
+// NormalizeSchedule checks a schedule against the catalog and fills in what it
+// leaves out: the airline from the flight number, the seats of the aircraft
+// and a fare for the distance.
+func NormalizeSchedule(schedule *model.Schedule) error {
+	schedule.FlightNumber = strings.ToUpper(strings.ReplaceAll(schedule.FlightNumber, " ", ""))
+	if len(schedule.FlightNumber) < 3 {
+		return fmt.Errorf("invalid flight number %q", schedule.FlightNumber)
+	}
+	if schedule.Airline == "" {
+		name, err := airlineName(schedule.FlightNumber[:2])
+		if err != nil {
+			return err
+		}
+		schedule.Airline = name
+	} else if airline, ok := catalog.AirlineByCode(schedule.Airline); ok {
+		schedule.Airline = airline.Name
+	}
+
+	from, err := airport(schedule.DepartureAirport)
+	if err != nil {
+		return err
+	}
+	to, err := airport(schedule.ArrivalAirport)
+	if err != nil {
+		return err
+	}
+	schedule.DepartureAirport, schedule.ArrivalAirport = from.Code, to.Code
+
+	days, err := parseDays(schedule.DaysOfWeek)
+	if err != nil {
+		return err
+	}
+	schedule.DaysOfWeek = formatDays(days)
+	if _, err = parseClock(schedule.DepartureTime, "15:04"); err != nil {
+		return err
+	}
+	if _, err = parseClock(schedule.ArrivalTime, "15:04"); err != nil {
+		return err
+	}
+	if schedule.ArrivalDayOffset < -1 || schedule.ArrivalDayOffset > 3 {
+		return fmt.Errorf("invalid arrival day offset %d", schedule.ArrivalDayOffset)
+	}
+
+	schedule.EffectiveFrom = model.Date(schedule.EffectiveFrom)
+	if schedule.EffectiveTo != nil {
+		effectiveTo := model.Date(*schedule.EffectiveTo)
+		if effectiveTo.Before(schedule.EffectiveFrom) {
+			return fmt.Errorf("schedule ends on %s before it starts on %s", effectiveTo.Format(time.DateOnly), schedule.EffectiveFrom.Format(time.DateOnly))
+		}
+		schedule.EffectiveTo = &effectiveTo
+	}
+
+	aircraft, err := aircraft(schedule.Aircraft)
+	if err != nil {
+		return err
+	}
+	schedule.Aircraft = aircraft.Name
+	if schedule.TotalSeats == 0 {
+		schedule.TotalSeats = aircraft.Seats
+	}
+	if schedule.BasePrice == 0 {
+		schedule.BasePrice = catalog.BaseFare(catalog.Distance(from, to))
+	}
+	return nil
+}
+
+// SchedulePeriod returns the period a normalized schedule operates between
+// from and to, or false if it does not operate between them at all.
+func SchedulePeriod(schedule *model.Schedule, from, to time.Time) (Period, bool, error) {
+	period := Period{
+		Airline:          schedule.Airline,
+		FlightNumber:     schedule.FlightNumber,
+		Start:            model.Date(from),
+		End:              model.Date(to),
+		ArrivalDayOffset: schedule.ArrivalDayOffset,
+		Seats:            schedule.TotalSeats,
+		BasePrice:        schedule.BasePrice,
+	}
+	if schedule.EffectiveFrom.After(period.Start) {
+		period.Start = schedule.EffectiveFrom
+	}
+	if schedule.EffectiveTo != nil && schedule.EffectiveTo.Before(period.End) {
+		period.End = *schedule.EffectiveTo
+	}
+	if period.End.Before(period.Start) {
+		return period, false, nil
+	}
+
+	var err error
+	if period.From, err = airport(schedule.DepartureAirport); err != nil {
+		return period, false, err
+	}
+	if period.To, err = airport(schedule.ArrivalAirport); err != nil {
+		return period, false, err
+	}
+	if period.Days, err = parseDays(schedule.DaysOfWeek); err != nil {
+		return period, false, err
+	}
+	if period.Departure, err = parseClock(schedule.DepartureTime, "15:04"); err != nil {
+		return period, false, err
+	}
+	if period.Arrival, err = parseClock(schedule.ArrivalTime, "15:04"); err != nil {
+		return period, false, err
+	}
+	if period.Aircraft, err = aircraft(schedule.Aircraft); err != nil {
+		return period, false, err
+	}
+	return period, true, nil
+}
+
+// formatDays writes days of operation as digits, 1 for Monday.
+func formatDays(days [7]bool) string {
+	var b strings.Builder
+	for i, operates := range days {
+		if operates {
+			b.WriteByte(byte('1' + i))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+func testSchedule() *model.Schedule {
+	effectiveTo := time.Date(2026, time.November, 30, 0, 0, 0, 0, time.UTC)
+	return &model.Schedule{
+		FlightNumber:     "br 12",
+		DepartureAirport: "tpe",
+		ArrivalAirport:   "YVR",
+		DaysOfWeek:       "5.3.1",
+		DepartureTime:    "23:40",
+		ArrivalTime:      "19:30",
+		EffectiveFrom:    time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC),
+		EffectiveTo:      &effectiveTo,
+		Aircraft:         "789",
+	}
+}
+
+func TestNormalizeSchedule(t *testing.T) {
+	schedule := testSchedule()
+	require.NoError(t, NormalizeSchedule(schedule))
+	require.Equal(t, "BR12", schedule.FlightNumber)
+	require.Equal(t, "EVA AIR", schedule.Airline)
+	require.Equal(t, "TPE", schedule.DepartureAirport)
+	require.Equal(t, "135", schedule.DaysOfWeek)
+	require.Equal(t, "Boeing 787-9", schedule.Aircraft)
+	require.Positive(t, schedule.TotalSeats)
+	require.Positive(t, schedule.BasePrice)
+
+	invalid := testSchedule()
+	invalid.DepartureTime = "25:00"
+	require.ErrorContains(t, NormalizeSchedule(invalid), "invalid time")
+
+	invalid = testSchedule()
+	invalid.EffectiveFrom = invalid.EffectiveTo.AddDate(0, 0, 1)
+	require.ErrorContains(t, NormalizeSchedule(invalid), "before it starts")
+}
+
+func TestSchedulePeriod(t *testing.T) {
+	schedule := testSchedule()
+	require.NoError(t, NormalizeSchedule(schedule))
+
+	// Clamped to the effective dates
+	period, ok, err := SchedulePeriod(schedule, time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, schedule.EffectiveFrom, period.Start)
+	require.Equal(t, *schedule.EffectiveTo, period.End)
+
+	instances, errs := Expand([]Period{period})
+	require.Empty(t, errs)
+	// Four weeks of Monday, Wednesday and Friday
+	require.Len(t, instances, 13)
+	require.Equal(t, schedule.TotalSeats, instances[0].Flight.TotalSeats)
+	require.Equal(t, schedule.BasePrice, instances[0].Flight.BasePrice)
+
+	// Clamped to the window
+	period, ok, err = SchedulePeriod(schedule, time.Date(2026, time.November, 9, 0, 0, 0, 0, time.UTC), time.Date(2026, time.November, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, ok)
+	instances, _ = Expand([]Period{period})
+	require.Len(t, instances, 3)
+
+	_, ok, err = SchedulePeriod(schedule, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ssimDateLayout is the DDMMMYY date of SSIM periods
+const ssimDateLayout = "02Jan06"
+
+// parseSSIM reads the flight leg records, type 3, of an IATA SSIM Chapter 7
+// file. Header, carrier and trailer records are skipped. Positions below are
+// the 1-based columns of the standard.
+func parseSSIM(r io.Reader) ([]Period, []LineError, error) {
+	var periods []Period
+	var errs []LineError
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record := strings.TrimRight(scanner.Text(), "\r")
+		if !strings.HasPrefix(record, "3") {
+			continue
+		}
+		period, err := ssimPeriod(record)
+		if err != nil {
+			errs = append(errs, LineError{Line: line, Message: err.Error()})
+			continue
+		}
+		period.Line = line
+		periods = append(periods, period)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read schedule: %w", err)
+	}
+	return periods, errs, nil
+}
+
+// ssimPeriod builds the period of a flight leg record.
+func ssimPeriod(record string) (Period, error) {
+	var period Period
+	var err error
+	if len(record) < 75 {
+		return period, fmt.Errorf("flight leg record is %d characters, expected 200", len(record))
+	}
+	field := func(from, to int) string {
+		if to > len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[from-1 : to])
+	}
+
+	if leg := field(12, 13); leg != "" && leg != "01" {
+		return period, fmt.Errorf("leg %s of a multi-leg flight is not supported", leg)
+	}
+	airlineCode := field(3, 5)
+	if period.Airline, err = airlineName(airlineCode); err != nil {
+		return period, err
+	}
+	number, err := strconv.Atoi(field(6, 9))
+	if err != nil || number <= 0 {
+		return period, fmt.Errorf("invalid flight number %q", field(6, 9))
+	}
+	period.FlightNumber = fmt.Sprintf("%s%d", airlineCode, number)
+
+	if period.Start, err = time.Parse(ssimDateLayout, field(15, 21)); err != nil {
+		return period, fmt.Errorf("invalid period start %q", field(15, 21))
+	}
+	if end := field(22, 28); end == "00XXX00" {
+		// Open-ended, cut off at the longest period imported
+		period.End = period.Start.Add(maxPeriod)
+	} else if period.End, err = time.Parse(ssimDateLayout, end); err != nil {
+		return period, fmt.Errorf("invalid period end %q", end)
+	}
+	if err = checkPeriod(period.Start, period.End); err != nil {
+		return period, err
+	}
+	if period.Days, err = parseDays(record[28:35]); err != nil {
+		return period, err
+	}
+	switch rate := field(36, 36); rate {
+	case "", "1":
+	case "2":
+		period.Fortnightly = true
+	default:
+		return period, fmt.Errorf("invalid frequency rate %q", rate)
+	}
+
+	if period.From, err = airport(field(37, 39)); err != nil {
+		return period, err
+	}
+	if period.Departure, err = parseClock(field(40, 43), "1504"); err != nil {
+		return period, err
+	}
+	if period.DepartureZone, err = parseVariation(field(48, 52)); err != nil {
+		return period, err
+	}
+	if period.To, err = airport(field(55, 57)); err != nil {
+		return period, err
+	}
+	if period.Arrival, err = parseClock(field(62, 65), "1504"); err != nil {
+		return period, err
+	}
+	if period.ArrivalZone, err = parseVariation(field(66, 70)); err != nil {
+		return period, err
+	}
+	if period.Aircraft, err = aircraft(field(73, 75)); err != nil {
+		return period, err
+	}
+
+	// The date variations count days from the dates of the period, which are
+	// the local departure dates of the first leg
+	if len(record) >= 194 {
+		departureDays, err := parseDateVariation(record[192])
+		if err != nil {
+			return period, err
+		}
+		arrivalDays, err := parseDateVariation(record[193])
+		if err != nil {
+			return period, err
+		}
+		period.ArrivalDayOffset = arrivalDays - departureDays
+	}
+	return period, nil
+}
+
+// parseVariation returns the zone of a UTC/local time variation like +0800.
+// An empty variation leaves the time zone of the airport.
+func parseVariation(s string) (*time.Location, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if len(s) != 5 || (s[0] != '+' && s[0] != '-') {
+		return nil, fmt.Errorf("invalid UTC time variation %q", s)
+	}
+	hours, err := strconv.Atoi(s[1:3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid UTC time variation %q", s)
+	}
+	minutes, err := strconv.Atoi(s[3:5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid UTC time variation %q", s)
+	}
+	offset := hours*3600 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone(s, offset), nil
+}
+
+// parseDateVariation returns the days of a date variation, A is the day
+// before.
+func parseDateVariation(c byte) (int, error) {
+	switch {
+	case c == 'A':
+		return -1, nil
+	case c == ' ':
+		return 0, nil
+	case c >= '0' && c <= '9':
+		return int(c - '0'), nil
+	}
+	return 0, fmt.Errorf("invalid date variation %q", c)
+}
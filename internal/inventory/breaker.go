@@ -0,0 +1,113 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/joremysh/tonx/pkg/breaker"
+)
+
+// ErrUnavailable is returned when the seat inventory cannot be reached, or
+// its breaker is open. Bookings may fall back to the database then.
+var ErrUnavailable = errors.New("seat inventory unavailable")
+
+// breakerInventory guards a SeatInventory with a circuit breaker, so an
+// unreachable backend fails fast instead of on every call's timeout
+type breakerInventory struct {
+	next    SeatInventory
+	breaker *breaker.Breaker
+}
+
+// WithBreaker wraps next in b. Errors other than the answers of the inventory,
+// such as ErrNoAvailableSeats and ErrNotInitialized, count as failures and are
+// returned as ErrUnavailable.
+func WithBreaker(next SeatInventory, b *breaker.Breaker) SeatInventory {
+	return &breakerInventory{
+		next:    next,
+		breaker: b,
+	}
+}
+
+func (b *breakerInventory) Init(ctx context.Context, flightID uint, availableSeats int) error {
+	return b.call(func() error {
+		return b.next.Init(ctx, flightID, availableSeats)
+	})
+}
+
+func (b *breakerInventory) InitCustomer(ctx context.Context, flightID, customerID uint, bookedSeats int) error {
+	return b.call(func() error {
+		return b.next.InitCustomer(ctx, flightID, customerID, bookedSeats)
+	})
+}
+
+func (b *breakerInventory) Get(ctx context.Context, flightID uint) (int, error) {
+	var seats int
+	err := b.call(func() (err error) {
+		seats, err = b.next.Get(ctx, flightID)
+		return err
+	})
+	return seats, err
+}
+
+func (b *breakerInventory) Reserve(ctx context.Context, flightID uint, hold Hold, ttl time.Duration) (string, error) {
+	var reservation string
+	err := b.call(func() (err error) {
+		reservation, err = b.next.Reserve(ctx, flightID, hold, ttl)
+		return err
+	})
+	return reservation, err
+}
+
+func (b *breakerInventory) Release(ctx context.Context, flightID, customerID uint, reservation string) error {
+	return b.call(func() error {
+		return b.next.Release(ctx, flightID, customerID, reservation)
+	})
+}
+
+func (b *breakerInventory) Confirm(ctx context.Context, flightID, customerID uint, reservation string) error {
+	return b.call(func() error {
+		return b.next.Confirm(ctx, flightID, customerID, reservation)
+	})
+}
+
+func (b *breakerInventory) Restock(ctx context.Context, flightID, customerID uint, seats int) error {
+	return b.call(func() error {
+		return b.next.Restock(ctx, flightID, customerID, seats)
+	})
+}
+
+func (b *breakerInventory) CloseFlight(ctx context.Context, flightID uint) error {
+	return b.call(func() error {
+		return b.next.CloseFlight(ctx, flightID)
+	})
+}
+
+// call runs fn through the breaker, telling answers of the inventory apart
+// from failures to reach it.
+func (b *breakerInventory) call(fn func() error) error {
+	var result error
+	err := b.breaker.Execute(func() error {
+		result = fn()
+		if isAnswer(result) {
+			return nil
+		}
+		return result
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return result
+}
+
+// isAnswer reports whether err is an answer of the inventory rather than a
+// failure to reach it.
+func isAnswer(err error) bool {
+	for _, answer := range []error{ErrNoAvailableSeats, ErrNotInitialized, ErrCustomerNotInitialized, ErrFlightSeatLimit, ErrPendingHoldLimit} {
+		if errors.Is(err, answer) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/pkg/breaker"
+)
+
+// flakyInventory fails every call while down
+type flakyInventory struct {
+	SeatInventory
+	down bool
+}
+
+func (f *flakyInventory) Reserve(ctx context.Context, flightID uint, hold Hold, ttl time.Duration) (string, error) {
+	if f.down {
+		return "", errors.New("connection refused")
+	}
+	return f.SeatInventory.Reserve(ctx, flightID, hold, ttl)
+}
+
+func TestWithBreaker(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyInventory{SeatInventory: NewMemoryInventory()}
+	b := breaker.New(breaker.Settings{MaxFailures: 2, OpenTimeout: time.Minute})
+	seats := WithBreaker(flaky, b)
+	require.NoError(t, seats.Init(ctx, 1, 1))
+
+	// Answers of the inventory are not failures
+	_, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Minute)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Minute)
+		require.ErrorIs(t, err, ErrNoAvailableSeats)
+	}
+	require.Equal(t, breaker.StateClosed, b.State())
+
+	flaky.down = true
+	for i := 0; i < 2; i++ {
+		_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Minute)
+		require.ErrorIs(t, err, ErrUnavailable)
+	}
+	require.Equal(t, breaker.StateOpen, b.State())
+
+	_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Minute)
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorIs(t, err, breaker.ErrOpen)
+}
@@ -0,0 +1,55 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotInitialized   = errors.New("flight seats not initialized")
+	ErrNoAvailableSeats = errors.New("no available seats")
+	// ErrCustomerNotInitialized is returned when a hold is checked against a
+	// per flight limit before the customer's seats on the flight are set
+	ErrCustomerNotInitialized = errors.New("customer seats not initialized")
+	ErrFlightSeatLimit        = errors.New("seat limit per customer on this flight reached")
+	ErrPendingHoldLimit       = errors.New("too many pending bookings")
+)
+
+// Hold is a customer's request for seats of a flight, with the limits that
+// are checked atomically with taking the seats. Zero limits are not checked.
+type Hold struct {
+	CustomerID uint
+	Seats      int
+	// MaxFlightSeats caps the seats the customer holds and booked on the
+	// flight
+	MaxFlightSeats int
+	// MaxPending caps the reservations of the customer, on any flight, that
+	// are neither confirmed nor released
+	MaxPending int
+}
+
+// SeatInventory defines the interface for the fast path seat counters that
+// bookings reserve from before they are written to the database
+type SeatInventory interface {
+	// Init sets the available seats of a flight unless they are set already
+	Init(ctx context.Context, flightID uint, availableSeats int) error
+	// InitCustomer sets the seats a customer booked on a flight unless they
+	// are set already
+	InitCustomer(ctx context.Context, flightID, customerID uint, bookedSeats int) error
+	// Get returns the available seats of a flight, or ErrNotInitialized
+	Get(ctx context.Context, flightID uint) (int, error)
+	// Reserve takes seats of a flight for a hold and returns the token of the
+	// reservation, which can be released until ttl has passed
+	Reserve(ctx context.Context, flightID uint, hold Hold, ttl time.Duration) (string, error)
+	// Release gives the seats of a reservation back. Releasing a reservation
+	// more than once, or after it was confirmed, does nothing.
+	Release(ctx context.Context, flightID, customerID uint, reservation string) error
+	// Confirm keeps the seats of a reservation for good
+	Confirm(ctx context.Context, flightID, customerID uint, reservation string) error
+	// Restock gives the seats of a cancelled booking back
+	Restock(ctx context.Context, flightID, customerID uint, seats int) error
+	// CloseFlight takes every seat of a cancelled or completed flight off
+	// sale
+	CloseFlight(ctx context.Context, flightID uint) error
+}
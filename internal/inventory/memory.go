@@ -0,0 +1,170 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryInventory implements SeatInventory in process. The counters are not
+// shared, so it only suits a single server, such as in development and tests.
+type memoryInventory struct {
+	mu           sync.Mutex
+	seats        map[uint]int
+	customers    map[customerFlight]int
+	reservations map[string]memoryReservation
+}
+
+// customerFlight identifies the seats of a customer on a flight
+type customerFlight struct {
+	flightID   uint
+	customerID uint
+}
+
+type memoryReservation struct {
+	flightID   uint
+	customerID uint
+	seats      int
+	expiresAt  time.Time
+}
+
+// NewMemoryInventory creates a new instance of SeatInventory kept in memory
+func NewMemoryInventory() SeatInventory {
+	return &memoryInventory{
+		seats:        make(map[uint]int),
+		customers:    make(map[customerFlight]int),
+		reservations: make(map[string]memoryReservation),
+	}
+}
+
+func (m *memoryInventory) Init(_ context.Context, flightID uint, availableSeats int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seats[flightID]; !ok {
+		m.seats[flightID] = availableSeats
+	}
+	return nil
+}
+
+func (m *memoryInventory) InitCustomer(_ context.Context, flightID, customerID uint, bookedSeats int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := customerFlight{flightID: flightID, customerID: customerID}
+	if _, ok := m.customers[key]; !ok {
+		m.customers[key] = bookedSeats
+	}
+	return nil
+}
+
+func (m *memoryInventory) Get(_ context.Context, flightID uint) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seats, ok := m.seats[flightID]
+	if !ok {
+		return 0, ErrNotInitialized
+	}
+	return seats, nil
+}
+
+func (m *memoryInventory) Reserve(_ context.Context, flightID uint, hold Hold, ttl time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	available, ok := m.seats[flightID]
+	if !ok {
+		return "", ErrNotInitialized
+	}
+	if available < hold.Seats {
+		return "", ErrNoAvailableSeats
+	}
+
+	key := customerFlight{flightID: flightID, customerID: hold.CustomerID}
+	booked, tracked := m.customers[key]
+	if hold.MaxFlightSeats > 0 {
+		if !tracked {
+			return "", ErrCustomerNotInitialized
+		}
+		if booked+hold.Seats > hold.MaxFlightSeats {
+			return "", ErrFlightSeatLimit
+		}
+	}
+
+	// Expired reservations can no longer be released, drop them like Redis
+	// drops expired keys.
+	now := time.Now()
+	pending := 0
+	for token, reservation := range m.reservations {
+		if !now.Before(reservation.expiresAt) {
+			delete(m.reservations, token)
+		} else if reservation.customerID == hold.CustomerID {
+			pending++
+		}
+	}
+	if hold.MaxPending > 0 && pending >= hold.MaxPending {
+		return "", ErrPendingHoldLimit
+	}
+
+	token := uuid.New().String()
+	m.seats[flightID] = available - hold.Seats
+	if tracked {
+		m.customers[key] = booked + hold.Seats
+	}
+	m.reservations[token] = memoryReservation{
+		flightID:   flightID,
+		customerID: hold.CustomerID,
+		seats:      hold.Seats,
+		expiresAt:  now.Add(ttl),
+	}
+	return token, nil
+}
+
+func (m *memoryInventory) Release(_ context.Context, flightID, customerID uint, reservation string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.reservations[reservation]
+	if !ok || r.flightID != flightID || r.customerID != customerID {
+		return nil
+	}
+	delete(m.reservations, reservation)
+	if !time.Now().Before(r.expiresAt) {
+		return nil
+	}
+	if _, ok = m.seats[flightID]; ok {
+		m.seats[flightID] += r.seats
+	}
+	key := customerFlight{flightID: flightID, customerID: customerID}
+	if _, ok = m.customers[key]; ok {
+		m.customers[key] -= r.seats
+	}
+	return nil
+}
+
+func (m *memoryInventory) Confirm(_ context.Context, flightID, customerID uint, reservation string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.reservations[reservation]; ok && r.flightID == flightID && r.customerID == customerID {
+		delete(m.reservations, reservation)
+	}
+	return nil
+}
+
+func (m *memoryInventory) Restock(_ context.Context, flightID, customerID uint, seats int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seats[flightID]; ok {
+		m.seats[flightID] += seats
+	}
+	key := customerFlight{flightID: flightID, customerID: customerID}
+	if _, ok := m.customers[key]; ok {
+		m.customers[key] -= seats
+	}
+	return nil
+}
+
+func (m *memoryInventory) CloseFlight(_ context.Context, flightID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seats[flightID] = 0
+	return nil
+}
@@ -0,0 +1,150 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryInventory_Reserve(t *testing.T) {
+	seats := NewMemoryInventory()
+	ctx := context.Background()
+
+	_, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Minute)
+	require.ErrorIs(t, err, ErrNotInitialized)
+
+	require.NoError(t, seats.Init(ctx, 1, 5))
+	// Init does not override a counter in use
+	require.NoError(t, seats.Init(ctx, 1, 100))
+
+	reservation, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 3}, time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, reservation)
+
+	_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 3}, time.Minute)
+	require.ErrorIs(t, err, ErrNoAvailableSeats)
+
+	available, err := seats.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, available)
+}
+
+func TestMemoryInventory_Release(t *testing.T) {
+	seats := NewMemoryInventory()
+	ctx := context.Background()
+	require.NoError(t, seats.Init(ctx, 1, 10))
+
+	released, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 3}, time.Minute)
+	require.NoError(t, err)
+	confirmed, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 2}, time.Minute)
+	require.NoError(t, err)
+	expired, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Nanosecond)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	require.NoError(t, seats.Confirm(ctx, 1, 1, confirmed))
+	require.NoError(t, seats.Release(ctx, 1, 1, released))
+	require.NoError(t, seats.Release(ctx, 1, 1, released))
+	require.NoError(t, seats.Release(ctx, 1, 1, confirmed))
+	require.NoError(t, seats.Release(ctx, 1, 1, expired))
+
+	available, err := seats.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 7, available)
+}
+
+func TestMemoryInventory_Concurrent(t *testing.T) {
+	seats := NewMemoryInventory()
+	ctx := context.Background()
+	require.NoError(t, seats.Init(ctx, 1, 50))
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int64
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reservation, err := seats.Reserve(ctx, 1, Hold{CustomerID: 1, Seats: 1}, time.Minute)
+			if err != nil {
+				require.ErrorIs(t, err, ErrNoAvailableSeats)
+				return
+			}
+			// Every other booking fails and gives its seat back
+			if i%2 == 0 {
+				require.NoError(t, seats.Release(ctx, 1, 1, reservation))
+				return
+			}
+			require.NoError(t, seats.Confirm(ctx, 1, 1, reservation))
+			reserved.Add(1)
+		}(i)
+	}
+	wg.Wait()
+
+	available, err := seats.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 50-int(reserved.Load()), available)
+}
+
+func TestMemoryInventory_Limits(t *testing.T) {
+	seats := NewMemoryInventory()
+	ctx := context.Background()
+	require.NoError(t, seats.Init(ctx, 1, 100))
+	require.NoError(t, seats.Init(ctx, 2, 100))
+	hold := Hold{CustomerID: 7, Seats: 2, MaxFlightSeats: 5, MaxPending: 2}
+
+	_, err := seats.Reserve(ctx, 1, hold, time.Minute)
+	require.ErrorIs(t, err, ErrCustomerNotInitialized)
+	require.NoError(t, seats.InitCustomer(ctx, 1, 7, 1))
+
+	first, err := seats.Reserve(ctx, 1, hold, time.Minute)
+	require.NoError(t, err)
+	// The seats booked before count towards the limit
+	_, err = seats.Reserve(ctx, 1, hold, time.Minute)
+	require.NoError(t, err)
+	_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 7, Seats: 1, MaxFlightSeats: 5}, time.Minute)
+	require.ErrorIs(t, err, ErrFlightSeatLimit)
+
+	// Other flights are not limited by this one, but the pending holds are
+	require.NoError(t, seats.InitCustomer(ctx, 2, 7, 0))
+	_, err = seats.Reserve(ctx, 2, hold, time.Minute)
+	require.ErrorIs(t, err, ErrPendingHoldLimit)
+
+	// A confirmed hold is no longer pending, its seats still count
+	require.NoError(t, seats.Confirm(ctx, 1, 7, first))
+	_, err = seats.Reserve(ctx, 2, hold, time.Minute)
+	require.NoError(t, err)
+	_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 7, Seats: 1, MaxFlightSeats: 5}, time.Minute)
+	require.ErrorIs(t, err, ErrFlightSeatLimit)
+
+	// Other customers have limits of their own
+	_, err = seats.Reserve(ctx, 1, Hold{CustomerID: 8, Seats: 2}, time.Minute)
+	require.NoError(t, err)
+}
+
+func TestMemoryInventory_LimitsConcurrent(t *testing.T) {
+	seats := NewMemoryInventory()
+	ctx := context.Background()
+	require.NoError(t, seats.Init(ctx, 1, 100))
+	require.NoError(t, seats.InitCustomer(ctx, 1, 7, 0))
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int64
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := seats.Reserve(ctx, 1, Hold{CustomerID: 7, Seats: 1, MaxFlightSeats: 4}, time.Minute)
+			if err != nil {
+				require.ErrorIs(t, err, ErrFlightSeatLimit)
+				return
+			}
+			reserved.Add(1)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(4), reserved.Load())
+}
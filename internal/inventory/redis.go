@@ -0,0 +1,185 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/cache"
+)
+
+// redisInventory implements SeatInventory on Redis counters shared by every
+// server, changes are published to the availability channel of the flight
+type redisInventory struct {
+	redisClient *cache.RedisClient
+}
+
+// NewRedisInventory creates a new instance of SeatInventory backed by Redis
+func NewRedisInventory(redisClient *cache.RedisClient) SeatInventory {
+	return &redisInventory{
+		redisClient: redisClient,
+	}
+}
+
+func (r *redisInventory) Init(ctx context.Context, flightID uint, availableSeats int) error {
+	flight := model.Flight{ID: flightID}
+	if err := r.redisClient.Client.SetNX(ctx, flight.FlightKey(), availableSeats, 24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to initialize Redis with available seats: %w", err)
+	}
+	return nil
+}
+
+func (r *redisInventory) InitCustomer(ctx context.Context, flightID, customerID uint, bookedSeats int) error {
+	if err := r.redisClient.Client.SetNX(ctx, customerKey(flightID, customerID), bookedSeats, 24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to initialize Redis with booked seats of customer: %w", err)
+	}
+	return nil
+}
+
+func (r *redisInventory) Get(ctx context.Context, flightID uint) (int, error) {
+	flight := model.Flight{ID: flightID}
+	seats, err := r.redisClient.Client.Get(ctx, flight.FlightKey()).Int()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrNotInitialized
+		}
+		return 0, fmt.Errorf("failed to get available seats from Redis: %w", err)
+	}
+	return seats, nil
+}
+
+func (r *redisInventory) Reserve(ctx context.Context, flightID uint, hold Hold, ttl time.Duration) (string, error) {
+	flight := model.Flight{ID: flightID}
+	reservation := uuid.New().String()
+
+	// The holds of a customer live in another cluster slot than the flight,
+	// so the hold is taken first and dropped again if the seats are not.
+	if hold.MaxPending > 0 {
+		held, err := r.redisClient.Client.Eval(ctx, constant.AcquireHoldScript,
+			[]string{holdsKey(hold.CustomerID)},
+			reservation, hold.MaxPending, ttl.Milliseconds(),
+		).Int()
+		if err != nil {
+			return "", fmt.Errorf("failed to execute Redis script: %w", err)
+		}
+		if held == 0 {
+			return "", ErrPendingHoldLimit
+		}
+	}
+
+	result, err := r.redisClient.Client.Eval(ctx, constant.CheckAndDecrementSeatsScript,
+		[]string{flight.FlightKey(), reservationKey(flightID, reservation), customerKey(flightID, hold.CustomerID)},
+		hold.Seats, int(ttl.Seconds()), hold.MaxFlightSeats,
+	).Result()
+	if err != nil {
+		r.dropHold(ctx, hold.CustomerID, reservation)
+		return "", fmt.Errorf("failed to execute Redis script: %w", err)
+	}
+
+	resultInt, ok := result.(int64)
+	if !ok {
+		r.dropHold(ctx, hold.CustomerID, reservation)
+		return "", fmt.Errorf("failed to parse Redis script result: not an integer")
+	}
+
+	switch resultInt {
+	case 1:
+		r.publish(ctx, flight)
+		return reservation, nil
+	case -1:
+		err = ErrNotInitialized
+	case -2:
+		err = ErrCustomerNotInitialized
+	case -3:
+		err = ErrFlightSeatLimit
+	case 0:
+		err = ErrNoAvailableSeats
+	default:
+		err = fmt.Errorf("invalid Redis script result: %d", resultInt)
+	}
+	r.dropHold(ctx, hold.CustomerID, reservation)
+	return "", err
+}
+
+func (r *redisInventory) Release(ctx context.Context, flightID, customerID uint, reservation string) error {
+	flight := model.Flight{ID: flightID}
+	released, err := r.redisClient.Client.Eval(ctx, constant.ReleaseSeatsScript,
+		[]string{flight.FlightKey(), reservationKey(flightID, reservation), customerKey(flightID, customerID)},
+	).Int()
+	if err != nil {
+		return fmt.Errorf("failed to release seats in Redis: %w", err)
+	}
+	r.dropHold(ctx, customerID, reservation)
+	if released == 1 {
+		r.publish(ctx, flight)
+	}
+	return nil
+}
+
+func (r *redisInventory) Confirm(ctx context.Context, flightID, customerID uint, reservation string) error {
+	if err := r.redisClient.Delete(ctx, reservationKey(flightID, reservation)); err != nil {
+		return err
+	}
+	r.dropHold(ctx, customerID, reservation)
+	return nil
+}
+
+func (r *redisInventory) Restock(ctx context.Context, flightID, customerID uint, seats int) error {
+	flight := model.Flight{ID: flightID}
+	restocked, err := r.redisClient.Client.Eval(ctx, constant.RestockSeatsScript,
+		[]string{flight.FlightKey(), customerKey(flightID, customerID)},
+		seats,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("failed to restock seats in Redis: %w", err)
+	}
+	if restocked == 1 {
+		r.publish(ctx, flight)
+	}
+	return nil
+}
+
+func (r *redisInventory) CloseFlight(ctx context.Context, flightID uint) error {
+	flight := model.Flight{ID: flightID}
+	if err := r.redisClient.Client.Set(ctx, flight.FlightKey(), 0, 24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to close seats in Redis: %w", err)
+	}
+	r.publish(ctx, flight)
+	return nil
+}
+
+// dropHold stops counting a reservation as a pending hold of its customer.
+// Failures are only logged, the hold expires with the reservation.
+func (r *redisInventory) dropHold(ctx context.Context, customerID uint, reservation string) {
+	if err := r.redisClient.Client.ZRem(ctx, holdsKey(customerID), reservation).Err(); err != nil {
+		log.Printf("failed to drop hold %s of customer %d: %v\n", reservation, customerID, err)
+	}
+}
+
+// publish publishes the seats left on a flight to subscribers of its
+// availability channel. Failures are only logged, subscribers catch up with
+// the next change.
+func (r *redisInventory) publish(ctx context.Context, flight model.Flight) {
+	if err := r.redisClient.Client.Eval(ctx, constant.PublishSeatsScript, []string{flight.FlightKey()}, flight.AvailabilityChannel()).Err(); err != nil {
+		log.Printf("failed to publish available seats of flight %d: %v\n", flight.ID, err)
+	}
+}
+
+func reservationKey(flightID uint, reservation string) string {
+	return fmt.Sprintf(constant.RESERVATION_KEY, flightID, reservation)
+}
+
+func customerKey(flightID, customerID uint) string {
+	return fmt.Sprintf(constant.FLIGHT_CUSTOMER_KEY, flightID, customerID)
+}
+
+func holdsKey(customerID uint) string {
+	return fmt.Sprintf(constant.CUSTOMER_HOLDS_KEY, customerID)
+}
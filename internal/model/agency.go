@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Agency represents a travel agency booking through the API
+type Agency struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	Name      string    `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// APIKey is a key an agency calls the API with. Only the SHA-256 hash of the
+// key is stored, it is shown once on creation.
+type APIKey struct {
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	AgencyID uint   `json:"agency_id" gorm:"type:uint;not null;index"`
+	Name     string `json:"name" gorm:"type:varchar(100);not null"`
+	// Prefix is the start of the key, to tell keys apart
+	Prefix  string `json:"prefix" gorm:"type:varchar(16);not null"`
+	KeyHash string `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	// Scopes are comma separated, search or booking
+	Scopes string `json:"scopes" gorm:"type:varchar(100);not null"`
+	// RateLimit is the requests allowed per minute, 0 for no limit
+	RateLimit int `json:"rate_limit" gorm:"type:int;not null;default:0"`
+	// DailyBookingQuota is the orders allowed per UTC day, 0 for no limit
+	DailyBookingQuota int        `json:"daily_booking_quota" gorm:"type:int;not null;default:0"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	Agency            *Agency    `json:"agency" gorm:"foreignKey:AgencyID"`
+}
+
+// ScopeList returns the scopes of the key
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// AgencyCustomer links an agency to a customer it may book for
+type AgencyCustomer struct {
+	AgencyID   uint      `json:"agency_id" gorm:"primaryKey;type:uint"`
+	CustomerID uint      `json:"customer_id" gorm:"primaryKey;type:uint;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
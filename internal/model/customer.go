@@ -4,11 +4,16 @@ import "time"
 
 // Customer represents a flight booking customer
 type Customer struct {
-	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
-	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
-	Email     string    `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
-	Phone     string    `json:"phone" gorm:"type:varchar(20);not null"`
-	Status    string    `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE'"` // ACTIVE, INACTIVE
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	Name         string `json:"name" gorm:"type:varchar(100);not null"`
+	Email        string `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Phone        string `json:"phone" gorm:"type:varchar(20);not null"`
+	Status       string `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE'"` // ACTIVE, INACTIVE
+	PasswordHash string `json:"-" gorm:"type:varchar(100)"`                               // empty for customers who have not signed up
+	Role         string `json:"role" gorm:"type:varchar(20);not null;default:'CUSTOMER'"` // CUSTOMER, ADMIN
+	// CalendarTokenHash is the SHA-256 hash of the token of the calendar feed
+	// of the customer, nil when there is no feed
+	CalendarTokenHash *string   `json:"-" gorm:"type:char(64);uniqueIndex"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
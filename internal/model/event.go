@@ -0,0 +1,74 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// OutboxEvent is a domain event written in the transaction of the change it
+// describes, and delivered to webhooks from there
+type OutboxEvent struct {
+	// ID is unique per event, receivers deduplicate deliveries with it
+	ID          string `json:"id" gorm:"type:char(36);primaryKey"`
+	Type        string `json:"type" gorm:"type:varchar(50);not null;index"`
+	AggregateID uint   `json:"aggregate_id" gorm:"type:uint;not null;index"`
+	// Payload is the JSON data of the event
+	Payload   string    `json:"payload" gorm:"type:json;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	// DispatchedAt is set once deliveries to the webhooks were queued
+	DispatchedAt *time.Time `json:"dispatched_at" gorm:"index"`
+}
+
+// Webhook is an endpoint subscribed to events
+type Webhook struct {
+	ID  uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	URL string `json:"url" gorm:"type:varchar(500);not null"`
+	// Secret signs the deliveries, it is shown once on creation
+	Secret string `json:"-" gorm:"type:varchar(100);not null"`
+	// EventTypes are comma separated, empty for every event
+	EventTypes string    `json:"event_types" gorm:"type:varchar(500);not null"`
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// EventTypeList returns the event types the webhook is subscribed to
+func (w Webhook) EventTypeList() []string {
+	if w.EventTypes == "" {
+		return nil
+	}
+	return strings.Split(w.EventTypes, ",")
+}
+
+// Subscribes reports whether the webhook receives events of a type
+func (w Webhook) Subscribes(eventType string) bool {
+	types := w.EventTypeList()
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the delivery of an event to a webhook
+type WebhookDelivery struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	EventID   string `json:"event_id" gorm:"type:char(36);not null;uniqueIndex:idx_webhook_deliveries_event_webhook"`
+	WebhookID uint   `json:"webhook_id" gorm:"type:uint;not null;uniqueIndex:idx_webhook_deliveries_event_webhook"`
+	Status    string `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"` // PENDING, DELIVERED, FAILED
+	Attempts  int    `json:"attempts" gorm:"type:int;not null;default:0"`
+	// NextAttemptAt is when a pending delivery is due, a worker sending it
+	// pushes it back while it is in flight
+	NextAttemptAt  time.Time    `json:"next_attempt_at" gorm:"index"`
+	LastStatusCode int          `json:"last_status_code" gorm:"type:int;not null;default:0"`
+	LastError      string       `json:"last_error" gorm:"type:varchar(500);not null;default:''"`
+	DeliveredAt    *time.Time   `json:"delivered_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Event          *OutboxEvent `json:"event" gorm:"foreignKey:EventID"`
+	Webhook        *Webhook     `json:"webhook" gorm:"foreignKey:WebhookID"`
+}
@@ -4,20 +4,35 @@ import (
 	"fmt"
 	"time"
 
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/catalog"
 	"github.com/joremysh/tonx/internal/constant"
 )
 
 // Flight represents a scheduled flight
 type Flight struct {
-	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
-	FlightNumber   string    `json:"flight_number" gorm:"uniqueIndex;type:varchar(20);not null"`
-	Airline        string    `json:"airline" gorm:"type:varchar(100);not null"`
-	DepartureCity  string    `json:"departure_city" gorm:"type:varchar(100);not null"`
-	ArrivalCity    string    `json:"arrival_city" gorm:"type:varchar(100);not null"`
-	DepartureTime  time.Time `json:"departure_time" gorm:"type:timestamp;not null;index"`
-	ArrivalTime    time.Time `json:"arrival_time" gorm:"type:timestamp;not null"`
-	Aircraft       string    `json:"aircraft" gorm:"type:varchar(50);not null"`
-	Status         string    `json:"status" gorm:"type:varchar(20);not null;default:'SCHEDULED'"` // SCHEDULED, DELAYED, CANCELLED, IN_PROGRESS, COMPLETED
+	ID uint `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	// ScheduleID is the schedule the flight was generated from, if any
+	ScheduleID    *uint  `json:"schedule_id" gorm:"index"`
+	FlightNumber  string `json:"flight_number" gorm:"uniqueIndex:idx_flights_flight_number_departure_date,priority:1;type:varchar(20);not null"`
+	Airline       string `json:"airline" gorm:"type:varchar(100);not null"`
+	DepartureCity string `json:"departure_city" gorm:"type:varchar(100);not null"`
+	ArrivalCity   string `json:"arrival_city" gorm:"type:varchar(100);not null"`
+	// DepartureAirport and ArrivalAirport are the IATA codes of the airports,
+	// as a city may have more than one. They are empty for places the
+	// catalog does not know.
+	DepartureAirport string    `json:"departure_airport" gorm:"type:char(3);not null;default:''"`
+	ArrivalAirport   string    `json:"arrival_airport" gorm:"type:char(3);not null;default:''"`
+	DepartureTime    time.Time `json:"departure_time" gorm:"type:timestamp;not null;index"`
+	// DepartureDate is the local date of departure at the departure airport,
+	// a flight number operates once per date
+	DepartureDate time.Time `json:"departure_date" gorm:"type:date;not null;uniqueIndex:idx_flights_flight_number_departure_date,priority:2"`
+	ArrivalTime   time.Time `json:"arrival_time" gorm:"type:timestamp;not null"`
+	Aircraft      string    `json:"aircraft" gorm:"type:varchar(50);not null"`
+	Status        string    `json:"status" gorm:"type:varchar(20);not null;default:'SCHEDULED'"` // SCHEDULED, DELAYED, CANCELLED, IN_PROGRESS, COMPLETED
+	// Revision counts the changes of the schedule and status of the flight
+	Revision       int       `json:"revision" gorm:"type:int;not null;default:0"`
 	TotalSeats     int       `json:"total_seats" gorm:"type:int;not null"`
 	AvailableSeats int       `json:"available_seats" gorm:"type:int;not null"`
 	BasePrice      int       `json:"base_price" gorm:"type:mediumint;not null"`
@@ -28,3 +43,51 @@ type Flight struct {
 func (f Flight) FlightKey() string {
 	return fmt.Sprintf(constant.FLIGHT_KEY, f.ID)
 }
+
+// AvailabilityChannel is the Redis pub/sub channel that seat count changes of
+// the flight are published on.
+func (f Flight) AvailabilityChannel() string {
+	return fmt.Sprintf(constant.FLIGHT_CHANNEL, f.ID)
+}
+
+// BeforeSave fills in the departure date of flights created without one, in
+// the time zone of the departure airport when it is known.
+func (f *Flight) BeforeSave(*gorm.DB) error {
+	if f.DepartureDate.IsZero() && !f.DepartureTime.IsZero() {
+		f.DepartureDate = f.LocalDepartureDate(f.DepartureTime)
+	}
+	return nil
+}
+
+// LocalDepartureDate returns the date of a departure of the flight at t, in
+// the time zone of its departure airport when it is known.
+func (f Flight) LocalDepartureDate(t time.Time) time.Time {
+	if airport, ok := f.Origin(); ok {
+		t = t.In(airport.Location())
+	}
+	return Date(t)
+}
+
+// Origin returns the departure airport of the flight, by its code, or by
+// its city for flights recorded without one
+func (f Flight) Origin() (catalog.Airport, bool) {
+	return flightAirport(f.DepartureAirport, f.DepartureCity)
+}
+
+// Destination returns the arrival airport of the flight, like Origin
+func (f Flight) Destination() (catalog.Airport, bool) {
+	return flightAirport(f.ArrivalAirport, f.ArrivalCity)
+}
+
+func flightAirport(code, city string) (catalog.Airport, bool) {
+	if code != "" {
+		return catalog.AirportByCode(code)
+	}
+	return catalog.AirportByCity(city)
+}
+
+// Date returns the calendar date of t as midnight UTC, which is how DATE
+// columns are written and read.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// FlightStatusHistory is a change of the status or times of a flight, with
+// the values before and after it
+type FlightStatusHistory struct {
+	ID                    uint      `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	FlightID              uint      `json:"flight_id" gorm:"type:uint;not null;index"`
+	PreviousStatus        string    `json:"previous_status" gorm:"type:varchar(20);not null"`
+	Status                string    `json:"status" gorm:"type:varchar(20);not null"`
+	PreviousDepartureTime time.Time `json:"previous_departure_time" gorm:"type:timestamp;not null"`
+	PreviousArrivalTime   time.Time `json:"previous_arrival_time" gorm:"type:timestamp;not null"`
+	DepartureTime         time.Time `json:"departure_time" gorm:"type:timestamp;not null"`
+	ArrivalTime           time.Time `json:"arrival_time" gorm:"type:timestamp;not null"`
+	// Reason is told to customers, such as "late arrival of the aircraft"
+	Reason string `json:"reason" gorm:"type:varchar(200);not null;default:''"`
+	// ChangedBy is the admin who made the change
+	ChangedBy *uint `json:"changed_by" gorm:"type:uint"`
+	// AffectedOrders is how many active orders were notified of the change
+	AffectedOrders int `json:"affected_orders" gorm:"type:int;not null;default:0"`
+	// EntitlementsGranted is how many of them were granted an entitlement
+	EntitlementsGranted int       `json:"entitlements_granted" gorm:"type:int;not null;default:0"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// OrderEntitlement is a right granted to the customer of an order, such as a
+// refund after their flight was delayed a lot or cancelled
+type OrderEntitlement struct {
+	ID      uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	OrderID uint   `json:"order_id" gorm:"type:uint;not null;uniqueIndex:idx_order_entitlements_order_id_kind,priority:1"`
+	Kind    string `json:"kind" gorm:"type:varchar(30);not null;uniqueIndex:idx_order_entitlements_order_id_kind,priority:2"` // REFUND
+	// FlightStatusHistoryID is the flight change the entitlement was granted
+	// for
+	FlightStatusHistoryID uint       `json:"flight_status_history_id" gorm:"type:uint;not null"`
+	Reason                string     `json:"reason" gorm:"type:varchar(200);not null;default:''"`
+	UsedAt                *time.Time `json:"used_at"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
@@ -1,9 +1,17 @@
 package model
 
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+)
+
 type ListParams struct {
 	Page      int
 	PageSize  int
 	SortBy    string
 	SortOrder string // "asc" or "desc"
 	Filters   map[string]string
+	Match     string // how filters are matched, MatchExact (default) or MatchPrefix
+	Cursor    string // opaque keyset cursor, takes precedence over Page when set
+	SkipCount bool   // skip the COUNT(*) query, TotalCount is left as zero
 }
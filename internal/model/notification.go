@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Notification is an email to a customer, written in the transaction of the
+// change it tells about and sent from there
+type Notification struct {
+	ID      uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	Kind    string `json:"kind" gorm:"type:varchar(30);not null"` // ORDER_CONFIRMED, ORDER_CANCELLED, FLIGHT_CHANGED
+	OrderID uint   `json:"order_id" gorm:"type:uint;not null;index"`
+	// Payload is the JSON data the message needs besides the order, such as
+	// the previous times of a changed flight
+	Payload  string `json:"payload" gorm:"type:json;not null"`
+	Status   string `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"` // PENDING, SENT, FAILED
+	Attempts int    `json:"attempts" gorm:"type:int;not null;default:0"`
+	// NextAttemptAt is when a pending notification is due, a worker sending
+	// it pushes it back while it is in flight
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error" gorm:"type:varchar(500);not null;default:''"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Order         *Order     `json:"order" gorm:"foreignKey:OrderID"`
+}
@@ -4,15 +4,20 @@ import "time"
 
 // Order represents a flight booking order
 type Order struct {
-	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
-	FlightID    uint      `json:"flight_id" gorm:"type:uint;not null;index"`
-	CustomerID  uint      `json:"customer_id" gorm:"type:uint;not null;index"`
-	Status      string    `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"` // PENDING, CONFIRMED, CANCELLED, COMPLETED
-	TotalAmount int       `json:"total_amount" gorm:"type:mediumint;not null"`               // In smallest currency unit (e.g., cents)
-	OrderNumber string    `json:"order_number" gorm:"type:varchar(50);uniqueIndex;not null"`
-	BookingTime time.Time `json:"booking_time" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	CreatedAt   time.Time `json:"created_at" gorm:"type:timestamp;autoCreateTime"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"type:timestamp;autoUpdateTime"`
-	Flight      *Flight   `json:"flight" gorm:"foreignKey:FlightID"`
-	Customer    *Customer `json:"customer" gorm:"foreignKey:CustomerID"`
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	FlightID     uint       `json:"flight_id" gorm:"type:uint;not null;index"`
+	CustomerID   uint       `json:"customer_id" gorm:"type:uint;not null;index"`
+	AgencyID     *uint      `json:"agency_id" gorm:"type:uint;index"`                          // set for orders booked by an agency's API key
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"` // PENDING, CONFIRMED, CANCELLED, COMPLETED
+	TotalAmount  int        `json:"total_amount" gorm:"type:mediumint;not null"`               // In smallest currency unit (e.g., cents)
+	TicketAmount int        `json:"ticket_amount" gorm:"type:int;not null;default:0"`
+	OrderNumber  string     `json:"order_number" gorm:"type:varchar(50);uniqueIndex;not null"`
+	BookingTime  time.Time  `json:"booking_time" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"type:timestamp;autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"type:timestamp;autoUpdateTime"`
+	Flight       *Flight    `json:"flight" gorm:"foreignKey:FlightID"`
+	Customer     *Customer  `json:"customer" gorm:"foreignKey:CustomerID"`
+	Travelers    []Traveler `json:"travelers" gorm:"foreignKey:OrderID"`
+	// Entitlements are granted when the flight is delayed a lot or cancelled
+	Entitlements []OrderEntitlement `json:"entitlements" gorm:"foreignKey:OrderID"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Schedule is a flight operating on some days of the week, dated flights are
+// generated from it ahead of time
+type Schedule struct {
+	ID               uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	FlightNumber     string `json:"flight_number" gorm:"type:varchar(20);not null;index"`
+	Airline          string `json:"airline" gorm:"type:varchar(100);not null"`
+	DepartureAirport string `json:"departure_airport" gorm:"type:char(3);not null"`
+	ArrivalAirport   string `json:"arrival_airport" gorm:"type:char(3);not null"`
+	// DaysOfWeek are the digits of the days of operation, 1 for Monday
+	DaysOfWeek string `json:"days_of_week" gorm:"type:varchar(7);not null"`
+	// DepartureTime and ArrivalTime are HH:MM in the local time of the airports
+	DepartureTime    string    `json:"departure_time" gorm:"type:char(5);not null"`
+	ArrivalTime      string    `json:"arrival_time" gorm:"type:char(5);not null"`
+	ArrivalDayOffset int       `json:"arrival_day_offset" gorm:"type:tinyint;not null;default:0"`
+	EffectiveFrom    time.Time `json:"effective_from" gorm:"type:date;not null"`
+	// EffectiveTo is nil for schedules operating until further notice
+	EffectiveTo *time.Time `json:"effective_to" gorm:"type:date"`
+	Aircraft    string     `json:"aircraft" gorm:"type:varchar(50);not null"`
+	TotalSeats  int        `json:"total_seats" gorm:"type:int;not null"`
+	BasePrice   int        `json:"base_price" gorm:"type:mediumint;not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
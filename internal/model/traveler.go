@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Traveler is a passenger of an order, one per ticket
+type Traveler struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement;type:uint"`
+	OrderID   uint   `json:"order_id" gorm:"type:uint;not null;index"`
+	FirstName string `json:"first_name" gorm:"type:varchar(50);not null"`
+	LastName  string `json:"last_name" gorm:"type:varchar(50);not null"`
+	// Seat is assigned at check-in, like "12A"
+	Seat        string     `json:"seat" gorm:"type:varchar(4);not null;default:'';uniqueIndex:idx_travelers_seat_flight_id_seat,priority:2"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	// BoardingSequence is the check-in sequence number of the traveler on
+	// the flight, printed on the boarding pass
+	BoardingSequence int `json:"boarding_sequence" gorm:"type:int;not null;default:0"`
+	// SeatFlightID is the flight the seat is taken on, unique with the seat.
+	// It is cleared when the order is cancelled, giving the seat back.
+	SeatFlightID *uint     `json:"-" gorm:"type:uint;uniqueIndex:idx_travelers_seat_flight_id_seat,priority:1"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FullName returns the name of the traveler as printed on documents
+func (t Traveler) FullName() string {
+	return t.FirstName + " " + t.LastName
+}
@@ -0,0 +1,218 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPConfig holds the settings of the SMTP server emails are sent through
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth, which net/smtp
+	// only allows over TLS or to localhost. Empty for servers without auth.
+	Username string
+	Password string
+	// From is the sender of the emails, like "Tonx <no-reply@tonx.example>"
+	From string
+	// StartTLS upgrades the connection before authenticating
+	StartTLS bool
+	// Timeout bounds the whole conversation with the server
+	Timeout time.Duration
+}
+
+// smtpMailer implements Mailer
+type smtpMailer struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewSMTPMailer creates a new instance of Mailer sending through an SMTP
+// server
+func NewSMTPMailer(cfg SMTPConfig) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", cfg.From, err)
+	}
+	return &smtpMailer{cfg: cfg, from: from}, nil
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	body, err := msg.Bytes(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if m.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.cfg.Timeout)
+		defer cancel()
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if m.cfg.StartTLS {
+		if err = client.StartTLS(&tls.Config{ServerName: m.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if m.cfg.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err = client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("smtp server refused sender: %w", err)
+	}
+	if err = client.Rcpt(msg.To.Address); err != nil {
+		return fmt.Errorf("smtp server refused recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err = w.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("smtp server refused message: %w", err)
+	}
+	return client.Quit()
+}
+
+// Bytes encodes the message as a MIME email from a sender, with the text and
+// HTML bodies as alternatives, followed by the attachments if there are any.
+func (msg *Message) Bytes(from *mail.Address, date time.Time) ([]byte, error) {
+	contentType, body, err := msg.alternatives()
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Attachments) > 0 {
+		if contentType, body, err = msg.mixed(contentType, body); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	header("To", msg.To.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	if msg.ID != "" {
+		header("Message-ID", msg.ID)
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", contentType)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// alternatives encodes the text and HTML bodies as a multipart/alternative
+// entity. It returns its content type and body.
+func (msg *Message) alternatives() (string, []byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, alternative := range []struct {
+		contentType string
+		content     string
+	}{
+		// Clients show the last alternative they support
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alternative.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err = qp.Write([]byte(alternative.content)); err != nil {
+			return "", nil, err
+		}
+		if err = qp.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return "", nil, err
+	}
+	return mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()}), body.Bytes(), nil
+}
+
+// mixed encodes the message entity of contentType followed by the
+// attachments as a multipart/mixed entity. It returns its content type and
+// body.
+func (msg *Message) mixed(contentType string, content []byte) (string, []byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	part, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err = part.Write(content); err != nil {
+		return "", nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		part, err = parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		// Lines of base64 are at most 76 characters long
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			if _, err = io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+				return "", nil, err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err = io.WriteString(part, encoded+"\r\n"); err != nil {
+			return "", nil, err
+		}
+	}
+	if err = parts.Close(); err != nil {
+		return "", nil, err
+	}
+	return mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": parts.Boundary()}), body.Bytes(), nil
+}
@@ -0,0 +1,220 @@
+// Package notify renders the emails sent to customers about their bookings
+// and sends them over SMTP.
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/mail"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/joremysh/tonx/internal/catalog"
+	"github.com/joremysh/tonx/internal/model"
+)
+
+var ErrUnknownKind = errors.New("unknown notification kind")
+
+// Kinds of notifications
+const (
+	KindOrderConfirmed = "ORDER_CONFIRMED"
+	KindOrderCancelled = "ORDER_CANCELLED"
+	KindFlightChanged  = "FLIGHT_CHANGED"
+)
+
+// Kinds lists every kind of notification, each has a text and an HTML
+// template named after it
+var Kinds = []string{KindOrderConfirmed, KindOrderCancelled, KindFlightChanged}
+
+//go:embed templates/*
+var templateFS embed.FS
+
+type kindTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = mustParseTemplates()
+
+// Itinerary is the data the templates are rendered with
+type Itinerary struct {
+	CustomerName string
+	OrderNumber  string
+	Status       string
+	BookedAt     time.Time
+	TicketAmount int
+	// Total is the formatted total amount of the order
+	Total     string
+	Flight    Segment
+	Travelers []string
+	// Change is set for flight change notices
+	Change *FlightChange
+}
+
+// Segment is a flight of an itinerary, its times are local to the airports
+type Segment struct {
+	FlightNumber string
+	Airline      string
+	Aircraft     string
+	Status       string
+	From         Place
+	To           Place
+	Departure    time.Time
+	Arrival      time.Time
+}
+
+// Place is the city and airport a segment departs from or arrives at
+type Place struct {
+	City string
+	// Airport is the IATA code of the airport, empty when it is not in the
+	// catalog
+	Airport  string
+	Location *time.Location
+}
+
+// FlightChange holds the times of a flight before it was changed
+type FlightChange struct {
+	PreviousStatus    string    `json:"previous_status"`
+	PreviousDeparture time.Time `json:"previous_departure"`
+	PreviousArrival   time.Time `json:"previous_arrival"`
+	Reason            string    `json:"reason,omitempty"`
+	// Entitled is set when the change entitles the customer to a refund of
+	// the booking
+	Entitled bool `json:"entitled,omitempty"`
+}
+
+// Message is a rendered email
+type Message struct {
+	// ID is the Message-ID, it stays the same when sending is retried so
+	// receivers can drop duplicates
+	ID          string
+	To          mail.Address
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// NewItinerary returns the itinerary of an order, which must be loaded with
+// its flight, customer and travelers.
+func NewItinerary(order *model.Order) Itinerary {
+	itinerary := Itinerary{
+		OrderNumber:  order.OrderNumber,
+		Status:       order.Status,
+		BookedAt:     order.BookingTime,
+		TicketAmount: order.TicketAmount,
+		Total:        FormatAmount(order.TotalAmount),
+	}
+	if order.Customer != nil {
+		itinerary.CustomerName = order.Customer.Name
+	}
+	if flight := order.Flight; flight != nil {
+		itinerary.Flight = NewSegment(flight)
+	}
+	for _, traveler := range order.Travelers {
+		itinerary.Travelers = append(itinerary.Travelers, traveler.FullName())
+	}
+	return itinerary
+}
+
+// NewSegment returns the segment of a flight, with its times in the time
+// zones of its airports.
+func NewSegment(flight *model.Flight) Segment {
+	origin, ok := flight.Origin()
+	from := newPlace(flight.DepartureCity, origin, ok)
+	destination, ok := flight.Destination()
+	to := newPlace(flight.ArrivalCity, destination, ok)
+	return Segment{
+		FlightNumber: flight.FlightNumber,
+		Airline:      flight.Airline,
+		Aircraft:     flight.Aircraft,
+		Status:       flight.Status,
+		From:         from,
+		To:           to,
+		Departure:    flight.DepartureTime.In(from.Location),
+		Arrival:      flight.ArrivalTime.In(to.Location),
+	}
+}
+
+func newPlace(city string, airport catalog.Airport, ok bool) Place {
+	if ok {
+		return Place{City: city, Airport: airport.Code, Location: airport.Location()}
+	}
+	return Place{City: city, Location: time.UTC}
+}
+
+// String returns the city and airport code of the place, like "Taipei (TPE)"
+func (p Place) String() string {
+	if p.Airport == "" {
+		return p.City
+	}
+	return fmt.Sprintf("%s (%s)", p.City, p.Airport)
+}
+
+// FormatAmount formats an amount in the smallest currency unit
+func FormatAmount(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// Render renders the email of a kind of notification. The recipient and ID
+// of the message are left to the caller.
+func Render(kind string, itinerary Itinerary) (*Message, error) {
+	t, ok := templates[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", itinerary); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s: %w", kind, err)
+	}
+	if err := t.text.Execute(&text, itinerary); err != nil {
+		return nil, fmt.Errorf("failed to render text of %s: %w", kind, err)
+	}
+	if err := t.html.Execute(&html, itinerary); err != nil {
+		return nil, fmt.Errorf("failed to render html of %s: %w", kind, err)
+	}
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+var templateFuncs = map[string]any{
+	"datetime": func(t time.Time) string {
+		return t.Format("Mon, 02 Jan 2006 15:04 MST")
+	},
+}
+
+// mustParseTemplates parses the templates of every kind. The templates of a
+// kind are parsed together with the shared ones, the itinerary and the HTML
+// layout.
+func mustParseTemplates() map[string]kindTemplates {
+	parsed := make(map[string]kindTemplates, len(Kinds))
+	for _, kind := range Kinds {
+		name := strings.ToLower(kind)
+		parsed[kind] = kindTemplates{
+			text: texttemplate.Must(texttemplate.New(name+".txt").Funcs(templateFuncs).
+				ParseFS(templateFS, "templates/"+name+".txt", "templates/itinerary.txt")),
+			html: htmltemplate.Must(htmltemplate.New("layout.html").Funcs(templateFuncs).
+				ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")),
+		}
+	}
+	return parsed
+}
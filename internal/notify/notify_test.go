@@ -0,0 +1,284 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+func testOrder() *model.Order {
+	departure := time.Date(2025, 3, 1, 1, 30, 0, 0, time.UTC)
+	return &model.Order{
+		OrderNumber:  "ORD-20250201-abcd1234",
+		Status:       "COMPLETED",
+		TicketAmount: 2,
+		TotalAmount:  1234567,
+		Flight: &model.Flight{
+			FlightNumber:  "BR198",
+			Airline:       "EVA Air",
+			DepartureCity: "Taipei",
+			ArrivalCity:   "Tokyo",
+			DepartureTime: departure,
+			ArrivalTime:   departure.Add(3 * time.Hour),
+			Aircraft:      "Boeing 787-10",
+			Status:        "SCHEDULED",
+		},
+		Customer: &model.Customer{Name: "Mei Lin", Email: "mei@example.com"},
+		Travelers: []model.Traveler{
+			{FirstName: "Mei", LastName: "Lin"},
+			{FirstName: "Wei", LastName: "Chen <Jr>"},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	itinerary := NewItinerary(testOrder())
+	require.Equal(t, "12345.67", itinerary.Total)
+	require.Equal(t, "Taipei (TPE)", itinerary.Flight.From.String())
+	// Times are shown in the time zones of the airports
+	require.Equal(t, 9, itinerary.Flight.Departure.Hour())
+	require.Equal(t, 13, itinerary.Flight.Arrival.Hour())
+
+	for _, kind := range Kinds {
+		msg, err := Render(kind, itinerary)
+		require.NoError(t, err, kind)
+		require.Contains(t, msg.Subject, "BR198")
+		require.NotContains(t, msg.Subject, "\n")
+		for _, body := range []string{msg.Text, msg.HTML} {
+			require.Contains(t, body, "Dear Mei Lin")
+			require.Contains(t, body, itinerary.OrderNumber)
+			require.Contains(t, body, "Taipei (TPE)")
+			require.Contains(t, body, "Sat, 01 Mar 2025 09:30")
+			require.Contains(t, body, "12345.67")
+		}
+		require.Contains(t, msg.Text, "  - Wei Chen <Jr>")
+		require.Contains(t, msg.HTML, "Wei Chen &lt;Jr&gt;")
+	}
+
+	itinerary.Flight.Status = "DELAYED"
+	itinerary.Change = &FlightChange{
+		PreviousStatus:    "SCHEDULED",
+		PreviousDeparture: time.Date(2025, 3, 1, 0, 30, 0, 0, time.UTC),
+		PreviousArrival:   time.Date(2025, 3, 1, 3, 30, 0, 0, time.UTC),
+	}
+	msg, err := Render(KindFlightChanged, itinerary)
+	require.NoError(t, err)
+	require.Equal(t, "Flight BR198 is DELAYED: update to booking ORD-20250201-abcd1234", msg.Subject)
+	require.Contains(t, msg.Text, "changed from SCHEDULED to DELAYED.")
+	require.Contains(t, msg.Text, "Sat, 01 Mar 2025 08:30")
+	require.Contains(t, msg.HTML, "Sat, 01 Mar 2025 08:30")
+	require.NotContains(t, msg.Text, "Reason:")
+	require.NotContains(t, msg.Text, "full refund")
+
+	itinerary.Change.Reason = "Typhoon <Kong-rey>"
+	itinerary.Change.Entitled = true
+	msg, err = Render(KindFlightChanged, itinerary)
+	require.NoError(t, err)
+	require.Contains(t, msg.Text, "DELAYED.\nReason: Typhoon <Kong-rey>\n")
+	require.Contains(t, msg.Text, "cancel your booking for a full refund, even\n")
+	require.Contains(t, msg.HTML, "Reason: Typhoon &lt;Kong-rey&gt;")
+	require.Contains(t, msg.HTML, "cancel your booking for a full refund")
+
+	_, err = Render("ORDER_SHIPPED", itinerary)
+	require.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestMessage_Bytes(t *testing.T) {
+	msg, err := Render(KindOrderConfirmed, NewItinerary(testOrder()))
+	require.NoError(t, err)
+	msg.ID = "<notification-1@tonx>"
+	msg.To = mail.Address{Name: "Mei Lin", Address: "mei@example.com"}
+	msg.Subject = "Booking confirmed: 台北"
+
+	raw, err := msg.Bytes(&mail.Address{Name: "Tonx", Address: "no-reply@tonx.example"}, time.Now())
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, msg.Subject, subject)
+	require.Equal(t, "<notification-1@tonx>", parsed.Header.Get("Message-ID"))
+	to, err := parsed.Header.AddressList("To")
+	require.NoError(t, err)
+	require.Equal(t, "mei@example.com", to[0].Address)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		part, err := parts.NextRawPart()
+		require.NoError(t, err)
+		require.Equal(t, want.contentType, part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+		// Line breaks are sent as CRLF
+		require.Equal(t, want.body, strings.ReplaceAll(string(body), "\r\n", "\n"))
+	}
+	_, err = parts.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestMessage_BytesWithAttachment(t *testing.T) {
+	msg, err := Render(KindOrderConfirmed, NewItinerary(testOrder()))
+	require.NoError(t, err)
+	msg.To = mail.Address{Address: "mei@example.com"}
+	pdf, err := ItineraryPDF(NewItinerary(testOrder()))
+	require.NoError(t, err)
+	msg.Attachments = []Attachment{{Filename: ItineraryFilename("ORD-1"), ContentType: ItineraryContentType, Data: pdf}}
+
+	raw, err := msg.Bytes(&mail.Address{Address: "no-reply@tonx.example"}, time.Now())
+	require.NoError(t, err)
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	part, err := parts.NextRawPart()
+	require.NoError(t, err)
+	mediaType, _, err = mime.ParseMediaType(part.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	part, err = parts.NextRawPart()
+	require.NoError(t, err)
+	require.Equal(t, ItineraryContentType, part.Header.Get("Content-Type"))
+	require.Equal(t, "itinerary-ORD-1.pdf", part.FileName())
+	encoded, err := io.ReadAll(part)
+	require.NoError(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		require.LessOrEqual(t, len(line), 76)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	require.NoError(t, err)
+	require.Equal(t, pdf, data)
+}
+
+func TestItineraryPDF(t *testing.T) {
+	order := testOrder()
+	pdf, err := ItineraryPDF(NewItinerary(order))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+	require.Contains(t, string(pdf), "Itinerary ORD-20250201-abcd1234")
+	// Text is set in an embedded Unicode font rather than a core font
+	require.Contains(t, string(pdf), "/FontName /utf8dejavu")
+	require.NotContains(t, string(pdf), "/Helvetica")
+
+	// Orders whose travelers are not named yet still render
+	order.Travelers = nil
+	order.Customer.Name = "林美"
+	pdf, err = ItineraryPDF(NewItinerary(order))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+
+	code, err := qrPNG(order.OrderNumber, 64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(code))
+	require.NoError(t, err)
+	require.Equal(t, 64, img.Bounds().Dx())
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// A minimal SMTP server that records the envelope and message
+	type received struct {
+		from, to string
+		data     string
+	}
+	done := make(chan received, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var got received
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "MAIL FROM:"):
+				got.from = line
+				reply("250 OK")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				got.to = line
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				got.data = data.String()
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 bye")
+				done <- got
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	_, err = NewSMTPMailer(SMTPConfig{From: "not an address"})
+	require.Error(t, err)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	mailer, err := NewSMTPMailer(SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    port,
+		From:    "Tonx <no-reply@tonx.example>",
+		Timeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	msg, err := Render(KindOrderCancelled, NewItinerary(testOrder()))
+	require.NoError(t, err)
+	msg.To = mail.Address{Name: "Mei Lin", Address: "mei@example.com"}
+	require.NoError(t, mailer.Send(context.Background(), msg))
+
+	got := <-done
+	require.Equal(t, "MAIL FROM:<no-reply@tonx.example>", got.from)
+	require.Equal(t, "RCPT TO:<mei@example.com>", got.to)
+	require.Contains(t, got.data, "Subject: Booking cancelled: BR198")
+}
@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strconv"
+
+	"codeberg.org/go-pdf/fpdf"
+	"github.com/boombuler/barcode/qr"
+
+	"github.com/joremysh/tonx/internal/boarding"
+)
+
+// The DejaVu fonts print names and places in Latin, Greek and Cyrillic
+// letters, unlike the core PDF fonts, which only cover Latin-1
+var (
+	//go:embed fonts/DejaVuSansCondensed.ttf
+	fontRegular []byte
+	//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+	fontBold []byte
+	//go:embed fonts/DejaVuSansCondensed-Oblique.ttf
+	fontItalic []byte
+)
+
+const fontFamily = "DejaVu"
+
+// ItineraryContentType is the media type of rendered itineraries
+const ItineraryContentType = "application/pdf"
+
+// ItineraryFilename returns the file name an itinerary is served and attached
+// as
+func ItineraryFilename(orderNumber string) string {
+	return "itinerary-" + orderNumber + ".pdf"
+}
+
+// ItineraryPDF renders a printable itinerary of an order, with a QR code of
+// the order number for the airport staff to scan.
+func ItineraryPDF(itinerary Itinerary) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", fontRegular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", fontBold)
+	pdf.AddUTF8FontFromBytes(fontFamily, "I", fontItalic)
+	pdf.SetTitle("Itinerary "+itinerary.OrderNumber, false)
+	pdf.SetCreator("tonx", false)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	code, err := qrPNG(itinerary.OrderNumber, 256)
+	if err != nil {
+		return nil, err
+	}
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(code))
+	pdf.ImageOptions("qr", 160, 10, 40, 40, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	pdf.SetFont(fontFamily, "B", 20)
+	pdf.CellFormat(140, 12, "Itinerary", "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 11)
+	for _, field := range [][2]string{
+		{"Order number", itinerary.OrderNumber},
+		{"Status", itinerary.Status},
+		{"Booked", itinerary.BookedAt.UTC().Format("02 Jan 2006 15:04 UTC")},
+		{"Passenger contact", itinerary.CustomerName},
+	} {
+		pdf.SetFont(fontFamily, "B", 11)
+		pdf.CellFormat(40, 7, field[0], "", 0, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.CellFormat(100, 7, field[1], "", 1, "L", false, 0, "")
+	}
+	pdf.SetY(58)
+
+	heading := func(title string) {
+		pdf.Ln(4)
+		pdf.SetFont(fontFamily, "B", 13)
+		pdf.CellFormat(0, 8, title, "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+
+	heading("Flight")
+	widths := []float64{20, 32, 38, 32, 38, 30}
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for i, title := range []string{"Flight", "From", "Departure", "To", "Arrival", "Aircraft"} {
+		pdf.CellFormat(widths[i], 7, title, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(fontFamily, "", 9)
+	segment := itinerary.Flight
+	for i, value := range []string{
+		segment.FlightNumber,
+		segment.From.String(),
+		segment.Departure.Format("02 Jan 2006 15:04 MST"),
+		segment.To.String(),
+		segment.Arrival.Format("02 Jan 2006 15:04 MST"),
+		segment.Aircraft,
+	} {
+		pdf.CellFormat(widths[i], 7, value, "1", 0, "L", false, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(0, 7, fmt.Sprintf("Operated by %s. Times are local to each airport.", segment.Airline), "", 1, "L", false, 0, "")
+
+	heading("Travelers")
+	pdf.SetFont(fontFamily, "", 11)
+	if len(itinerary.Travelers) == 0 {
+		pdf.CellFormat(0, 7, fmt.Sprintf("%d ticket(s), travelers not named yet", itinerary.TicketAmount), "", 1, "L", false, 0, "")
+	}
+	for i, name := range itinerary.Travelers {
+		pdf.CellFormat(10, 7, strconv.Itoa(i+1)+".", "", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 7, name, "", 1, "L", false, 0, "")
+	}
+
+	heading("Payment")
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.CellFormat(40, 7, "Tickets", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 7, strconv.Itoa(itinerary.TicketAmount), "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.CellFormat(40, 7, "Total", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 7, itinerary.Total, "", 1, "L", false, 0, "")
+
+	pdf.Ln(8)
+	pdf.SetFont(fontFamily, "I", 9)
+	pdf.MultiCell(0, 5, "Please present this itinerary and a valid travel document at check-in. "+
+		"The QR code holds the order number.", "", "L", false)
+
+	var buf bytes.Buffer
+	if err = pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render itinerary: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// qrPNG encodes content as a QR code PNG of size pixels square
+func qrPNG(content string, size int) ([]byte, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	return boarding.BarcodePNG(code, size, size)
+}
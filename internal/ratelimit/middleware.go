@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+// Rule limits the requests to the routes under a path, for one method or all
+// of them if Method is empty. Each rule has its own buckets.
+type Rule struct {
+	Name   string
+	Method string
+	Path   string
+	Limit  Limit
+}
+
+// Config holds the limits of the middleware. Requests matching none of the
+// rules take from the default bucket.
+type Config struct {
+	Enabled bool
+	Default Limit
+	Rules   []Rule
+	// Address limits all requests of an IP address before they are
+	// authenticated, so requests refused for bad credentials count too.
+	// Zero requests do not limit them.
+	Address Limit
+}
+
+// rule returns the first rule matching a request.
+func (cfg Config) rule(method, path string) Rule {
+	for _, rule := range cfg.Rules {
+		if rule.Method != "" && rule.Method != method {
+			continue
+		}
+		if path == rule.Path || strings.HasPrefix(path, strings.TrimSuffix(rule.Path, "/")+"/") {
+			return rule
+		}
+	}
+	return Rule{Name: "default", Limit: cfg.Default}
+}
+
+// Middleware limits the requests of each client, by API key, signed in
+// customer or IP address in that order. It has to run after auth.Middleware
+// to tell clients by their principal. Responses carry the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, requests over the limit
+// are answered with 429 and Retry-After.
+//
+// Requests are let through while Redis fails, a rate limiter outage should
+// not take bookings down with it.
+func Middleware(limiter Limiter, cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		rule := cfg.rule(c.Request.Method, c.Request.URL.Path)
+		key := fmt.Sprintf(constant.RATE_LIMIT_KEY, rule.Name, client(c))
+		if take(c, limiter, key, rule.Limit) {
+			c.Next()
+		}
+	}
+}
+
+// AddressMiddleware limits the requests of each IP address to cfg.Address.
+// It runs ahead of auth.Middleware, which refuses bad tokens and API keys
+// before Middleware can count them, so guessing them is limited too.
+func AddressMiddleware(limiter Limiter, cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled || cfg.Address.Requests <= 0 {
+			c.Next()
+			return
+		}
+
+		key := fmt.Sprintf(constant.RATE_LIMIT_KEY, "address", "ip:"+c.ClientIP())
+		if take(c, limiter, key, cfg.Address) {
+			c.Next()
+		}
+	}
+}
+
+// take takes a request from the bucket of key and sets the rate limit
+// headers. Requests over the limit are aborted with 429, and take reports
+// whether the request may go on.
+func take(c *gin.Context, limiter Limiter, key string, limit Limit) bool {
+	result, err := limiter.Allow(c.Request.Context(), key, limit)
+	if err != nil {
+		log.Printf("failed to rate limit %s: %v\n", key, err)
+		metrics.Inc("rate_limit_errors")
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+	if !result.Allowed {
+		metrics.Inc("rate_limited")
+		c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, api.Error{
+			Code:    http.StatusTooManyRequests,
+			Message: fmt.Sprintf("rate limit of %s exceeded", limit),
+		})
+		return false
+	}
+	return true
+}
+
+// client returns the key telling a client's requests apart from others.
+func client(c *gin.Context) string {
+	principal, ok := auth.PrincipalFrom(c.Request.Context())
+	switch {
+	case ok && principal.IsAgency():
+		return fmt.Sprintf("key:%d", principal.APIKeyID)
+	case ok:
+		return fmt.Sprintf("customer:%d", principal.CustomerID)
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// seconds rounds a duration up to whole seconds, as the headers expect.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
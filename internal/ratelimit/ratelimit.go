@@ -0,0 +1,101 @@
+// Package ratelimit limits the requests of each client with token buckets
+// kept in Redis, so all replicas share the limits.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/pkg/cache"
+)
+
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit allows Requests per Per to a client, in bursts of up to Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses a limit like "100/m", with s, m or h for the period.
+func ParseLimit(s string) (Limit, error) {
+	requests, unit, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("%w: %q, expected requests/period", ErrInvalidLimit, s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q, requests must be a positive number", ErrInvalidLimit, s)
+	}
+
+	limit := Limit{Requests: n}
+	switch unit {
+	case "s":
+		limit.Per = time.Second
+	case "m":
+		limit.Per = time.Minute
+	case "h":
+		limit.Per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("%w: %q, period must be s, m or h", ErrInvalidLimit, s)
+	}
+	return limit, nil
+}
+
+func (l Limit) String() string {
+	switch l.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", l.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", l.Requests)
+	default:
+		return fmt.Sprintf("%d/m", l.Requests)
+	}
+}
+
+// Result is the state of a client's bucket after taking a request from it.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is the time until the next request is allowed.
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// Limiter defines the interface for taking requests from client buckets
+type Limiter interface {
+	// Allow takes a request from the bucket of key, which holds up to
+	// limit.Requests tokens.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type redisLimiter struct {
+	redisClient *cache.RedisClient
+}
+
+func NewRedisLimiter(redisClient *cache.RedisClient) Limiter {
+	return &redisLimiter{redisClient: redisClient}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	rate := float64(limit.Requests) / limit.Per.Seconds()
+	values, err := l.redisClient.Client.Eval(ctx, constant.TokenBucketScript, []string{key}, rate, limit.Requests).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take from token bucket: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected token bucket result %v", values)
+	}
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
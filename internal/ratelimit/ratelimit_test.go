@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/auth"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("100/m")
+	require.NoError(t, err)
+	require.Equal(t, Limit{Requests: 100, Per: time.Minute}, limit)
+	require.Equal(t, "100/m", limit.String())
+
+	limit, err = ParseLimit(" 5/s ")
+	require.NoError(t, err)
+	require.Equal(t, Limit{Requests: 5, Per: time.Second}, limit)
+
+	for _, s := range []string{"", "100", "0/m", "-1/m", "x/m", "100/d"} {
+		_, err = ParseLimit(s)
+		require.ErrorIs(t, err, ErrInvalidLimit, s)
+	}
+}
+
+// stubLimiter counts the requests of each bucket in memory.
+type stubLimiter struct {
+	used map[string]int
+	err  error
+}
+
+func (l *stubLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if l.err != nil {
+		return Result{}, l.err
+	}
+	if l.used[key] >= limit.Requests {
+		return Result{Remaining: 0, RetryAfter: 1500 * time.Millisecond, Reset: limit.Per}, nil
+	}
+	l.used[key]++
+	return Result{Allowed: true, Remaining: limit.Requests - l.used[key], Reset: limit.Per}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := &stubLimiter{used: make(map[string]int)}
+	cfg := Config{
+		Enabled: true,
+		Default: Limit{Requests: 3, Per: time.Minute},
+		Rules: []Rule{{
+			Name:   "orders",
+			Method: http.MethodPost,
+			Path:   "/api/v1/orders",
+			Limit:  Limit{Requests: 1, Per: time.Minute},
+		}},
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Customer") != "" {
+			ctx := auth.WithPrincipal(c.Request.Context(), auth.Principal{CustomerID: 7, Role: auth.RoleCustomer})
+			c.Request = c.Request.WithContext(ctx)
+		}
+	})
+	r.Use(Middleware(limiter, cfg))
+	r.GET("/api/v1/flights", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/api/v1/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	r.POST("/api/v1/orders/async", func(c *gin.Context) { c.Status(http.StatusAccepted) })
+
+	request := func(method, path string, customer bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if customer {
+			req.Header.Set("X-Customer", "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodGet, "/api/v1/flights", false)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "2", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+	// Orders have their own, stricter bucket, shared by the routes below
+	require.Equal(t, http.StatusCreated, request(http.MethodPost, "/api/v1/orders", true).Code)
+	w = request(http.MethodPost, "/api/v1/orders/async", true)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+	var body api.Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, http.StatusTooManyRequests, body.Code)
+
+	// Customers and addresses have buckets of their own
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/flights", true).Code)
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/flights", false).Code)
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/flights", false).Code)
+	require.Equal(t, http.StatusTooManyRequests, request(http.MethodGet, "/api/v1/flights", false).Code)
+
+	// Requests are let through while the limiter fails
+	limiter.err = errors.New("connection refused")
+	w = request(http.MethodGet, "/api/v1/flights", false)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestAddressMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := &stubLimiter{used: make(map[string]int)}
+	cfg := Config{
+		Enabled: true,
+		Default: Limit{Requests: 10, Per: time.Minute},
+		Address: Limit{Requests: 2, Per: time.Minute},
+	}
+
+	// Requests refused for bad credentials are counted against the address
+	r := gin.New()
+	r.Use(AddressMiddleware(limiter, cfg))
+	r.Use(func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+	r.GET("/api/v1/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(addr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		req.RemoteAddr = addr + ":1234"
+		req.Header.Set(auth.APIKeyHeader, "guess")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusUnauthorized, request("192.0.2.1"))
+	require.Equal(t, http.StatusUnauthorized, request("192.0.2.1"))
+	require.Equal(t, http.StatusTooManyRequests, request("192.0.2.1"))
+	require.Equal(t, http.StatusUnauthorized, request("192.0.2.2"))
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+var (
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidSortColumn = errors.New("invalid sort column")
+	ErrInvalidSortOrder  = errors.New("invalid sort order")
+)
+
+// sortColumns maps the columns a listing can be ordered by to the accessor of
+// the matching field, which is used to build and decode keyset cursors.
+type sortColumns[T any] map[string]func(*T) any
+
+// cursor is the decoded form of an opaque keyset cursor. It holds the sort
+// column value and ID of the last row of the previous page.
+type cursor struct {
+	SortBy string          `json:"s"`
+	Value  json.RawMessage `json:"v"`
+	ID     uint            `json:"id"`
+}
+
+// paginate applies ordering and either keyset or offset pagination to query.
+// Rows are always ordered by the sort column and then by ID, so that rows
+// sharing a sort value are paged in a stable order. One row more than a page
+// is read, which tells nextCursor whether another page follows.
+func paginate[T any](query *gorm.DB, columns sortColumns[T], params *model.ListParams) (*gorm.DB, error) {
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = "id"
+	}
+	accessor, ok := columns[sortBy]
+	if !ok {
+		return nil, ErrInvalidSortColumn
+	}
+
+	direction, comparison := " ASC", " > ?"
+	switch params.SortOrder {
+	case "", "asc":
+	case "desc":
+		direction, comparison = " DESC", " < ?"
+	default:
+		return nil, ErrInvalidSortOrder
+	}
+
+	if params.Cursor != "" {
+		c, err := decodeCursor(params.Cursor)
+		if err != nil || c.SortBy != sortBy {
+			return nil, ErrInvalidCursor
+		}
+		value := reflect.New(reflect.TypeOf(accessor(new(T))))
+		if err = json.Unmarshal(c.Value, value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		if sortBy == "id" {
+			query = query.Where("id"+comparison, c.ID)
+		} else {
+			query = query.Where("("+sortBy+comparison+" OR ("+sortBy+" = ? AND id"+comparison+"))",
+				value.Elem().Interface(), value.Elem().Interface(), c.ID)
+		}
+	} else if params.Page > 1 {
+		query = query.Offset((params.Page - 1) * params.PageSize)
+	}
+
+	if sortBy != "id" {
+		query = query.Order(sortBy + direction)
+	}
+	return query.Order("id" + direction).Limit(params.PageSize + 1), nil
+}
+
+// nextCursor trims the rows read by paginate to a page and returns them with
+// the cursor of the following page, or an empty string when there is nothing
+// left to read.
+func nextCursor[T any](columns sortColumns[T], params *model.ListParams, rows []T, id func(*T) uint) ([]T, string, error) {
+	if len(rows) <= params.PageSize {
+		return rows, "", nil
+	}
+	rows = rows[:params.PageSize]
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = "id"
+	}
+	accessor, ok := columns[sortBy]
+	if !ok {
+		return nil, "", ErrInvalidSortColumn
+	}
+
+	last := &rows[len(rows)-1]
+	value, err := json.Marshal(accessor(last))
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := json.Marshal(cursor{SortBy: sortBy, Value: value, ID: id(last)})
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	c := &cursor{}
+	if err = json.Unmarshal(raw, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
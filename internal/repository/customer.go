@@ -8,6 +8,8 @@ import (
 
 type Customer interface {
 	Create(customer *model.Customer) error
+	Get(id uint) (*model.Customer, error)
+	GetByEmail(email string) (*model.Customer, error)
 }
 
 func NewCustomerRepo(gdb *gorm.DB) Customer {
@@ -21,3 +23,19 @@ type customerRepo struct {
 func (o *customerRepo) Create(customer *model.Customer) error {
 	return o.gdb.Create(customer).Error
 }
+
+func (o *customerRepo) Get(id uint) (*model.Customer, error) {
+	customer := &model.Customer{}
+	if err := o.gdb.First(customer, id).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (o *customerRepo) GetByEmail(email string) (*model.Customer, error) {
+	customer := &model.Customer{}
+	if err := o.gdb.Where("email = ?", email).First(customer).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// whereLike filters column by value, matching either the whole column or, for
+// model.MatchPrefix, its beginning. Wildcards in value are escaped, so they
+// only ever match themselves. Case and accents are ignored through the
+// column's accent- and case-insensitive collation.
+func whereLike(query *gorm.DB, column, value, match string) *gorm.DB {
+	pattern := likeEscaper.Replace(value)
+	if match == model.MatchPrefix {
+		pattern += "%"
+	}
+	return query.Where(column+` LIKE ? ESCAPE '\\'`, pattern)
+}
@@ -10,7 +10,21 @@ import (
 
 type Flight interface {
 	Create(*model.Flight) error
-	List(params *model.ListParams, departureDate *time.Time) ([]model.Flight, int64, error)
+	Get(id uint) (*model.Flight, error)
+	// List returns a page of flights, their total count unless params.SkipCount
+	// is set, and the cursor of the next page, empty on the last page.
+	List(params *model.ListParams, departureDate *time.Time) ([]model.Flight, int64, string, error)
+	// DistinctValues returns the distinct values of the given columns of
+	// flights departing on or after since.
+	DistinctValues(since time.Time, columns ...string) ([]string, error)
+}
+
+var flightSortColumns = sortColumns[model.Flight]{
+	"id":              func(f *model.Flight) any { return f.ID },
+	"departure_time":  func(f *model.Flight) any { return f.DepartureTime },
+	"arrival_time":    func(f *model.Flight) any { return f.ArrivalTime },
+	"base_price":      func(f *model.Flight) any { return f.BasePrice },
+	"available_seats": func(f *model.Flight) any { return f.AvailableSeats },
 }
 
 func NewFlightRepo(gdb *gorm.DB) Flight {
@@ -25,12 +39,20 @@ func (f *flightRepo) Create(flight *model.Flight) error {
 	return f.gdb.Create(flight).Error
 }
 
-func (f *flightRepo) List(params *model.ListParams, departureDate *time.Time) ([]model.Flight, int64, error) {
+func (f *flightRepo) Get(id uint) (*model.Flight, error) {
+	flight := &model.Flight{}
+	if err := f.gdb.First(flight, id).Error; err != nil {
+		return nil, err
+	}
+	return flight, nil
+}
+
+func (f *flightRepo) List(params *model.ListParams, departureDate *time.Time) ([]model.Flight, int64, string, error) {
 	query := f.gdb
 	var listFilterColumnNames = []string{"flight_number", "airline", "departure_city", "arrival_city"}
 
 	if departureDate == nil {
-		year, month, day := time.Now().Date()
+		year, month, day := time.Now().UTC().Date()
 		today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 		departureDate = &today
 	}
@@ -39,32 +61,52 @@ func (f *flightRepo) List(params *model.ListParams, departureDate *time.Time) ([
 	// Apply filters
 	for _, field := range listFilterColumnNames {
 		if s, ok := params.Filters[field]; ok {
-			condition := field + " like ?"
-			query = query.Where(condition, s)
+			query = whereLike(query, field, s, params.Match)
 		}
 	}
-	countQuery := query
 
 	var totalCount int64
-	if err := countQuery.Model(&model.Flight{}).Count(&totalCount).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// Apply sorting
-	if params.SortBy != "" {
-		order := params.SortBy
-		if params.SortOrder == "desc" {
-			order += " DESC"
+	if !params.SkipCount {
+		countQuery := query
+		if err := countQuery.Model(&model.Flight{}).Count(&totalCount).Error; err != nil {
+			return nil, 0, "", err
 		}
-		query = query.Order(order)
 	}
 
-	offset := (params.Page - 1) * params.PageSize
-	query = query.Offset(offset).Limit(params.PageSize)
+	// Apply sorting and pagination
+	query, err := paginate(query, flightSortColumns, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
 	var flights []model.Flight
 	if err := query.Find(&flights).Error; err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+	flights, next, err := nextCursor(flightSortColumns, params, flights, func(flight *model.Flight) uint { return flight.ID })
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return flights, totalCount, next, nil
+}
+
+func (f *flightRepo) DistinctValues(since time.Time, columns ...string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var values []string
+	for _, column := range columns {
+		var columnValues []string
+		if err := f.gdb.Model(&model.Flight{}).
+			Where("departure_time >= ?", since).
+			Distinct(column).
+			Pluck(column, &columnValues).Error; err != nil {
+			return nil, err
+		}
+		for _, value := range columnValues {
+			if _, ok := seen[value]; !ok {
+				seen[value] = struct{}{}
+				values = append(values, value)
+			}
+		}
 	}
-	return flights, totalCount, nil
+	return values, nil
 }
@@ -1,3 +1,5 @@
+//go:build integration
+
 package repository
 
 import (
@@ -5,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/brianvoe/gofakeit/v7"
 	"github.com/ory/dockertest/v3"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
@@ -32,6 +35,10 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+	err = RunSeeds(gdb)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 
 	defer func() {
 		if err = pool.Purge(resource); err != nil {
@@ -81,7 +88,7 @@ func TestFlightRepo_List(t *testing.T) {
 	err = repo.Create(flight)
 	require.NoError(t, err)
 
-	flights, total, err := repo.List(&model.ListParams{
+	flights, total, _, err := repo.List(&model.ListParams{
 		Page:     1,
 		PageSize: 10,
 		Filters:  map[string]string{"flight_number": flight.FlightNumber},
@@ -94,3 +101,107 @@ func TestFlightRepo_List(t *testing.T) {
 	require.Equal(t, flight.DepartureCity, flights[0].DepartureCity)
 	require.Equal(t, flight.ArrivalCity, flights[0].ArrivalCity)
 }
+
+func TestFlightRepo_ListWithCursor(t *testing.T) {
+	tx := gdb.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+
+	repo := NewFlightRepo(tx)
+	year, month, day := time.Now().Date()
+	departure := time.Date(year, month, day, 23, 0, 0, 0, time.UTC)
+	airline := "CURSOR AIR " + gofakeit.LetterN(8)
+
+	// The last page is exactly full, which must not be followed by an empty one
+	created := make([]*model.Flight, 4)
+	for i := range created {
+		flight := MockFlight()
+		flight.FlightNumber = "CU" + gofakeit.DigitN(6)
+		flight.Airline = airline
+		flight.DepartureTime = departure
+		flight.ArrivalTime = departure.Add(2 * time.Hour)
+		err = repo.Create(flight)
+		require.NoError(t, err)
+		created[i] = flight
+	}
+
+	params := &model.ListParams{
+		PageSize:  2,
+		SortBy:    "departure_time",
+		Filters:   map[string]string{"airline": airline},
+		SkipCount: true,
+	}
+	var ids []uint
+	pages := 0
+	for {
+		flights, total, next, err := repo.List(params, nil)
+		require.NoError(t, err)
+		require.Zero(t, total)
+		require.NotEmpty(t, flights)
+		pages++
+		for _, flight := range flights {
+			ids = append(ids, flight.ID)
+		}
+		if next == "" {
+			break
+		}
+		params.Cursor = next
+	}
+
+	require.Equal(t, 2, pages)
+	require.Len(t, ids, len(created))
+	for i, flight := range created {
+		require.Equal(t, flight.ID, ids[i])
+	}
+
+	params.Cursor = "not-a-cursor"
+	_, _, _, err = repo.List(params, nil)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	// Unknown sorting is refused rather than falling back to the default
+	params.Cursor = ""
+	params.SortBy = "seats; DROP TABLE flights"
+	_, _, _, err = repo.List(params, nil)
+	require.ErrorIs(t, err, ErrInvalidSortColumn)
+	params.SortBy = "departure_time"
+	params.SortOrder = "sideways"
+	_, _, _, err = repo.List(params, nil)
+	require.ErrorIs(t, err, ErrInvalidSortOrder)
+}
+
+func TestFlightRepo_ListWithPrefixMatch(t *testing.T) {
+	tx := gdb.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+
+	repo := NewFlightRepo(tx)
+	flight := MockFlight()
+	year, month, day := time.Now().Date()
+	flight.DepartureTime = time.Date(year, month, day, 22, 0, 0, 0, time.UTC)
+	flight.ArrivalTime = flight.DepartureTime.Add(2 * time.Hour)
+	flight.DepartureCity = "Zürich " + gofakeit.LetterN(8)
+	err = repo.Create(flight)
+	require.NoError(t, err)
+
+	flights, _, _, err := repo.List(&model.ListParams{
+		Page:     1,
+		PageSize: 10,
+		Match:    model.MatchPrefix,
+		Filters:  map[string]string{"departure_city": "ZURICH " + flight.DepartureCity[len("Zürich "):len("Zürich ")+4]},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, flights, 1)
+	require.Equal(t, flight.ID, flights[0].ID)
+
+	// Wildcards in the input are matched literally.
+	flights, _, _, err = repo.List(&model.ListParams{
+		Page:     1,
+		PageSize: 10,
+		Match:    model.MatchPrefix,
+		Filters:  map[string]string{"departure_city": "%" + flight.DepartureCity[len("Zürich "):]},
+	}, nil)
+	require.NoError(t, err)
+	require.Empty(t, flights)
+}
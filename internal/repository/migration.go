@@ -1,19 +1,82 @@
 package repository
 
 import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+
 	"gorm.io/gorm"
 
-	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/catalog"
+	"github.com/joremysh/tonx/pkg/migrate"
 )
 
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// NewMigrator creates a migrator for the migrations embedded in the binary
+func NewMigrator(gdb *gorm.DB) (*migrate.Migrator, error) {
+	fsys, err := migrationFS()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(gdb, fsys)
+}
+
+// migrationFS returns the embedded migrations. They are templates given the
+// airports of the catalog, so backfills know the same airports as the code.
+func migrationFS() (fs.FS, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return templateFS{FS: fsys}, nil
+}
+
+// templateFS renders the files of an FS as templates when they are read
+type templateFS struct {
+	fs.FS
+}
+
+func (t templateFS) ReadFile(name string) ([]byte, error) {
+	body, err := fs.ReadFile(t.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"quote": func(s string) string {
+			return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+		},
+	}).Parse(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration %s: %w", name, err)
+	}
+	var rendered bytes.Buffer
+	if err = tmpl.Execute(&rendered, struct{ Airports []catalog.Airport }{catalog.Airports}); err != nil {
+		return nil, fmt.Errorf("failed to render migration %s: %w", name, err)
+	}
+	return rendered.Bytes(), nil
+}
+
+// Migrate applies all pending migrations
 func Migrate(gdb *gorm.DB) error {
-	err := gdb.AutoMigrate(&model.Flight{}, &model.Order{}, &model.Customer{})
+	migrator, err := NewMigrator(gdb)
 	if err != nil {
 		return err
 	}
+	_, err = migrator.Up(context.Background())
+	return err
+}
 
+// RunSeeds fills the database with fake flights and customers, for development
+// and tests only
+func RunSeeds(gdb *gorm.DB) error {
 	for _, seed := range All() {
-		if err = seed.Run(gdb); err != nil {
+		if err := seed.Run(gdb); err != nil {
 			return err
 		}
 	}
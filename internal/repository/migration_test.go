@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/catalog"
+)
+
+func TestMigrationFS(t *testing.T) {
+	fsys, err := migrationFS()
+	require.NoError(t, err)
+
+	// Backfills know every airport of the catalog
+	body, err := fs.ReadFile(fsys, "0002_flight_departure_date.up.sql")
+	require.NoError(t, err)
+	for _, airport := range catalog.Airports {
+		require.Contains(t, string(body), "WHEN '"+airport.City+"' THEN '"+airport.TimeZone+"'")
+	}
+	body, err = fs.ReadFile(fsys, "0011_flight_airports.up.sql")
+	require.NoError(t, err)
+	for _, airport := range catalog.Airports {
+		require.Contains(t, string(body), "WHEN '"+airport.City+"' THEN '"+airport.Code+"'")
+	}
+
+	// Every migration renders
+	entries, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		body, err = fs.ReadFile(fsys, entry.Name())
+		require.NoError(t, err, entry.Name())
+		require.False(t, strings.Contains(string(body), "{{"), entry.Name())
+	}
+}
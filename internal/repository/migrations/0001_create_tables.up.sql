@@ -0,0 +1,63 @@
+-- Matches the schema AutoMigrate created, so existing databases adopt it as is
+CREATE TABLE IF NOT EXISTS flights (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    flight_number VARCHAR(20) NOT NULL,
+    airline VARCHAR(100) NOT NULL,
+    departure_city VARCHAR(100) NOT NULL,
+    arrival_city VARCHAR(100) NOT NULL,
+    departure_time TIMESTAMP NOT NULL,
+    arrival_time TIMESTAMP NOT NULL,
+    aircraft VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'SCHEDULED',
+    total_seats BIGINT NOT NULL,
+    available_seats BIGINT NOT NULL,
+    base_price MEDIUMINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_flights_flight_number (flight_number),
+    INDEX idx_flights_departure_time (departure_time)
+);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_customers_email (email)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    flight_id BIGINT UNSIGNED NOT NULL,
+    customer_id BIGINT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    total_amount MEDIUMINT NOT NULL,
+    ticket_amount BIGINT NOT NULL DEFAULT 0,
+    order_number VARCHAR(50) NOT NULL,
+    booking_time TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_orders_order_number (order_number),
+    INDEX idx_orders_flight_id (flight_id),
+    INDEX idx_orders_customer_id (customer_id),
+    CONSTRAINT fk_orders_flight FOREIGN KEY (flight_id) REFERENCES flights (id),
+    CONSTRAINT fk_orders_customer FOREIGN KEY (customer_id) REFERENCES customers (id)
+);
+
+-- Databases AutoMigrate created before orders counted their tickets lack the
+-- column, and MySQL has no ADD COLUMN IF NOT EXISTS. The reconciler derives
+-- the tickets of orders left at 0 from their amount.
+SET @add_ticket_amount = (
+    SELECT IF(COUNT(*) = 0, 'ALTER TABLE orders ADD COLUMN ticket_amount BIGINT NOT NULL DEFAULT 0 AFTER total_amount', 'DO 0')
+    FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'orders' AND column_name = 'ticket_amount'
+);
+PREPARE add_ticket_amount FROM @add_ticket_amount;
+EXECUTE add_ticket_amount;
+DEALLOCATE PREPARE add_ticket_amount;
@@ -0,0 +1,6 @@
+-- Fails while a flight number has flights on several dates
+ALTER TABLE flights
+    DROP INDEX idx_flights_flight_number_departure_date,
+    ADD UNIQUE INDEX idx_flights_flight_number (flight_number);
+
+ALTER TABLE flights DROP COLUMN departure_date;
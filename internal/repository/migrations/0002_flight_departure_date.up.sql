@@ -0,0 +1,31 @@
+-- A flight number operates once per date rather than once ever. MySQL has no
+-- ADD COLUMN IF NOT EXISTS, and the migration may be rerun once the time zone
+-- tables are loaded.
+SET @add_departure_date = (
+    SELECT IF(COUNT(*) = 0, 'ALTER TABLE flights ADD COLUMN departure_date DATE NULL AFTER departure_time', 'DO 0')
+    FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'flights' AND column_name = 'departure_date'
+);
+PREPARE add_departure_date FROM @add_departure_date;
+EXECUTE add_departure_date;
+DEALLOCATE PREPARE add_departure_date;
+
+-- The date is local to the departure airport, like Flight.BeforeSave computes
+-- it. Naming the zones needs the time zone tables of MySQL, without them the
+-- dates stay NULL and the migration fails below rather than keeping UTC dates.
+UPDATE flights SET departure_date = DATE(CONVERT_TZ(departure_time, @@session.time_zone,
+    CASE departure_city
+{{- range .Airports}}
+        WHEN {{quote .City}} THEN {{quote .TimeZone}}
+{{- end}}
+    END))
+WHERE departure_city IN ({{range $i, $airport := .Airports}}{{if $i}}, {{end}}{{quote $airport.City}}{{end}});
+
+-- Cities outside the catalog have no known zone
+UPDATE flights SET departure_date = DATE(departure_time) WHERE departure_date IS NULL AND departure_city NOT IN ({{range $i, $airport := .Airports}}{{if $i}}, {{end}}{{quote $airport.City}}{{end}});
+
+ALTER TABLE flights MODIFY departure_date DATE NOT NULL;
+
+ALTER TABLE flights
+    DROP INDEX idx_flights_flight_number,
+    ADD UNIQUE INDEX idx_flights_flight_number_departure_date (flight_number, departure_date);
@@ -0,0 +1,6 @@
+ALTER TABLE flights
+    DROP FOREIGN KEY fk_flights_schedule,
+    DROP INDEX idx_flights_schedule_id,
+    DROP COLUMN schedule_id;
+
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS schedules (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    flight_number VARCHAR(20) NOT NULL,
+    airline VARCHAR(100) NOT NULL,
+    departure_airport CHAR(3) NOT NULL,
+    arrival_airport CHAR(3) NOT NULL,
+    days_of_week VARCHAR(7) NOT NULL,
+    departure_time CHAR(5) NOT NULL,
+    arrival_time CHAR(5) NOT NULL,
+    arrival_day_offset TINYINT NOT NULL DEFAULT 0,
+    effective_from DATE NOT NULL,
+    effective_to DATE NULL,
+    aircraft VARCHAR(50) NOT NULL,
+    total_seats INT NOT NULL,
+    base_price MEDIUMINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_schedules_flight_number (flight_number)
+);
+
+-- Flights keep their schedule when it is deleted, they may have been booked
+ALTER TABLE flights
+    ADD COLUMN schedule_id BIGINT UNSIGNED NULL AFTER id,
+    ADD INDEX idx_flights_schedule_id (schedule_id),
+    ADD CONSTRAINT fk_flights_schedule FOREIGN KEY (schedule_id) REFERENCES schedules (id) ON DELETE SET NULL;
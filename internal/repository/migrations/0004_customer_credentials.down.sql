@@ -0,0 +1,3 @@
+ALTER TABLE customers
+    DROP COLUMN role,
+    DROP COLUMN password_hash;
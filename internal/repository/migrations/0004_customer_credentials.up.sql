@@ -0,0 +1,3 @@
+ALTER TABLE customers
+    ADD COLUMN password_hash VARCHAR(100) NULL AFTER status,
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'CUSTOMER' AFTER password_hash;
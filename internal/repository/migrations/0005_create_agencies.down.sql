@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    DROP FOREIGN KEY fk_orders_agency,
+    DROP INDEX idx_orders_agency_id,
+    DROP COLUMN agency_id;
+
+DROP TABLE IF EXISTS api_keys;
+
+DROP TABLE IF EXISTS agencies;
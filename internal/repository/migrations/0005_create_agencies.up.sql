@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS agencies (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_agencies_name (name)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    agency_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(100) NOT NULL,
+    rate_limit INT NOT NULL DEFAULT 0,
+    daily_booking_quota INT NOT NULL DEFAULT 0,
+    revoked_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_api_keys_key_hash (key_hash),
+    INDEX idx_api_keys_agency_id (agency_id),
+    CONSTRAINT fk_api_keys_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+
+-- Commission is reported from the agency of each order
+ALTER TABLE orders
+    ADD COLUMN agency_id BIGINT UNSIGNED NULL AFTER customer_id,
+    ADD INDEX idx_orders_agency_id (agency_id),
+    ADD CONSTRAINT fk_orders_agency FOREIGN KEY (agency_id) REFERENCES agencies (id);
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id CHAR(36) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    aggregate_id BIGINT UNSIGNED NOT NULL,
+    payload JSON NOT NULL,
+    created_at DATETIME(3) NULL,
+    dispatched_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_outbox_events_type (type),
+    INDEX idx_outbox_events_aggregate_id (aggregate_id),
+    INDEX idx_outbox_events_created_at (created_at),
+    INDEX idx_outbox_events_dispatched_at (dispatched_at)
+);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    url VARCHAR(500) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    event_types VARCHAR(500) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    event_id CHAR(36) NOT NULL,
+    webhook_id BIGINT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NULL,
+    last_status_code INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(500) NOT NULL DEFAULT '',
+    delivered_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_webhook_deliveries_event_webhook (event_id, webhook_id),
+    INDEX idx_webhook_deliveries_next_attempt_at (next_attempt_at),
+    CONSTRAINT fk_webhook_deliveries_event FOREIGN KEY (event_id) REFERENCES outbox_events (id),
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS travelers (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    order_id BIGINT UNSIGNED NOT NULL,
+    first_name VARCHAR(50) NOT NULL,
+    last_name VARCHAR(50) NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_travelers_order_id (order_id),
+    CONSTRAINT fk_travelers_order FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    kind VARCHAR(30) NOT NULL,
+    order_id BIGINT UNSIGNED NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NULL,
+    last_error VARCHAR(500) NOT NULL DEFAULT '',
+    sent_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_notifications_order_id (order_id),
+    INDEX idx_notifications_next_attempt_at (next_attempt_at),
+    CONSTRAINT fk_notifications_order FOREIGN KEY (order_id) REFERENCES orders (id)
+);
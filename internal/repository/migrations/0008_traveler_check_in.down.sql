@@ -0,0 +1,6 @@
+ALTER TABLE travelers
+    DROP INDEX idx_travelers_seat_flight_id_seat,
+    DROP COLUMN seat_flight_id,
+    DROP COLUMN boarding_sequence,
+    DROP COLUMN checked_in_at,
+    DROP COLUMN seat;
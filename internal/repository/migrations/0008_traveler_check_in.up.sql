@@ -0,0 +1,6 @@
+ALTER TABLE travelers
+    ADD COLUMN seat VARCHAR(4) NOT NULL DEFAULT '' AFTER last_name,
+    ADD COLUMN checked_in_at DATETIME(3) NULL AFTER seat,
+    ADD COLUMN boarding_sequence INT NOT NULL DEFAULT 0 AFTER checked_in_at,
+    ADD COLUMN seat_flight_id BIGINT UNSIGNED NULL AFTER boarding_sequence,
+    ADD UNIQUE INDEX idx_travelers_seat_flight_id_seat (seat_flight_id, seat);
@@ -0,0 +1,3 @@
+ALTER TABLE customers
+    DROP INDEX idx_customers_calendar_token_hash,
+    DROP COLUMN calendar_token_hash;
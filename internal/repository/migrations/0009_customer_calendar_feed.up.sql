@@ -0,0 +1,3 @@
+ALTER TABLE customers
+    ADD COLUMN calendar_token_hash CHAR(64) NULL AFTER role,
+    ADD UNIQUE INDEX idx_customers_calendar_token_hash (calendar_token_hash);
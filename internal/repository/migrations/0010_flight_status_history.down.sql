@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_entitlements;
+
+DROP TABLE IF EXISTS flight_status_histories;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS flight_status_histories (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    flight_id BIGINT UNSIGNED NOT NULL,
+    previous_status VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    previous_departure_time TIMESTAMP NOT NULL,
+    previous_arrival_time TIMESTAMP NOT NULL,
+    departure_time TIMESTAMP NOT NULL,
+    arrival_time TIMESTAMP NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    changed_by BIGINT UNSIGNED NULL,
+    affected_orders INT NOT NULL DEFAULT 0,
+    entitlements_granted INT NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_flight_status_histories_flight_id (flight_id),
+    CONSTRAINT fk_flight_status_histories_flight FOREIGN KEY (flight_id) REFERENCES flights (id)
+);
+
+CREATE TABLE IF NOT EXISTS order_entitlements (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    order_id BIGINT UNSIGNED NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    flight_status_history_id BIGINT UNSIGNED NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_order_entitlements_order_id_kind (order_id, kind),
+    CONSTRAINT fk_order_entitlements_order FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_entitlements_history FOREIGN KEY (flight_status_history_id) REFERENCES flight_status_histories (id)
+);
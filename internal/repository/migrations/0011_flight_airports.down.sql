@@ -0,0 +1,3 @@
+ALTER TABLE flights
+    DROP COLUMN arrival_airport,
+    DROP COLUMN departure_airport;
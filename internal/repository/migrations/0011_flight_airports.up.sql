@@ -0,0 +1,19 @@
+-- A city may have more than one airport, so flights record theirs
+ALTER TABLE flights
+    ADD COLUMN departure_airport CHAR(3) NOT NULL DEFAULT '' AFTER arrival_city,
+    ADD COLUMN arrival_airport CHAR(3) NOT NULL DEFAULT '' AFTER departure_airport;
+
+-- Every city of the catalog has a single airport, flights between other
+-- cities are left without codes
+UPDATE flights SET departure_airport = CASE departure_city
+{{- range .Airports}}
+        WHEN {{quote .City}} THEN {{quote .Code}}
+{{- end}}
+        ELSE ''
+    END,
+    arrival_airport = CASE arrival_city
+{{- range .Airports}}
+        WHEN {{quote .City}} THEN {{quote .Code}}
+{{- end}}
+        ELSE ''
+    END;
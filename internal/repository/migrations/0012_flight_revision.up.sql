@@ -0,0 +1,7 @@
+-- Counts the changes of the times and status of a flight, so calendars can
+-- tell newer versions of its events
+ALTER TABLE flights ADD COLUMN revision INT NOT NULL DEFAULT 0 AFTER status;
+
+UPDATE flights SET revision = (
+    SELECT COUNT(*) FROM flight_status_histories WHERE flight_status_histories.flight_id = flights.id
+);
@@ -0,0 +1,14 @@
+-- Agencies book only for their own customers
+CREATE TABLE IF NOT EXISTS agency_customers (
+    agency_id BIGINT UNSIGNED NOT NULL,
+    customer_id BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (agency_id, customer_id),
+    INDEX idx_agency_customers_customer_id (customer_id),
+    CONSTRAINT fk_agency_customers_agency FOREIGN KEY (agency_id) REFERENCES agencies (id),
+    CONSTRAINT fk_agency_customers_customer FOREIGN KEY (customer_id) REFERENCES customers (id)
+);
+
+-- Customers an agency booked for already stay its customers
+INSERT IGNORE INTO agency_customers (agency_id, customer_id, created_at)
+SELECT agency_id, customer_id, MIN(created_at) FROM orders WHERE agency_id IS NOT NULL GROUP BY agency_id, customer_id;
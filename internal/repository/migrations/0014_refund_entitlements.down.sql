@@ -0,0 +1 @@
+UPDATE order_entitlements SET kind = 'CHANGE_OR_REFUND' WHERE kind = 'REFUND';
@@ -0,0 +1,3 @@
+-- Entitlements only ever refunded cancellations, changing flights is not
+-- offered
+UPDATE order_entitlements SET kind = 'REFUND' WHERE kind = 'CHANGE_OR_REFUND';
@@ -8,7 +8,17 @@ import (
 
 type Order interface {
 	Create(*model.Order) error
-	List(params *model.ListParams) ([]model.Order, int64, error)
+	// List returns a page of orders, their total count unless params.SkipCount
+	// is set, and the cursor of the next page, empty on the last page.
+	List(params *model.ListParams) ([]model.Order, int64, string, error)
+}
+
+var orderSortColumns = sortColumns[model.Order]{
+	"id":           func(o *model.Order) any { return o.ID },
+	"booking_time": func(o *model.Order) any { return o.BookingTime },
+	"total_amount": func(o *model.Order) any { return o.TotalAmount },
+	"order_number": func(o *model.Order) any { return o.OrderNumber },
+	"status":       func(o *model.Order) any { return o.Status },
 }
 
 func NewOrderRepo(gdb *gorm.DB) Order {
@@ -23,39 +33,38 @@ func (o *orderRepo) Create(order *model.Order) error {
 	return o.gdb.Create(order).Error
 }
 
-func (o *orderRepo) List(params *model.ListParams) ([]model.Order, int64, error) {
+func (o *orderRepo) List(params *model.ListParams) ([]model.Order, int64, string, error) {
 	query := o.gdb
 	var listFilterColumnNames = []string{"status", "booking_time", "order_number"}
 
 	// Apply filters
 	for _, field := range listFilterColumnNames {
 		if s, ok := params.Filters[field]; ok {
-			condition := field + " like ?"
-			query = query.Where(condition, s)
+			query = whereLike(query, field, s, params.Match)
 		}
 	}
-	countQuery := query
 
 	var totalCount int64
-	if err := countQuery.Model(&model.Order{}).Count(&totalCount).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// Apply sorting
-	if params.SortBy != "" {
-		order := params.SortBy
-		if params.SortOrder == "desc" {
-			order += " DESC"
+	if !params.SkipCount {
+		countQuery := query
+		if err := countQuery.Model(&model.Order{}).Count(&totalCount).Error; err != nil {
+			return nil, 0, "", err
 		}
-		query = query.Order(order)
 	}
 
-	offset := (params.Page - 1) * params.PageSize
-	query = query.Offset(offset).Limit(params.PageSize)
+	// Apply sorting and pagination
+	query, err := paginate(query, orderSortColumns, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
 	var orders []model.Order
 	if err := query.Find(&orders).Error; err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+	orders, next, err := nextCursor(orderSortColumns, params, orders, func(order *model.Order) uint { return order.ID })
+	if err != nil {
+		return nil, 0, "", err
 	}
-	return orders, totalCount, nil
+	return orders, totalCount, next, nil
 }
@@ -57,7 +57,7 @@ func MockFlight() *model.Flight {
 		Aircraft:       "Airbus A330",
 		Status:         string(api.FlightStatusSCHEDULED),
 		TotalSeats:     100,
-		AvailableSeats: 120,
+		AvailableSeats: 100,
 		BasePrice:      6000,
 	}
 }
@@ -0,0 +1,371 @@
+// Package seed generates reproducible datasets of flights, customers and
+// orders for demos and load tests.
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/catalog"
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/model"
+)
+
+const batchSize = 500
+
+// Options controls the generated dataset. The same options always generate
+// the same dataset.
+type Options struct {
+	Seed      uint64
+	Flights   int
+	Customers int
+	// From and To bound the departure dates of the flights
+	From time.Time
+	To   time.Time
+	// Orders also generates the orders booked on the flights until Now
+	Orders bool
+	// Now is the time orders are booked until, flights departing before it
+	// are completed
+	Now time.Time
+}
+
+// Dataset is a generated set of fixtures. Orders refer to their flight and
+// customer by index into Flights and Customers.
+type Dataset struct {
+	Flights   []model.Flight
+	Customers []model.Customer
+	Orders    []Order
+}
+
+// Order is a generated order before its flight and customer are stored
+type Order struct {
+	model.Order
+	FlightIndex   int
+	CustomerIndex int
+}
+
+// Generate builds a dataset from opts
+func Generate(opts Options) (*Dataset, error) {
+	if opts.Flights < 0 || opts.Customers < 0 {
+		return nil, fmt.Errorf("negative number of flights or customers")
+	}
+	if !opts.To.After(opts.From) {
+		return nil, fmt.Errorf("date range %s to %s is empty", opts.From.Format(time.DateOnly), opts.To.Format(time.DateOnly))
+	}
+	if opts.Orders && opts.Customers == 0 && opts.Flights > 0 {
+		return nil, fmt.Errorf("orders need at least one customer")
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x5eed))
+	faker := gofakeit.NewFaker(rand.NewPCG(opts.Seed, opts.Seed^0xfa4e), false)
+	g := &generator{opts: opts, rng: rng, faker: faker, numbers: make(map[string]bool)}
+
+	dataset := &Dataset{}
+	for i := 0; i < opts.Customers; i++ {
+		dataset.Customers = append(dataset.Customers, g.customer(i))
+	}
+	for i := 0; i < opts.Flights; i++ {
+		flight, err := g.flight()
+		if err != nil {
+			return nil, err
+		}
+		dataset.Flights = append(dataset.Flights, flight)
+	}
+	sort.SliceStable(dataset.Flights, func(i, j int) bool {
+		return dataset.Flights[i].DepartureTime.Before(dataset.Flights[j].DepartureTime)
+	})
+
+	if opts.Orders {
+		for i := range dataset.Flights {
+			dataset.Orders = append(dataset.Orders, g.orders(dataset, i)...)
+		}
+	}
+	return dataset, nil
+}
+
+type generator struct {
+	opts    Options
+	rng     *rand.Rand
+	faker   *gofakeit.Faker
+	numbers map[string]bool
+}
+
+func (g *generator) customer(i int) model.Customer {
+	first, last := g.faker.FirstName(), g.faker.LastName()
+	return model.Customer{
+		Name: first + " " + last,
+		// The index keeps emails unique however often names repeat
+		Email:  fmt.Sprintf("%s.%s.%d@example.com", emailPart(first), emailPart(last), i),
+		Phone:  g.faker.Phone(),
+		Status: "ACTIVE",
+	}
+}
+
+// emailPart lowercases a name and drops what does not belong in an address.
+func emailPart(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+func (g *generator) flight() (model.Flight, error) {
+	airline := catalog.Airlines[g.rng.IntN(len(catalog.Airlines))]
+	hub, _ := catalog.AirportByCode(airline.Hub)
+	destination := hub
+	for destination.Code == hub.Code {
+		destination = catalog.Airports[g.rng.IntN(len(catalog.Airports))]
+	}
+	from, to := hub, destination
+	if g.rng.IntN(2) == 1 {
+		from, to = to, from
+	}
+
+	distance := catalog.Distance(from, to)
+	aircrafts := catalog.AircraftsFor(distance)
+	aircraft := aircrafts[g.rng.IntN(len(aircrafts))]
+
+	// Departures are at a local time of day between 06:00 and 23:55
+	days := int(g.opts.To.Sub(g.opts.From).Hours() / 24)
+	date := g.opts.From.AddDate(0, 0, g.rng.IntN(max(days, 1)))
+	minutes := 6*60 + g.rng.IntN(18*12)*5
+	departure := time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, from.Location())
+
+	number, err := g.flightNumber(airline, distance)
+	if err != nil {
+		return model.Flight{}, err
+	}
+
+	// Fares vary around the usual one, in whole hundreds
+	price := int(math.Round(float64(catalog.BaseFare(distance))*(0.8+g.rng.Float64()*0.4)/100) * 100)
+
+	status := api.FlightStatusSCHEDULED
+	if departure.Before(g.now()) {
+		status = api.FlightStatusCOMPLETED
+	}
+	return model.Flight{
+		FlightNumber:     number,
+		Airline:          airline.Name,
+		DepartureCity:    from.City,
+		ArrivalCity:      to.City,
+		DepartureAirport: from.Code,
+		ArrivalAirport:   to.Code,
+		DepartureTime:    departure,
+		DepartureDate:    model.Date(departure),
+		ArrivalTime:      departure.Add(catalog.BlockTime(distance)),
+		Aircraft:         aircraft.Name,
+		Status:           string(status),
+		TotalSeats:       aircraft.Seats,
+		AvailableSeats:   aircraft.Seats,
+		BasePrice:        price,
+	}, nil
+}
+
+// flightNumber picks an unused flight number, long-haul routes get the low
+// numbers like airlines tend to give them.
+func (g *generator) flightNumber(airline catalog.Airline, distance float64) (string, error) {
+	low, high := 100, 999
+	if distance > 4000 {
+		low, high = 1, 99
+	}
+	for attempt := 0; attempt < 100; attempt++ {
+		number := fmt.Sprintf("%s%d", airline.Code, low+g.rng.IntN(high-low+1))
+		if !g.numbers[number] {
+			g.numbers[number] = true
+			return number, nil
+		}
+		if distance > 4000 {
+			// Short of long-haul numbers, use four digits
+			low, high = 1000, 9999
+		}
+	}
+	return "", fmt.Errorf("ran out of flight numbers for %s", airline.Code)
+}
+
+// orders books a flight up to a random load factor, spread over the two
+// months before departure and cut off at Now.
+func (g *generator) orders(dataset *Dataset, flightIndex int) []Order {
+	flight := &dataset.Flights[flightIndex]
+	bookingOpens := flight.DepartureTime.AddDate(0, -2, 0)
+	bookingCloses := flight.DepartureTime
+	if now := g.now(); now.Before(bookingCloses) {
+		bookingCloses = now
+	}
+	if !bookingCloses.After(bookingOpens) {
+		return nil
+	}
+	// Flights sell over time, so a flight booked until departure ends up
+	// fuller than one with half its sales window left.
+	elapsed := float64(bookingCloses.Sub(bookingOpens)) / float64(flight.DepartureTime.Sub(bookingOpens))
+	target := int(float64(flight.TotalSeats) * (0.5 + g.rng.Float64()*0.45) * elapsed)
+
+	var orders []Order
+	booked := 0
+	for booked < target {
+		tickets := min(1+g.rng.IntN(4), target-booked)
+		bookingTime := bookingOpens.Add(time.Duration(g.rng.Int64N(int64(bookingCloses.Sub(bookingOpens)))))
+		status := api.OrderStatusCOMPLETED
+		if g.rng.IntN(20) == 0 {
+			status = api.OrderStatusCANCELLED
+		} else {
+			booked += tickets
+		}
+		orders = append(orders, Order{
+			Order: model.Order{
+				Status:       string(status),
+				TotalAmount:  flight.BasePrice * tickets,
+				TicketAmount: tickets,
+				OrderNumber:  fmt.Sprintf("%s-%s-%08x", constant.ORD_PREFIX, bookingTime.Format("20060102"), g.rng.Uint32()),
+				BookingTime:  bookingTime,
+			},
+			FlightIndex:   flightIndex,
+			CustomerIndex: g.rng.IntN(len(dataset.Customers)),
+		})
+	}
+	flight.AvailableSeats = flight.TotalSeats - booked
+	return orders
+}
+
+func (g *generator) now() time.Time {
+	if g.opts.Now.IsZero() {
+		return time.Now()
+	}
+	return g.opts.Now
+}
+
+// Result counts the rows a Load inserted, rows already present are skipped
+type Result struct {
+	Flights   int
+	Customers int
+	Orders    int
+}
+
+// Load inserts a dataset. Flights and customers already present, such as
+// from an earlier run with the same seed, are kept as they are, and orders
+// are only added to the flights inserted by this run so their seats add up.
+func Load(gdb *gorm.DB, dataset *Dataset) (*Result, error) {
+	result := &Result{}
+	err := gdb.Transaction(func(tx *gorm.DB) error {
+		existing, err := existingFlights(tx, dataset.Flights)
+		if err != nil {
+			return fmt.Errorf("failed to find existing flights: %w", err)
+		}
+
+		var flights []*model.Flight
+		created := make(map[int]bool)
+		for i := range dataset.Flights {
+			if !existing[flightKey(dataset.Flights[i].FlightNumber, dataset.Flights[i].DepartureDate)] {
+				flights = append(flights, &dataset.Flights[i])
+				created[i] = true
+			}
+		}
+		if len(flights) > 0 {
+			if err = tx.CreateInBatches(flights, batchSize).Error; err != nil {
+				return fmt.Errorf("failed to create flights: %w", err)
+			}
+		}
+		result.Flights = len(flights)
+
+		customers := make([]*model.Customer, len(dataset.Customers))
+		for i := range dataset.Customers {
+			customers[i] = &dataset.Customers[i]
+		}
+		if len(customers) > 0 {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(customers, batchSize)
+			if res.Error != nil {
+				return fmt.Errorf("failed to create customers: %w", res.Error)
+			}
+			result.Customers = int(res.RowsAffected)
+		}
+		// Customers skipped as duplicates have no ID yet
+		emails := make([]string, len(dataset.Customers))
+		for i, customer := range dataset.Customers {
+			emails[i] = customer.Email
+		}
+		customerIDs, err := ids(tx, &model.Customer{}, "email", emails)
+		if err != nil {
+			return fmt.Errorf("failed to find customers: %w", err)
+		}
+
+		var orders []*model.Order
+		for i := range dataset.Orders {
+			order := &dataset.Orders[i]
+			if !created[order.FlightIndex] {
+				continue
+			}
+			order.FlightID = dataset.Flights[order.FlightIndex].ID
+			order.CustomerID = customerIDs[dataset.Customers[order.CustomerIndex].Email]
+			orders = append(orders, &order.Order)
+		}
+		if len(orders) > 0 {
+			if err = tx.CreateInBatches(orders, batchSize).Error; err != nil {
+				return fmt.Errorf("failed to create orders: %w", err)
+			}
+		}
+		result.Orders = len(orders)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// existingFlights returns the keys of the flights already stored, by flight
+// number and departure date.
+func existingFlights(tx *gorm.DB, flights []model.Flight) (map[string]bool, error) {
+	numbers := make([]string, len(flights))
+	for i, flight := range flights {
+		numbers[i] = flight.FlightNumber
+	}
+	found := make(map[string]bool)
+	for start := 0; start < len(numbers); start += batchSize {
+		var present []model.Flight
+		end := min(start+batchSize, len(numbers))
+		if err := tx.Select("flight_number", "departure_date").Where("flight_number IN ?", numbers[start:end]).Find(&present).Error; err != nil {
+			return nil, err
+		}
+		for _, flight := range present {
+			found[flightKey(flight.FlightNumber, flight.DepartureDate)] = true
+		}
+	}
+	return found, nil
+}
+
+func flightKey(number string, date time.Time) string {
+	return number + "/" + date.Format(time.DateOnly)
+}
+
+// ids returns the IDs of the rows with values in column.
+func ids(tx *gorm.DB, model interface{}, column string, values []string) (map[string]uint, error) {
+	found := make(map[string]uint)
+	for start := 0; start < len(values); start += batchSize {
+		var rows []struct {
+			ID    uint
+			Value string
+		}
+		end := min(start+batchSize, len(values))
+		if err := tx.Model(model).Select("id, "+column+" AS value").Where(column+" IN ?", values[start:end]).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			found[row.Value] = row.ID
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,87 @@
+package seed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/catalog"
+)
+
+func testOptions() Options {
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	return Options{
+		Seed:      42,
+		Flights:   100,
+		Customers: 30,
+		From:      from,
+		To:        from.AddDate(0, 1, 0),
+		Orders:    true,
+		Now:       from.AddDate(0, 0, 10),
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	first, err := Generate(testOptions())
+	require.NoError(t, err)
+	second, err := Generate(testOptions())
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	opts := testOptions()
+	opts.Seed = 43
+	other, err := Generate(opts)
+	require.NoError(t, err)
+	require.NotEqual(t, first.Flights, other.Flights)
+}
+
+func TestGenerate(t *testing.T) {
+	opts := testOptions()
+	dataset, err := Generate(opts)
+	require.NoError(t, err)
+	require.Len(t, dataset.Flights, opts.Flights)
+	require.Len(t, dataset.Customers, opts.Customers)
+	require.NotEmpty(t, dataset.Orders)
+
+	numbers := make(map[string]bool)
+	for _, flight := range dataset.Flights {
+		require.False(t, numbers[flight.FlightNumber], "duplicate flight number %s", flight.FlightNumber)
+		numbers[flight.FlightNumber] = true
+
+		require.False(t, flight.DepartureTime.Before(opts.From.Add(-24*time.Hour)))
+		require.True(t, flight.DepartureTime.Before(opts.To.Add(24*time.Hour)))
+		require.True(t, flight.ArrivalTime.After(flight.DepartureTime))
+		require.NotEqual(t, flight.DepartureCity, flight.ArrivalCity)
+
+		aircraft, ok := catalog.AircraftByName(flight.Aircraft)
+		require.True(t, ok)
+		require.Equal(t, aircraft.Seats, flight.TotalSeats)
+	}
+
+	booked := make([]int, len(dataset.Flights))
+	for _, order := range dataset.Orders {
+		require.False(t, order.BookingTime.After(opts.Now))
+		require.Equal(t, dataset.Flights[order.FlightIndex].BasePrice*order.TicketAmount, order.TotalAmount)
+		if order.Status != string(api.OrderStatusCANCELLED) {
+			booked[order.FlightIndex] += order.TicketAmount
+		}
+	}
+	for i, flight := range dataset.Flights {
+		require.Equal(t, flight.TotalSeats-booked[i], flight.AvailableSeats)
+		require.GreaterOrEqual(t, flight.AvailableSeats, 0)
+	}
+}
+
+func TestGenerate_InvalidOptions(t *testing.T) {
+	opts := testOptions()
+	opts.To = opts.From
+	_, err := Generate(opts)
+	require.Error(t, err)
+
+	opts = testOptions()
+	opts.Customers = 0
+	_, err = Generate(opts)
+	require.Error(t, err)
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/cache"
+)
+
+var (
+	ErrAgencyNotFound = errors.New("agency not found")
+	ErrAgencyExists   = errors.New("agency already exists")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidScope   = errors.New("invalid scope")
+	ErrQuotaExceeded  = errors.New("daily booking quota exceeded")
+)
+
+// apiKeyPrefix starts every API key, so leaked keys are easy to scan for
+const apiKeyPrefix = "tonx_"
+
+// CreateAPIKeyRequest represents the settings of a new API key
+type CreateAPIKeyRequest struct {
+	Name              string
+	Scopes            []string
+	RateLimit         int
+	DailyBookingQuota int
+}
+
+// APIKey defines the interface for travel agencies and their API keys
+type APIKey interface {
+	auth.KeyVerifier
+	CreateAgency(ctx context.Context, name string) (*model.Agency, error)
+	ListAgencies(ctx context.Context) ([]model.Agency, error)
+	// CreateKey creates an API key of an agency and returns it along with the
+	// key itself, which is not stored and cannot be shown again
+	CreateKey(ctx context.Context, agencyID uint, req CreateAPIKeyRequest) (*model.APIKey, string, error)
+	ListKeys(ctx context.Context, agencyID uint) ([]model.APIKey, error)
+	// RevokeKey stops a key from working, within APIKeyCacheTTL
+	RevokeKey(ctx context.Context, keyID uint) error
+	// UseBookingQuota counts a booking against the daily quota of a key in
+	// Redis. The returned func gives it back for bookings that fail.
+	UseBookingQuota(ctx context.Context, keyID uint, quota int) (func(), error)
+	// CreateAgencyCustomer creates a customer an agency books for
+	CreateAgencyCustomer(ctx context.Context, agencyID uint, customer *model.Customer) error
+	// IsAgencyCustomer reports whether an agency may book for a customer
+	IsAgencyCustomer(ctx context.Context, agencyID, customerID uint) (bool, error)
+}
+
+// cachedKey is an API key looked up recently, or nil for an unknown one
+type cachedKey struct {
+	key     *model.APIKey
+	expires time.Time
+}
+
+// apiKeyService implements APIKey
+type apiKeyService struct {
+	gdb         *gorm.DB
+	redisClient *cache.RedisClient
+
+	mu   sync.Mutex
+	keys map[string]cachedKey
+}
+
+// NewAPIKeyService creates a new instance of APIKey. Keys are cached for
+// APIKeyCacheTTL, so most requests skip the database.
+func NewAPIKeyService(gdb *gorm.DB, redisClient *cache.RedisClient) APIKey {
+	return &apiKeyService{
+		gdb:         gdb,
+		redisClient: redisClient,
+		keys:        make(map[string]cachedKey),
+	}
+}
+
+func (s *apiKeyService) CreateAgency(ctx context.Context, name string) (*model.Agency, error) {
+	agency := &model.Agency{Name: name}
+	result := s.gdb.WithContext(ctx).Where(model.Agency{Name: name}).FirstOrCreate(agency)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create agency: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAgencyExists
+	}
+	return agency, nil
+}
+
+func (s *apiKeyService) ListAgencies(ctx context.Context) ([]model.Agency, error) {
+	var agencies []model.Agency
+	if err := s.gdb.WithContext(ctx).Order("name").Find(&agencies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list agencies: %w", err)
+	}
+	return agencies, nil
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, agencyID uint, req CreateAPIKeyRequest) (*model.APIKey, string, error) {
+	for _, scope := range req.Scopes {
+		if scope != auth.ScopeSearch && scope != auth.ScopeBooking {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+	if err := s.gdb.WithContext(ctx).First(&model.Agency{}, agencyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrAgencyNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get agency: %w", err)
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(secret)
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	key := &model.APIKey{
+		AgencyID:          agencyID,
+		Name:              req.Name,
+		Prefix:            rawKey[:len(apiKeyPrefix)+6],
+		KeyHash:           hashSecret(rawKey),
+		Scopes:            strings.Join(slices.Compact(scopes), ","),
+		RateLimit:         req.RateLimit,
+		DailyBookingQuota: req.DailyBookingQuota,
+	}
+	if err := s.gdb.WithContext(ctx).Create(key).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+	return key, rawKey, nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context, agencyID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	if err := s.gdb.WithContext(ctx).Where("agency_id = ?", agencyID).Order("id").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, keyID uint) error {
+	result := s.gdb.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (s *apiKeyService) VerifyKey(ctx context.Context, rawKey string) (auth.Principal, error) {
+	key, err := s.lookup(ctx, hashSecret(rawKey))
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+
+	if key.RateLimit > 0 {
+		minute := time.Now().Unix() / 60
+		counted, err := s.redisClient.Client.Eval(ctx, constant.CountUpToLimitScript,
+			[]string{fmt.Sprintf(constant.APIKEY_RATE_KEY, key.ID, minute)},
+			key.RateLimit, 60,
+		).Int()
+		if err != nil {
+			return auth.Principal{}, fmt.Errorf("failed to check rate limit: %w", err)
+		}
+		if counted == 0 {
+			return auth.Principal{}, auth.ErrRateLimited
+		}
+	}
+
+	return auth.Principal{
+		Role:         auth.RoleAgency,
+		AgencyID:     key.AgencyID,
+		APIKeyID:     key.ID,
+		Scopes:       key.ScopeList(),
+		BookingQuota: key.DailyBookingQuota,
+	}, nil
+}
+
+func (s *apiKeyService) UseBookingQuota(ctx context.Context, keyID uint, quota int) (func(), error) {
+	if quota == 0 {
+		return func() {}, nil
+	}
+
+	// Quotas reset at midnight UTC
+	now := time.Now().UTC()
+	counterKey := fmt.Sprintf(constant.APIKEY_QUOTA_KEY, keyID, now.Format(time.DateOnly))
+	untilTomorrow := model.Date(now).AddDate(0, 0, 1).Sub(now)
+	counted, err := s.redisClient.Client.Eval(ctx, constant.CountUpToLimitScript,
+		[]string{counterKey}, quota, int(untilTomorrow.Seconds())+1,
+	).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count booking quota: %w", err)
+	}
+	if counted == 0 {
+		return nil, ErrQuotaExceeded
+	}
+
+	return func() {
+		if err := s.redisClient.Client.Eval(context.WithoutCancel(ctx), constant.UncountScript, []string{counterKey}).Err(); err != nil {
+			log.Printf("failed to give back booking quota of API key %d: %v\n", keyID, err)
+		}
+	}, nil
+}
+
+func (s *apiKeyService) CreateAgencyCustomer(ctx context.Context, agencyID uint, customer *model.Customer) error {
+	err := s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.AgencyCustomer{AgencyID: agencyID, CustomerID: customer.ID}).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create agency customer: %w", err)
+	}
+	return nil
+}
+
+func (s *apiKeyService) IsAgencyCustomer(ctx context.Context, agencyID, customerID uint) (bool, error) {
+	var count int64
+	err := s.gdb.WithContext(ctx).Model(&model.AgencyCustomer{}).
+		Where("agency_id = ? AND customer_id = ?", agencyID, customerID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to get agency customer: %w", err)
+	}
+	return count > 0, nil
+}
+
+// lookup returns the API key with a hash, nil if there is none, from the
+// cache while it is fresh.
+func (s *apiKeyService) lookup(ctx context.Context, hash string) (*model.APIKey, error) {
+	s.mu.Lock()
+	cached, ok := s.keys[hash]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.key, nil
+	}
+
+	key := &model.APIKey{}
+	err := s.gdb.WithContext(ctx).Where("key_hash = ?", hash).First(key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		key = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Unknown keys are cached too, so guessing cannot grow the cache without
+	// bound
+	if len(s.keys) >= 10000 {
+		clear(s.keys)
+	}
+	s.keys[hash] = cachedKey{key: key, expires: time.Now().Add(constant.APIKeyCacheTTL)}
+	return key, nil
+}
+
+// hashSecret returns the hex SHA-256 hash API keys and calendar feed tokens
+// are stored as
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,79 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(gdb, rc)
+
+	agency, err := svc.CreateAgency(ctx, gofakeit.Company()+" "+gofakeit.DigitN(6))
+	require.NoError(t, err)
+	_, err = svc.CreateAgency(ctx, agency.Name)
+	require.ErrorIs(t, err, ErrAgencyExists)
+
+	_, _, err = svc.CreateKey(ctx, agency.ID, CreateAPIKeyRequest{Name: "bad", Scopes: []string{"admin"}})
+	require.ErrorIs(t, err, ErrInvalidScope)
+
+	key, rawKey, err := svc.CreateKey(ctx, agency.ID, CreateAPIKeyRequest{
+		Name:              "booking engine",
+		Scopes:            []string{auth.ScopeBooking, auth.ScopeSearch},
+		RateLimit:         2,
+		DailyBookingQuota: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, rawKey[:len(key.Prefix)], key.Prefix)
+
+	principal, err := svc.VerifyKey(ctx, rawKey)
+	require.NoError(t, err)
+	require.Equal(t, agency.ID, principal.AgencyID)
+	require.ElementsMatch(t, []string{auth.ScopeBooking, auth.ScopeSearch}, principal.Scopes)
+	require.Equal(t, 1, principal.BookingQuota)
+	_, err = svc.VerifyKey(ctx, rawKey)
+	require.NoError(t, err)
+	_, err = svc.VerifyKey(ctx, rawKey)
+	require.ErrorIs(t, err, auth.ErrRateLimited)
+
+	_, err = svc.VerifyKey(ctx, "tonx_unknown")
+	require.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+
+	// A booking given back does not count against the quota
+	restore, err := svc.UseBookingQuota(ctx, key.ID, principal.BookingQuota)
+	require.NoError(t, err)
+	restore()
+	_, err = svc.UseBookingQuota(ctx, key.ID, principal.BookingQuota)
+	require.NoError(t, err)
+	_, err = svc.UseBookingQuota(ctx, key.ID, principal.BookingQuota)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// Agencies book for the customers they registered only
+	customer := repository.MockCustomer()
+	require.NoError(t, svc.CreateAgencyCustomer(ctx, agency.ID, customer))
+	own, err := svc.IsAgencyCustomer(ctx, agency.ID, customer.ID)
+	require.NoError(t, err)
+	require.True(t, own)
+	other, err := svc.CreateAgency(ctx, gofakeit.Company()+" "+gofakeit.DigitN(6))
+	require.NoError(t, err)
+	own, err = svc.IsAgencyCustomer(ctx, other.ID, customer.ID)
+	require.NoError(t, err)
+	require.False(t, own)
+	taken := repository.MockCustomer()
+	taken.Email = customer.Email
+	require.ErrorIs(t, svc.CreateAgencyCustomer(ctx, other.ID, taken), ErrEmailTaken)
+
+	require.NoError(t, svc.RevokeKey(ctx, key.ID))
+	require.ErrorIs(t, svc.RevokeKey(ctx, key.ID), ErrAPIKeyNotFound)
+	// Revoked keys are refused by services that did not cache them
+	_, err = NewAPIKeyService(gdb, rc).VerifyKey(ctx, rawKey)
+	require.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+}
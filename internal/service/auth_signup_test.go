@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/auth"
+	"github.com/joremysh/tonx/internal/model"
+)
+
+// racedCustomerRepo finds no customer, like a signup checking before another
+// one with the same email is created, which the unique email then refuses
+type racedCustomerRepo struct{}
+
+func (racedCustomerRepo) Create(*model.Customer) error { return gorm.ErrDuplicatedKey }
+
+func (racedCustomerRepo) Get(uint) (*model.Customer, error) { return nil, gorm.ErrRecordNotFound }
+
+func (racedCustomerRepo) GetByEmail(string) (*model.Customer, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestAuthService_SignupRace(t *testing.T) {
+	issuer, err := auth.NewTokenIssuer(auth.TokenConfig{
+		Secret:     []byte(strings.Repeat("s", 32)),
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	_, _, err = NewAuthService(racedCustomerRepo{}, issuer).Signup(context.Background(), SignupRequest{
+		Name:     "Mei Lin",
+		Email:    "mei@example.com",
+		Phone:    "0912345678",
+		Password: "correct horse battery",
+	})
+	require.ErrorIs(t, err, ErrEmailTaken)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// calendarTokenPrefix starts every calendar feed token
+const calendarTokenPrefix = "cal_"
+
+// CalendarFeedHistory is how long flights stay in calendar feeds after they
+// depart
+const CalendarFeedHistory = 30 * 24 * time.Hour
+
+// CalendarFeed defines the interface for the calendar feeds customers
+// subscribe to. Calendar apps cannot send tokens, so a feed is found by a
+// token of its own in its URL.
+type CalendarFeed interface {
+	// CreateFeed creates the calendar feed of a customer and returns its
+	// token, which is not stored and cannot be shown again. The feed created
+	// before, if any, stops working.
+	CreateFeed(ctx context.Context, customerID uint) (string, error)
+	// DeleteFeed stops the calendar feed of a customer from working
+	DeleteFeed(ctx context.Context, customerID uint) error
+	// FeedOrders returns the customer of a feed token and their orders with
+	// flights departed within CalendarFeedHistory or later, loaded with their
+	// flights and travelers. Cancelled orders are included, so calendars mark
+	// their events cancelled rather than keep them as they were.
+	FeedOrders(ctx context.Context, token string) (*model.Customer, []model.Order, error)
+}
+
+// calendarFeedService implements CalendarFeed
+type calendarFeedService struct {
+	gdb *gorm.DB
+}
+
+// NewCalendarFeedService creates a new instance of CalendarFeed
+func NewCalendarFeedService(gdb *gorm.DB) CalendarFeed {
+	return &calendarFeedService{gdb: gdb}
+}
+
+func (s *calendarFeedService) CreateFeed(ctx context.Context, customerID uint) (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	token := calendarTokenPrefix + hex.EncodeToString(secret)
+	if err := s.setTokenHash(ctx, customerID, hashSecret(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *calendarFeedService) DeleteFeed(ctx context.Context, customerID uint) error {
+	return s.setTokenHash(ctx, customerID, nil)
+}
+
+func (s *calendarFeedService) setTokenHash(ctx context.Context, customerID uint, hash any) error {
+	err := s.gdb.WithContext(ctx).Model(&model.Customer{}).
+		Where("id = ?", customerID).
+		Update("calendar_token_hash", hash).Error
+	if err != nil {
+		return fmt.Errorf("failed to update calendar feed: %w", err)
+	}
+	return nil
+}
+
+func (s *calendarFeedService) FeedOrders(ctx context.Context, token string) (*model.Customer, []model.Order, error) {
+	var customer model.Customer
+	err := s.gdb.WithContext(ctx).Where("calendar_token_hash = ?", hashSecret(token)).First(&customer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCalendarFeedNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	var orders []model.Order
+	err = s.gdb.WithContext(ctx).
+		Preload("Flight").Preload("Travelers").
+		Joins("JOIN flights ON flights.id = orders.flight_id").
+		Where("orders.customer_id = ?", customer.ID).
+		Where("flights.departure_time > ?", time.Now().Add(-CalendarFeedHistory)).
+		Order("flights.departure_time").
+		Find(&orders).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	return &customer, orders, nil
+}
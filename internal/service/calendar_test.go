@@ -0,0 +1,63 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestCalendarFeedService_FeedOrders(t *testing.T) {
+	ctx := context.Background()
+	svc := NewCalendarFeedService(gdb)
+	orderService := NewOrderService(gdb, inventory.NewMemoryInventory(), nil, BookingLimits{})
+
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+	book := func(departure time.Time) uint {
+		order, err := orderService.CreateOrder(ctx, CreateOrderRequest{
+			FlightID:     createFlight(t, departure).ID,
+			CustomerID:   customer.ID,
+			TicketAmount: 1,
+		})
+		require.NoError(t, err)
+		return order.ID
+	}
+	later := book(time.Now().Add(14 * 24 * time.Hour))
+	soon := book(time.Now().Add(24 * time.Hour))
+	cancelled := book(time.Now().Add(48 * time.Hour))
+	_, err = orderService.CancelOrder(ctx, cancelled)
+	require.NoError(t, err)
+	book(time.Now().Add(-CalendarFeedHistory - time.Hour))
+
+	_, _, err = svc.FeedOrders(ctx, "cal_unknown")
+	require.ErrorIs(t, err, ErrCalendarFeedNotFound)
+
+	token, err := svc.CreateFeed(ctx, customer.ID)
+	require.NoError(t, err)
+	feedCustomer, orders, err := svc.FeedOrders(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, customer.ID, feedCustomer.ID)
+	require.Len(t, orders, 3)
+	require.Equal(t, soon, orders[0].ID)
+	require.Equal(t, cancelled, orders[1].ID)
+	require.Equal(t, later, orders[2].ID)
+	require.NotNil(t, orders[0].Flight)
+
+	// A new feed replaces the one before
+	replaced, err := svc.CreateFeed(ctx, customer.ID)
+	require.NoError(t, err)
+	_, _, err = svc.FeedOrders(ctx, token)
+	require.ErrorIs(t, err, ErrCalendarFeedNotFound)
+
+	require.NoError(t, svc.DeleteFeed(ctx, customer.ID))
+	_, _, err = svc.FeedOrders(ctx, replaced)
+	require.ErrorIs(t, err, ErrCalendarFeedNotFound)
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/boarding"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+var (
+	ErrCheckInNotOpen    = errors.New("check-in is not open yet")
+	ErrCheckInClosed     = errors.New("check-in is closed")
+	ErrOrderCancelled    = errors.New("order is cancelled")
+	ErrTravelersNotNamed = errors.New("travelers of the order are not named")
+	ErrTravelerNotFound  = errors.New("traveler not found")
+	ErrInvalidSeat       = errors.New("no such seat on this flight")
+	ErrSeatTaken         = errors.New("seat is taken")
+	ErrFlightFull        = errors.New("every seat of the flight is taken")
+)
+
+// CheckInConfig holds the check-in window, relative to departure
+type CheckInConfig struct {
+	// Opens is how long before departure check-in opens
+	Opens time.Duration
+	// Closes is how long before departure check-in closes
+	Closes time.Duration
+}
+
+// CheckInRequest represents the request for checking in travelers of an
+// order
+type CheckInRequest struct {
+	OrderID uint
+	// Travelers are the travelers to check in, by ID, with the seats they
+	// chose, empty for any seat. Every traveler of the order is checked in
+	// with any seat if there are none.
+	Travelers map[uint]string
+}
+
+// BoardingPass is the boarding pass of a checked in traveler
+type BoardingPass struct {
+	Traveler model.Traveler
+	Flight   model.Flight
+	// BCBP is the pass in the IATA Bar Coded Boarding Pass format
+	BCBP string
+}
+
+// CheckIn defines the interface for online check-in
+type CheckIn interface {
+	// CheckIn checks in travelers of an order within the check-in window,
+	// assigning seats to those who did not choose one. Travelers checked in
+	// before keep their seats. It returns the boarding passes of the
+	// travelers.
+	CheckIn(ctx context.Context, req CheckInRequest) ([]BoardingPass, error)
+}
+
+// checkInService implements CheckIn
+type checkInService struct {
+	gdb *gorm.DB
+	cfg CheckInConfig
+}
+
+// NewCheckInService creates a new instance of CheckIn
+func NewCheckInService(gdb *gorm.DB, cfg CheckInConfig) CheckIn {
+	return &checkInService{
+		gdb: gdb,
+		cfg: cfg,
+	}
+}
+
+func (s *checkInService) CheckIn(ctx context.Context, req CheckInRequest) ([]BoardingPass, error) {
+	var order model.Order
+	if err := s.gdb.WithContext(ctx).First(&order, req.OrderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	var flight model.Flight
+	var passes []BoardingPass
+	err := s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Seats are assigned under the flight lock, like bookings take them
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flight, order.FlightID).Error; err != nil {
+			return fmt.Errorf("failed to lock flight record: %w", err)
+		}
+		if err := tx.First(&order, order.ID).Error; err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+		if order.Status == string(api.OrderStatusCANCELLED) {
+			return ErrOrderCancelled
+		}
+		if err := s.checkWindow(&flight, time.Now()); err != nil {
+			return err
+		}
+
+		var travelers []model.Traveler
+		if err := tx.Where("order_id = ?", order.ID).Order("id").Find(&travelers).Error; err != nil {
+			return fmt.Errorf("failed to get travelers: %w", err)
+		}
+		if len(travelers) == 0 {
+			return ErrTravelersNotNamed
+		}
+		checkingIn, err := selectTravelers(travelers, req.Travelers)
+		if err != nil {
+			return err
+		}
+		if err = assignSeats(tx, &flight, checkingIn); err != nil {
+			return err
+		}
+
+		// Travelers are only checked in with boarding passes, a pass that
+		// cannot be issued rolls the seats back
+		passes = make([]BoardingPass, 0, len(travelers))
+		for _, traveler := range travelers {
+			if _, ok := req.Travelers[traveler.ID]; len(req.Travelers) > 0 && !ok {
+				continue
+			}
+			pass, err := newBoardingPass(&order, &flight, traveler)
+			if err != nil {
+				return err
+			}
+			passes = append(passes, pass)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return passes, nil
+}
+
+// checkWindow returns why a flight cannot be checked in for at now, if it
+// cannot.
+func (s *checkInService) checkWindow(flight *model.Flight, now time.Time) error {
+	switch {
+	case flight.Status == string(api.FlightStatusCANCELLED) || flight.Status == string(api.FlightStatusCOMPLETED):
+		return ErrCheckInClosed
+	case now.Before(flight.DepartureTime.Add(-s.cfg.Opens)):
+		return ErrCheckInNotOpen
+	case !now.Before(flight.DepartureTime.Add(-s.cfg.Closes)):
+		return ErrCheckInClosed
+	}
+	return nil
+}
+
+// selectTravelers returns the travelers to check in, with the seats they
+// chose set. Travelers checked in before are left out.
+func selectTravelers(travelers []model.Traveler, seats map[uint]string) ([]*model.Traveler, error) {
+	for id := range seats {
+		found := false
+		for _, traveler := range travelers {
+			found = found || traveler.ID == id
+		}
+		if !found {
+			return nil, ErrTravelerNotFound
+		}
+	}
+
+	var selected []*model.Traveler
+	for i := range travelers {
+		traveler := &travelers[i]
+		seat, ok := seats[traveler.ID]
+		if (len(seats) > 0 && !ok) || traveler.CheckedInAt != nil {
+			continue
+		}
+		traveler.Seat = strings.ToUpper(strings.TrimSpace(seat))
+		selected = append(selected, traveler)
+	}
+	return selected, nil
+}
+
+// assignSeats checks in travelers on a flight within tx, which holds the
+// flight lock. Travelers who chose a seat get it if it is free, the others
+// get the frontmost free seats.
+func assignSeats(tx *gorm.DB, flight *model.Flight, travelers []*model.Traveler) error {
+	if len(travelers) == 0 {
+		return nil
+	}
+
+	var checkedIn []model.Traveler
+	err := tx.Model(&model.Traveler{}).
+		Select("travelers.seat, travelers.boarding_sequence").
+		Joins("JOIN orders ON orders.id = travelers.order_id").
+		Where("orders.flight_id = ? AND orders.status <> ? AND travelers.checked_in_at IS NOT NULL", flight.ID, api.OrderStatusCANCELLED).
+		Find(&checkedIn).Error
+	if err != nil {
+		return fmt.Errorf("failed to get taken seats: %w", err)
+	}
+	taken := make(map[string]bool, len(checkedIn))
+	sequence := 0
+	for _, traveler := range checkedIn {
+		taken[traveler.Seat] = true
+		sequence = max(sequence, traveler.BoardingSequence)
+	}
+
+	seatMap := boarding.NewSeatMap(flight.TotalSeats)
+	// Chosen seats first, so the others do not get them
+	for _, traveler := range travelers {
+		if traveler.Seat == "" {
+			continue
+		}
+		i, ok := seatMap.Index(traveler.Seat)
+		if !ok {
+			return ErrInvalidSeat
+		}
+		traveler.Seat = seatMap.Seat(i)
+		if taken[traveler.Seat] {
+			return ErrSeatTaken
+		}
+		taken[traveler.Seat] = true
+	}
+
+	now := time.Now()
+	for _, traveler := range travelers {
+		if traveler.Seat == "" {
+			seat, ok := seatMap.Next(taken)
+			if !ok {
+				return ErrFlightFull
+			}
+			traveler.Seat = seat
+			taken[seat] = true
+		}
+		sequence++
+		traveler.CheckedInAt = &now
+		traveler.BoardingSequence = sequence
+		traveler.SeatFlightID = &flight.ID
+		err = tx.Model(traveler).Updates(map[string]any{
+			"seat":              traveler.Seat,
+			"checked_in_at":     now,
+			"boarding_sequence": sequence,
+			"seat_flight_id":    flight.ID,
+		}).Error
+		// The unique seat per flight backs the flight lock up
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrSeatTaken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check in traveler: %w", err)
+		}
+		metrics.Inc("travelers_checked_in")
+	}
+	return nil
+}
+
+// newBoardingPass returns the boarding pass of a checked in traveler
+func newBoardingPass(order *model.Order, flight *model.Flight, traveler model.Traveler) (BoardingPass, error) {
+	from, ok := flight.Origin()
+	if !ok {
+		return BoardingPass{}, fmt.Errorf("no airport code for %s", flight.DepartureCity)
+	}
+	to, ok := flight.Destination()
+	if !ok {
+		return BoardingPass{}, fmt.Errorf("no airport code for %s", flight.ArrivalCity)
+	}
+
+	bcbp, err := boarding.Pass{
+		FirstName:     traveler.FirstName,
+		LastName:      traveler.LastName,
+		RecordLocator: recordLocator(order.OrderNumber),
+		From:          from.Code,
+		To:            to.Code,
+		FlightNumber:  flight.FlightNumber,
+		Date:          flight.DepartureDate,
+		Seat:          traveler.Seat,
+		Sequence:      traveler.BoardingSequence,
+		Status:        boarding.StatusCheckedIn,
+	}.Encode()
+	if err != nil {
+		return BoardingPass{}, err
+	}
+	return BoardingPass{Traveler: traveler, Flight: *flight, BCBP: bcbp}, nil
+}
+
+// recordLocator returns the booking reference printed on boarding passes,
+// the last six characters of the order number in upper case
+func recordLocator(orderNumber string) string {
+	if len(orderNumber) > 6 {
+		orderNumber = orderNumber[len(orderNumber)-6:]
+	}
+	return strings.ToUpper(orderNumber)
+}
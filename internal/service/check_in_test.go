@@ -0,0 +1,112 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestCheckInService_CheckIn(t *testing.T) {
+	ctx := context.Background()
+	svc := NewCheckInService(gdb, CheckInConfig{Opens: 24 * time.Hour, Closes: time.Hour})
+	orderService := NewOrderService(gdb, inventory.NewMemoryInventory(), nil, BookingLimits{})
+
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+	book := func(flight *model.Flight, travelers ...TravelerName) *model.Order {
+		order, err := orderService.CreateOrder(ctx, CreateOrderRequest{
+			FlightID:     flight.ID,
+			CustomerID:   customer.ID,
+			TicketAmount: max(len(travelers), 1),
+			Travelers:    travelers,
+		})
+		require.NoError(t, err)
+		return order
+	}
+	mei, wei := TravelerName{FirstName: "Mei", LastName: "Lin"}, TravelerName{FirstName: "Wei", LastName: "Chen"}
+
+	later := book(createFlight(t, time.Now().Add(48*time.Hour)), mei)
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: later.ID})
+	require.ErrorIs(t, err, ErrCheckInNotOpen)
+
+	closing := book(createFlight(t, time.Now().Add(30*time.Minute)), mei)
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: closing.ID})
+	require.ErrorIs(t, err, ErrCheckInClosed)
+
+	flight := createFlight(t, time.Now().Add(6*time.Hour))
+	unnamed := book(flight)
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: unnamed.ID})
+	require.ErrorIs(t, err, ErrTravelersNotNamed)
+
+	order := book(flight, mei, wei)
+	first, second := order.Travelers[0].ID, order.Travelers[1].ID
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: order.ID, Travelers: map[uint]string{first: "99Z"}})
+	require.ErrorIs(t, err, ErrInvalidSeat)
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: order.ID, Travelers: map[uint]string{0: ""}})
+	require.ErrorIs(t, err, ErrTravelerNotFound)
+
+	// A traveler of another order has the chosen seat
+	other := book(flight, TravelerName{FirstName: "Yu", LastName: "Wang"})
+	passes, err := svc.CheckIn(ctx, CheckInRequest{OrderID: other.ID, Travelers: map[uint]string{other.Travelers[0].ID: "3c"}})
+	require.NoError(t, err)
+	require.Len(t, passes, 1)
+	require.Equal(t, "3C", passes[0].Traveler.Seat)
+	require.Equal(t, 1, passes[0].Traveler.BoardingSequence)
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: order.ID, Travelers: map[uint]string{first: "3C"}})
+	require.ErrorIs(t, err, ErrSeatTaken)
+
+	passes, err = svc.CheckIn(ctx, CheckInRequest{OrderID: order.ID, Travelers: map[uint]string{first: "1A"}})
+	require.NoError(t, err)
+	require.Len(t, passes, 1)
+	require.Equal(t, "1A", passes[0].Traveler.Seat)
+	require.Contains(t, passes[0].BCBP, "TPENRT")
+	require.Contains(t, passes[0].BCBP, "001A")
+
+	// The rest of the order gets the next free seat, and checking in again
+	// keeps the seats
+	passes, err = svc.CheckIn(ctx, CheckInRequest{OrderID: order.ID})
+	require.NoError(t, err)
+	require.Len(t, passes, 2)
+	require.Equal(t, "1A", passes[0].Traveler.Seat)
+	require.Equal(t, "1B", passes[1].Traveler.Seat)
+	require.Equal(t, 3, passes[1].Traveler.BoardingSequence)
+
+	again, err := svc.CheckIn(ctx, CheckInRequest{OrderID: order.ID, Travelers: map[uint]string{second: "5A"}})
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	require.Equal(t, "1B", again[0].Traveler.Seat)
+
+	// The database keeps a seat from being taken twice, and frees the seats
+	// of cancelled orders
+	err = gdb.Model(&model.Traveler{}).Where("id = ?", other.Travelers[0].ID).Update("seat", "1A").Error
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	_, err = orderService.CancelOrder(ctx, other.ID)
+	require.NoError(t, err)
+	late := book(flight, TravelerName{FirstName: "Hao", LastName: "Li"})
+	passes, err = svc.CheckIn(ctx, CheckInRequest{OrderID: late.ID, Travelers: map[uint]string{late.Travelers[0].ID: "3C"}})
+	require.NoError(t, err)
+	require.Equal(t, "3C", passes[0].Traveler.Seat)
+
+	// Travelers are not checked in without a boarding pass, which needs the
+	// airport codes of the flight
+	unknown := createFlight(t, time.Now().Add(6*time.Hour))
+	err = gdb.Model(unknown).Updates(map[string]any{"departure_city": "Atlantis", "departure_airport": ""}).Error
+	require.NoError(t, err)
+	stranded := book(unknown, mei)
+	_, err = svc.CheckIn(ctx, CheckInRequest{OrderID: stranded.ID})
+	require.ErrorContains(t, err, "no airport code for Atlantis")
+	traveler := &model.Traveler{}
+	require.NoError(t, gdb.First(traveler, stranded.Travelers[0].ID).Error)
+	require.Nil(t, traveler.CheckedInAt)
+	require.Empty(t, traveler.Seat)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// retryPolicy holds how sending a queued row, a webhook delivery or a
+// notification, is retried
+type retryPolicy struct {
+	// MaxAttempts is the number of attempts after which the row fails
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for every further
+	// one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Outcomes of an attempt at sending a queued row
+const (
+	attemptSucceeded = iota
+	attemptRetried
+	attemptFailed
+)
+
+// claimDue takes up to limit rows of T with a pending status whose
+// next_attempt_at is due, and loads them with the preloaded associations.
+// They are pushed back by hold while they are sent, so other dispatchers
+// leave them alone, and are retried then if the dispatcher sending them dies.
+func claimDue[T any](ctx context.Context, gdb *gorm.DB, pending string, limit int, hold time.Duration, preloads ...string) ([]T, error) {
+	var ids []uint
+	err := gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(new(T)).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", pending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return tx.Model(new(T)).Where("id IN ?", ids).Update("next_attempt_at", now.Add(hold)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim rows: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// Only the claimed rows are loaded, the others are not ours to send
+	query := gdb.WithContext(ctx)
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+	var rows []T
+	if err = query.Where("id IN ?", ids).Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get claimed rows: %w", err)
+	}
+	return rows, nil
+}
+
+// attemptUpdates returns the columns recording an attempt at sending a
+// queued row that was attempted attempts times before and ended with err,
+// and the outcome. The status of the row is left to the caller.
+func attemptUpdates(policy retryPolicy, attempts int, err error) (map[string]any, int) {
+	updates := map[string]any{
+		"attempts":   attempts + 1,
+		"last_error": "",
+	}
+	switch {
+	case err == nil:
+		return updates, attemptSucceeded
+	case attempts+1 >= policy.MaxAttempts:
+		updates["last_error"] = truncate(err.Error(), 500)
+		return updates, attemptFailed
+	default:
+		updates["next_attempt_at"] = time.Now().Add(backoff(policy.Backoff, policy.MaxBackoff, attempts+1))
+		updates["last_error"] = truncate(err.Error(), 500)
+		return updates, attemptRetried
+	}
+}
+
+// backoff returns the delay before the retry following attempt, starting at
+// base and doubling up to limit.
+func backoff(base, limit time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, backoff(time.Second, time.Minute, 1))
+	require.Equal(t, 4*time.Second, backoff(time.Second, time.Minute, 3))
+	require.Equal(t, time.Minute, backoff(time.Second, time.Minute, 30))
+}
+
+func TestAttemptUpdates(t *testing.T) {
+	policy := retryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour}
+
+	updates, outcome := attemptUpdates(policy, 0, nil)
+	require.Equal(t, attemptSucceeded, outcome)
+	require.Equal(t, 1, updates["attempts"])
+	require.Empty(t, updates["last_error"])
+
+	updates, outcome = attemptUpdates(policy, 1, errors.New("down"))
+	require.Equal(t, attemptRetried, outcome)
+	require.Equal(t, "down", updates["last_error"])
+	next, ok := updates["next_attempt_at"].(time.Time)
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(2*time.Minute), next, time.Second)
+
+	updates, outcome = attemptUpdates(policy, 2, errors.New("down"))
+	require.Equal(t, attemptFailed, outcome)
+	require.Equal(t, 3, updates["attempts"])
+	require.NotContains(t, updates, "next_attempt_at")
+}
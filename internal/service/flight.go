@@ -2,15 +2,47 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/constant"
 	"github.com/joremysh/tonx/internal/model"
 	"github.com/joremysh/tonx/internal/repository"
 	"github.com/joremysh/tonx/pkg/cache"
+	"github.com/joremysh/tonx/pkg/search"
+)
+
+var ErrInvalidSuggestionKind = errors.New("invalid suggestion kind")
+
+const (
+	SuggestionKindCity    = "city"
+	SuggestionKindAirline = "airline"
 )
 
 type Flight interface {
 	ListFlights(ctx context.Context, params *model.ListParams, departureDate *time.Time) (*PaginatedResult[model.Flight], error)
+	// Suggest returns autocomplete suggestions of the given kind for query,
+	// tolerating accents, case and small typos.
+	Suggest(ctx context.Context, query, kind string, limit int) ([]search.Match, error)
+	// WatchAvailability returns the seats left on a flight and a channel of
+	// later changes, which is closed once ctx is done.
+	WatchAvailability(ctx context.Context, flightID uint) (int, <-chan int, error)
+}
+
+// suggestionColumns maps each suggestion kind to the flight columns its
+// candidates are read from.
+var suggestionColumns = map[string][]string{
+	SuggestionKindCity:    {"departure_city", "arrival_city"},
+	SuggestionKindAirline: {"airline"},
 }
 
 type PaginatedResult[T any] struct {
@@ -18,6 +50,7 @@ type PaginatedResult[T any] struct {
 	TotalCount int64
 	Page       int
 	PageSize   int
+	NextCursor string
 }
 
 func NewFlightService(repo repository.Flight, redisClient *cache.RedisClient) Flight {
@@ -32,15 +65,205 @@ type flightService struct {
 	redisClient *cache.RedisClient
 }
 
+// ListFlights serves search results from a short-lived Redis cache when
+// possible. Cached pages may be up to FlightSearchCacheTTL old and the seat
+// column lags behind queued orders, so the seat counts are always replaced by
+// the live per-flight Redis counters.
 func (f *flightService) ListFlights(ctx context.Context, params *model.ListParams, departureDate *time.Time) (*PaginatedResult[model.Flight], error) {
-	results, totalCount, err := f.repo.List(params, departureDate)
+	key, err := searchCacheKey(params, departureDate)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &PaginatedResult[model.Flight]{}
+	err = f.redisClient.Get(ctx, key, cached)
+	if err == nil {
+		if err = f.overlayAvailableSeats(ctx, cached.Data); err != nil {
+			log.Printf("failed to overlay available seats on cached search: %v\n", err)
+		}
+		return cached, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		log.Printf("failed to read search cache: %v\n", err)
+	}
+
+	results, totalCount, next, err := f.repo.List(params, departureDate)
 	if err != nil {
 		return nil, err
 	}
-	return &PaginatedResult[model.Flight]{
+	result := &PaginatedResult[model.Flight]{
 		Data:       results,
 		TotalCount: totalCount,
 		Page:       params.Page,
 		PageSize:   params.PageSize,
-	}, nil
+		NextCursor: next,
+	}
+
+	if err = f.redisClient.Set(ctx, key, result, constant.FlightSearchCacheTTL); err != nil {
+		log.Printf("failed to write search cache: %v\n", err)
+	}
+	// The seat column lags behind the counters while queued orders wait, so
+	// fresh results are overlaid too, after caching them as they are.
+	if err = f.overlayAvailableSeats(ctx, result.Data); err != nil {
+		log.Printf("failed to overlay available seats on search: %v\n", err)
+	}
+	return result, nil
+}
+
+func (f *flightService) Suggest(ctx context.Context, query, kind string, limit int) ([]search.Match, error) {
+	columns, ok := suggestionColumns[kind]
+	if !ok {
+		return nil, ErrInvalidSuggestionKind
+	}
+
+	// The candidate lists are small and change rarely, so they are cached
+	// whole and matched in memory.
+	key := fmt.Sprintf(constant.FLIGHT_SUGGEST_KEY, kind)
+	var candidates []string
+	err := f.redisClient.Get(ctx, key, &candidates)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("failed to read suggestion cache: %v\n", err)
+		}
+		year, month, day := time.Now().Date()
+		candidates, err = f.repo.DistinctValues(time.Date(year, month, day, 0, 0, 0, 0, time.UTC), columns...)
+		if err != nil {
+			return nil, err
+		}
+		if err = f.redisClient.Set(ctx, key, candidates, constant.FlightSuggestCacheTTL); err != nil {
+			log.Printf("failed to write suggestion cache: %v\n", err)
+		}
+	}
+
+	return search.Suggest(query, candidates, limit), nil
+}
+
+func (f *flightService) WatchAvailability(ctx context.Context, flightID uint) (int, <-chan int, error) {
+	flight, err := f.repo.Get(flightID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil, ErrFlightNotFound
+		}
+		return 0, nil, err
+	}
+
+	// Subscribe before reading the current value, so no change is missed in
+	// between.
+	pubsub := f.redisClient.Client.Subscribe(ctx, flight.AvailabilityChannel())
+	if _, err = pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return 0, nil, err
+	}
+
+	current := []model.Flight{*flight}
+	if err = f.overlayAvailableSeats(ctx, current); err != nil {
+		_ = pubsub.Close()
+		return 0, nil, err
+	}
+
+	updates := make(chan int)
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				seats, err := strconv.Atoi(msg.Payload)
+				if err != nil {
+					log.Printf("invalid availability message on %s: %v\n", msg.Channel, err)
+					continue
+				}
+				select {
+				case updates <- seats:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return current[0].AvailableSeats, updates, nil
+}
+
+// overlayAvailableSeats replaces the available seats of flights with the
+// values of their Redis counters. Flights without a counter are left as is.
+func (f *flightService) overlayAvailableSeats(ctx context.Context, flights []model.Flight) error {
+	if len(flights) == 0 {
+		return nil
+	}
+	keys := make([]string, len(flights))
+	for i, flight := range flights {
+		keys[i] = flight.FlightKey()
+	}
+
+	// The counters live in different cluster slots, which MGET refuses. A
+	// pipeline is split by slot instead.
+	pipe := f.redisClient.Client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	for i, cmd := range cmds {
+		seats, err := cmd.Int()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid seat counter %s: %w", keys[i], err)
+		}
+		flights[i].AvailableSeats = seats
+	}
+	return nil
+}
+
+// searchCacheKey derives the cache key of a search from a normalized form of
+// its parameters, so equivalent searches share a cache entry.
+func searchCacheKey(params *model.ListParams, departureDate *time.Time) (string, error) {
+	// Dates are UTC, like the repository searches from, so servers in other
+	// time zones share the entries
+	date := time.Now().UTC().Format(time.DateOnly)
+	if departureDate != nil {
+		date = departureDate.UTC().Format(time.DateOnly)
+	}
+	sortOrder := "asc"
+	if params.SortOrder == "desc" {
+		sortOrder = "desc"
+	}
+
+	// Maps are marshalled with sorted keys, so the filter order does not matter.
+	normalized, err := json.Marshal(struct {
+		DepartureDate string            `json:"d"`
+		Page          int               `json:"p"`
+		PageSize      int               `json:"ps"`
+		SortBy        string            `json:"sb"`
+		SortOrder     string            `json:"so"`
+		Filters       map[string]string `json:"f"`
+		Match         string            `json:"m"`
+		Cursor        string            `json:"c"`
+		SkipCount     bool              `json:"sc"`
+	}{
+		DepartureDate: date,
+		Page:          params.Page,
+		PageSize:      params.PageSize,
+		SortBy:        params.SortBy,
+		SortOrder:     sortOrder,
+		Filters:       params.Filters,
+		Match:         params.Match,
+		Cursor:        params.Cursor,
+		SkipCount:     params.SkipCount,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(normalized)
+	return fmt.Sprintf(constant.FLIGHT_SEARCH_KEY, hex.EncodeToString(sum[:])), nil
 }
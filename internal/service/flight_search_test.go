@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+func TestSearchCacheKey(t *testing.T) {
+	params := &model.ListParams{Page: 1, PageSize: 10}
+	date := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	key, err := searchCacheKey(params, &date)
+	require.NoError(t, err)
+
+	// The same instant in the time zone of a server elsewhere
+	taipei := date.In(time.FixedZone("CST", 8*3600))
+	same, err := searchCacheKey(params, &taipei)
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	nextDay := date.AddDate(0, 0, 1)
+	other, err := searchCacheKey(params, &nextDay)
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
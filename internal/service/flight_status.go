@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/notify"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+var (
+	ErrFlightClosed       = errors.New("flight is cancelled or completed already")
+	ErrInvalidFlightTimes = errors.New("arrival must be after departure")
+	ErrNoFlightChange     = errors.New("flight has this status and times already")
+)
+
+// Kinds of order entitlements
+const (
+	// EntitlementRefund lets the customer cancel the booking for a full
+	// refund, even once a cancelled flight was due to depart
+	EntitlementRefund = "REFUND"
+)
+
+// FlightStatusConfig holds when flight changes entitle orders to a refund
+type FlightStatusConfig struct {
+	// BigDelay is how much later than first scheduled a flight must depart
+	// for its orders to be entitled, 0 for cancellations only
+	BigDelay time.Duration
+}
+
+// UpdateFlightStatusRequest represents a change of the status and times of
+// a flight
+type UpdateFlightStatusRequest struct {
+	FlightID uint
+	Status   string
+	// DepartureTime and ArrivalTime are the new times, nil to keep them. The
+	// arrival moves along with the departure when only that is given.
+	DepartureTime *time.Time
+	ArrivalTime   *time.Time
+	// Reason is told to customers
+	Reason string
+	// ChangedBy is the admin making the change
+	ChangedBy *uint
+}
+
+// FlightStatus defines the interface for operating changes to flights
+type FlightStatus interface {
+	// UpdateStatus changes the status and times of a flight and records the
+	// change in its history. Customers with an active order on it are
+	// notified of new times, delays and cancellations, and their orders are
+	// entitled to a refund when the flight is cancelled, or
+	// departs BigDelay or more later than first scheduled. The seats of
+	// cancelled and completed flights are taken off sale.
+	UpdateStatus(ctx context.Context, req UpdateFlightStatusRequest) (*model.FlightStatusHistory, error)
+	// History returns the changes of a flight, oldest first
+	History(ctx context.Context, flightID uint) ([]model.FlightStatusHistory, error)
+}
+
+// flightStatusService implements FlightStatus
+type flightStatusService struct {
+	gdb   *gorm.DB
+	seats inventory.SeatInventory
+	cfg   FlightStatusConfig
+}
+
+// NewFlightStatusService creates a new instance of FlightStatus
+func NewFlightStatusService(gdb *gorm.DB, seats inventory.SeatInventory, cfg FlightStatusConfig) FlightStatus {
+	return &flightStatusService{
+		gdb:   gdb,
+		seats: seats,
+		cfg:   cfg,
+	}
+}
+
+func (s *flightStatusService) UpdateStatus(ctx context.Context, req UpdateFlightStatusRequest) (*model.FlightStatusHistory, error) {
+	var history *model.FlightStatusHistory
+	err := s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bookings and cancellations of the flight wait for the change, so
+		// every order on it is notified
+		var flight model.Flight
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flight, req.FlightID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFlightNotFound
+			}
+			return fmt.Errorf("failed to lock flight record: %w", err)
+		}
+		if closedStatus(flight.Status) {
+			return ErrFlightClosed
+		}
+
+		departure, arrival := flight.DepartureTime, flight.ArrivalTime
+		if req.DepartureTime != nil {
+			arrival = arrival.Add(req.DepartureTime.Sub(departure))
+			departure = *req.DepartureTime
+		}
+		if req.ArrivalTime != nil {
+			arrival = *req.ArrivalTime
+		}
+		if !arrival.After(departure) {
+			return ErrInvalidFlightTimes
+		}
+		if req.Status == flight.Status && departure.Equal(flight.DepartureTime) && arrival.Equal(flight.ArrivalTime) {
+			return ErrNoFlightChange
+		}
+
+		scheduled, err := scheduledDeparture(tx, &flight)
+		if err != nil {
+			return err
+		}
+		history = &model.FlightStatusHistory{
+			FlightID:              flight.ID,
+			PreviousStatus:        flight.Status,
+			Status:                req.Status,
+			PreviousDepartureTime: flight.DepartureTime,
+			PreviousArrivalTime:   flight.ArrivalTime,
+			DepartureTime:         departure,
+			ArrivalTime:           arrival,
+			Reason:                req.Reason,
+			ChangedBy:             req.ChangedBy,
+		}
+		if err = tx.Create(history).Error; err != nil {
+			return fmt.Errorf("failed to record flight change: %w", err)
+		}
+		err = tx.Model(&flight).Updates(map[string]any{
+			"status":         req.Status,
+			"departure_time": departure,
+			"departure_date": flight.LocalDepartureDate(departure),
+			"arrival_time":   arrival,
+			"revision":       gorm.Expr("revision + 1"),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update flight: %w", err)
+		}
+
+		// Flights taking off or landing are no news to their passengers
+		if !notifies(history) {
+			return recordEvent(tx, EventFlightChanged, flight.ID, newFlightChangedEvent(&flight, history))
+		}
+		var orders []model.Order
+		err = tx.Where("flight_id = ? AND status IN ?", flight.ID, []string{string(api.OrderStatusPENDING), string(api.OrderStatusCONFIRMED)}).
+			Order("id").
+			Find(&orders).Error
+		if err != nil {
+			return fmt.Errorf("failed to get orders of flight: %w", err)
+		}
+		entitled, reason := s.entitled(&flight, history, scheduled)
+		for _, order := range orders {
+			if entitled {
+				granted, err := grantEntitlement(tx, order.ID, history.ID, reason)
+				if err != nil {
+					return err
+				}
+				if granted {
+					history.EntitlementsGranted++
+				}
+			}
+			err = queueNotification(tx, notify.KindFlightChanged, order.ID, notify.FlightChange{
+				PreviousStatus:    history.PreviousStatus,
+				PreviousDeparture: history.PreviousDepartureTime,
+				PreviousArrival:   history.PreviousArrivalTime,
+				Reason:            history.Reason,
+				Entitled:          entitled,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		history.AffectedOrders = len(orders)
+		err = tx.Model(history).Updates(map[string]any{
+			"affected_orders":      history.AffectedOrders,
+			"entitlements_granted": history.EntitlementsGranted,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to record flight change: %w", err)
+		}
+		return recordEvent(tx, EventFlightChanged, flight.ID, newFlightChangedEvent(&flight, history))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if closedStatus(history.Status) {
+		// The database turns bookings of the flight down either way
+		if err = s.seats.CloseFlight(ctx, history.FlightID); err != nil {
+			log.Printf("failed to close seats of flight %d: %v\n", history.FlightID, err)
+		}
+	}
+
+	metrics.Inc("flight_changes")
+	log.Printf("flight %d changed from %s to %s, notifying %d orders\n", history.FlightID, history.PreviousStatus, history.Status, history.AffectedOrders)
+	return history, nil
+}
+
+// notifies reports whether customers are told about a change: new times,
+// delays and cancellations.
+func notifies(history *model.FlightStatusHistory) bool {
+	switch {
+	case history.Status == string(api.FlightStatusDELAYED), history.Status == string(api.FlightStatusCANCELLED):
+		return true
+	default:
+		return !history.DepartureTime.Equal(history.PreviousDepartureTime) || !history.ArrivalTime.Equal(history.PreviousArrivalTime)
+	}
+}
+
+// closedStatus reports whether a flight with status can no longer be booked.
+func closedStatus(status string) bool {
+	return status == string(api.FlightStatusCANCELLED) || status == string(api.FlightStatusCOMPLETED)
+}
+
+// entitled reports whether a change of a flight first scheduled to depart
+// at scheduled entitles its orders to a refund, and why.
+func (s *flightStatusService) entitled(flight *model.Flight, history *model.FlightStatusHistory, scheduled time.Time) (bool, string) {
+	if history.Status == string(api.FlightStatusCANCELLED) {
+		return true, fmt.Sprintf("flight %s was cancelled", flight.FlightNumber)
+	}
+	delay := history.DepartureTime.Sub(scheduled)
+	if s.cfg.BigDelay > 0 && delay >= s.cfg.BigDelay {
+		return true, fmt.Sprintf("flight %s departs %s late", flight.FlightNumber, delay.Round(time.Minute))
+	}
+	return false, ""
+}
+
+// scheduledDeparture returns when a flight was first scheduled to depart,
+// before any of its changes, so several small delays add up
+func scheduledDeparture(tx *gorm.DB, flight *model.Flight) (time.Time, error) {
+	var first model.FlightStatusHistory
+	if err := tx.Where("flight_id = ?", flight.ID).Order("id").Limit(1).Find(&first).Error; err != nil {
+		return time.Time{}, fmt.Errorf("failed to get flight history: %w", err)
+	}
+	if first.ID == 0 {
+		return flight.DepartureTime, nil
+	}
+	return first.PreviousDepartureTime, nil
+}
+
+// grantEntitlement grants a refund entitlement to an order within
+// tx, and reports false if the order had one already
+func grantEntitlement(tx *gorm.DB, orderID, historyID uint, reason string) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.OrderEntitlement{
+		OrderID:               orderID,
+		Kind:                  EntitlementRefund,
+		FlightStatusHistoryID: historyID,
+		Reason:                reason,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to grant entitlement: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *flightStatusService) History(ctx context.Context, flightID uint) ([]model.FlightStatusHistory, error) {
+	if err := s.gdb.WithContext(ctx).Select("id").First(&model.Flight{}, flightID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+
+	var history []model.FlightStatusHistory
+	if err := s.gdb.WithContext(ctx).Where("flight_id = ?", flightID).Order("id").Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get flight history: %w", err)
+	}
+	return history, nil
+}
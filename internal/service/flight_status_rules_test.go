@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+func TestFlightStatusService_Entitled(t *testing.T) {
+	svc := &flightStatusService{cfg: FlightStatusConfig{BigDelay: 3 * time.Hour}}
+	scheduled := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	flight := &model.Flight{FlightNumber: "TX1234"}
+	change := func(status string, delay time.Duration) *model.FlightStatusHistory {
+		return &model.FlightStatusHistory{Status: status, DepartureTime: scheduled.Add(delay)}
+	}
+
+	entitled, _ := svc.entitled(flight, change("DELAYED", 2*time.Hour), scheduled)
+	require.False(t, entitled)
+	entitled, reason := svc.entitled(flight, change("DELAYED", 3*time.Hour), scheduled)
+	require.True(t, entitled)
+	require.Equal(t, "flight TX1234 departs 3h0m0s late", reason)
+	entitled, reason = svc.entitled(flight, change("CANCELLED", 0), scheduled)
+	require.True(t, entitled)
+	require.Equal(t, "flight TX1234 was cancelled", reason)
+
+	svc.cfg.BigDelay = 0
+	entitled, _ = svc.entitled(flight, change("DELAYED", 24*time.Hour), scheduled)
+	require.False(t, entitled)
+}
+
+func TestNotifies(t *testing.T) {
+	departure := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	change := func(status string, delay time.Duration) *model.FlightStatusHistory {
+		return &model.FlightStatusHistory{
+			Status:                status,
+			PreviousDepartureTime: departure,
+			PreviousArrivalTime:   departure.Add(3 * time.Hour),
+			DepartureTime:         departure.Add(delay),
+			ArrivalTime:           departure.Add(3*time.Hour + delay),
+		}
+	}
+
+	require.True(t, notifies(change("DELAYED", 0)))
+	require.True(t, notifies(change("CANCELLED", 0)))
+	require.True(t, notifies(change("SCHEDULED", -time.Hour)))
+	require.False(t, notifies(change("IN_PROGRESS", 0)))
+	require.False(t, notifies(change("COMPLETED", 0)))
+	require.True(t, notifies(change("IN_PROGRESS", 30*time.Minute)))
+}
@@ -0,0 +1,166 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/notify"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestFlightStatusService_UpdateStatus(t *testing.T) {
+	ctx := context.Background()
+	seats := inventory.NewMemoryInventory()
+	svc := NewFlightStatusService(gdb, seats, FlightStatusConfig{BigDelay: 3 * time.Hour})
+	orderService := NewOrderService(gdb, seats, nil, BookingLimits{})
+
+	// Flight times are stored in whole seconds
+	flight := createFlight(t, time.Now().Add(48*time.Hour).Truncate(time.Minute))
+	scheduled := flight.DepartureTime
+	orders := make([]*model.Order, 3)
+	for i := range orders {
+		customer := repository.MockCustomer()
+		err = gdb.Create(customer).Error
+		require.NoError(t, err)
+		orders[i], err = orderService.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1})
+		require.NoError(t, err)
+	}
+	_, err = orderService.CancelOrder(ctx, orders[2].ID)
+	require.NoError(t, err)
+
+	_, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: 0, Status: "DELAYED"})
+	require.ErrorIs(t, err, ErrFlightNotFound)
+	_, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "SCHEDULED"})
+	require.ErrorIs(t, err, ErrNoFlightChange)
+	earlyArrival := scheduled.Add(-time.Hour)
+	_, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "DELAYED", ArrivalTime: &earlyArrival})
+	require.ErrorIs(t, err, ErrInvalidFlightTimes)
+
+	// A small delay notifies the active orders only
+	departure := scheduled.Add(2 * time.Hour)
+	history, err := svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "DELAYED", DepartureTime: &departure, Reason: "Late arrival of the aircraft"})
+	require.NoError(t, err)
+	require.Equal(t, "SCHEDULED", history.PreviousStatus)
+	require.True(t, history.PreviousDepartureTime.Equal(scheduled))
+	require.True(t, history.ArrivalTime.Equal(flight.ArrivalTime.Add(2*time.Hour)))
+	require.Equal(t, 2, history.AffectedOrders)
+	require.Zero(t, history.EntitlementsGranted)
+
+	check := &model.Flight{}
+	require.NoError(t, gdb.First(check, flight.ID).Error)
+	require.Equal(t, "DELAYED", check.Status)
+	require.True(t, check.DepartureTime.Equal(departure))
+	require.Equal(t, check.LocalDepartureDate(departure), check.DepartureDate)
+	require.Equal(t, flight.Revision+1, check.Revision)
+
+	var notifications []model.Notification
+	err = gdb.Where("order_id IN ? AND kind = ?", []uint{orders[0].ID, orders[1].ID, orders[2].ID}, notify.KindFlightChanged).Find(&notifications).Error
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	var change notify.FlightChange
+	require.NoError(t, json.Unmarshal([]byte(notifications[0].Payload), &change))
+	require.Equal(t, "Late arrival of the aircraft", change.Reason)
+	require.False(t, change.Entitled)
+	require.True(t, change.PreviousDeparture.Equal(scheduled))
+
+	// Delays add up against the first schedule
+	departure = scheduled.Add(3 * time.Hour)
+	history, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "DELAYED", DepartureTime: &departure})
+	require.NoError(t, err)
+	require.Equal(t, 2, history.EntitlementsGranted)
+	departure = scheduled.Add(5 * time.Hour)
+	history, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "DELAYED", DepartureTime: &departure})
+	require.NoError(t, err)
+	require.Equal(t, 2, history.AffectedOrders)
+	require.Zero(t, history.EntitlementsGranted)
+
+	order, err := orderService.GetOrder(ctx, orders[0].ID)
+	require.NoError(t, err)
+	require.Len(t, order.Entitlements, 1)
+	require.Equal(t, EntitlementRefund, order.Entitlements[0].Kind)
+	require.Nil(t, order.Entitlements[0].UsedAt)
+
+	// Cancelling the order uses the entitlement for the refund
+	cancelled, err := orderService.CancelOrder(ctx, orders[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, cancelled.Entitlements[0].UsedAt)
+
+	// Cancelled flights can no longer be booked
+	_, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "CANCELLED"})
+	require.NoError(t, err)
+	_, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "SCHEDULED"})
+	require.ErrorIs(t, err, ErrFlightClosed)
+	available, err := seats.Get(ctx, flight.ID)
+	require.NoError(t, err)
+	require.Zero(t, available)
+	_, err = createOrderTx(gdb, CreateOrderRequest{FlightID: flight.ID, CustomerID: orders[1].CustomerID, TicketAmount: 1}, BookingLimits{}, generateOrderNumber("TST"))
+	require.ErrorIs(t, err, ErrFlightClosed)
+
+	// Refunds of the cancelled flight are claimed after it was due to depart,
+	// and its seats stay off sale
+	err = gdb.Model(&model.Flight{}).Where("id = ?", flight.ID).Update("departure_time", time.Now().Add(-time.Hour)).Error
+	require.NoError(t, err)
+	_, err = orderService.CancelOrder(ctx, orders[1].ID)
+	require.NoError(t, err)
+	available, err = seats.Get(ctx, flight.ID)
+	require.NoError(t, err)
+	require.Zero(t, available)
+
+	changes, err := svc.History(ctx, flight.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	require.Equal(t, "CANCELLED", changes[3].Status)
+	require.Equal(t, 1, changes[3].AffectedOrders)
+
+	var events int64
+	err = gdb.Model(&model.OutboxEvent{}).Where("type = ? AND aggregate_id = ?", EventFlightChanged, flight.ID).Count(&events).Error
+	require.NoError(t, err)
+	require.EqualValues(t, 4, events)
+}
+
+func TestFlightStatusService_UpdateStatus_Operations(t *testing.T) {
+	ctx := context.Background()
+	seats := inventory.NewMemoryInventory()
+	svc := NewFlightStatusService(gdb, seats, FlightStatusConfig{BigDelay: 3 * time.Hour})
+	orderService := NewOrderService(gdb, seats, nil, BookingLimits{})
+
+	flight := createFlight(t, time.Now().Add(time.Hour).Truncate(time.Minute))
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+	order, err := orderService.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1})
+	require.NoError(t, err)
+
+	// Taking off and landing on time are not told to customers
+	history, err := svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "IN_PROGRESS"})
+	require.NoError(t, err)
+	require.Zero(t, history.AffectedOrders)
+	history, err = svc.UpdateStatus(ctx, UpdateFlightStatusRequest{FlightID: flight.ID, Status: "COMPLETED"})
+	require.NoError(t, err)
+	require.Zero(t, history.AffectedOrders)
+
+	var notifications int64
+	err = gdb.Model(&model.Notification{}).Where("order_id = ? AND kind = ?", order.ID, notify.KindFlightChanged).Count(&notifications).Error
+	require.NoError(t, err)
+	require.Zero(t, notifications)
+
+	// Completed flights can no longer be booked
+	_, err = orderService.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1})
+	require.ErrorIs(t, err, ErrNoAvailableSeats)
+	_, err = createOrderTx(gdb, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1}, BookingLimits{}, generateOrderNumber("TST"))
+	require.ErrorIs(t, err, ErrFlightClosed)
+
+	// Orders without a refund are not cancellable once the flight departed
+	err = gdb.Model(&model.Flight{}).Where("id = ?", flight.ID).Update("departure_time", time.Now().Add(-time.Hour)).Error
+	require.NoError(t, err)
+	_, err = orderService.CancelOrder(ctx, order.ID)
+	require.ErrorIs(t, err, ErrOrderNotCancellable)
+}
@@ -0,0 +1,48 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestFlightService_ListFlightsCached(t *testing.T) {
+	svc := NewFlightService(repository.NewFlightRepo(gdb), rc)
+	ctx := context.Background()
+
+	flight := repository.MockFlight()
+	flight.DepartureTime = time.Now().Add(24 * time.Hour)
+	flight.ArrivalTime = flight.DepartureTime.Add(2 * time.Hour)
+	err = gdb.Create(flight).Error
+	require.NoError(t, err)
+
+	params := &model.ListParams{
+		Page:     1,
+		PageSize: 10,
+		Filters:  map[string]string{"flight_number": flight.FlightNumber},
+	}
+	// Queued bookings move the Redis counter before the seat column
+	err = redisClient.Set(ctx, flight.FlightKey(), flight.AvailableSeats-1, time.Minute).Err()
+	require.NoError(t, err)
+	result, err := svc.ListFlights(ctx, params, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	require.Equal(t, flight.AvailableSeats-1, result.Data[0].AvailableSeats)
+
+	// A booking only moves the Redis counter until the cached page expires.
+	err = redisClient.Set(ctx, flight.FlightKey(), flight.AvailableSeats-3, time.Minute).Err()
+	require.NoError(t, err)
+
+	cached, err := svc.ListFlights(ctx, params, nil)
+	require.NoError(t, err)
+	require.Len(t, cached.Data, 1)
+	require.Equal(t, flight.ID, cached.Data[0].ID)
+	require.Equal(t, flight.AvailableSeats-3, cached.Data[0].AvailableSeats)
+}
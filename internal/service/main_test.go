@@ -0,0 +1,68 @@
+//go:build integration
+
+package service
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/ory/dockertest/v3"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/repository"
+	"github.com/joremysh/tonx/pkg/cache"
+	testingx "github.com/joremysh/tonx/pkg/testing"
+)
+
+var (
+	gdb           *gorm.DB
+	err           error
+	pool          *dockertest.Pool
+	resource      *dockertest.Resource
+	redisResource *dockertest.Resource
+	redisClient   *redis.Client
+	rc            *cache.RedisClient
+)
+
+func TestMain(m *testing.M) {
+	pool, resource, gdb, redisResource, redisClient, err = testingx.NewTestContainers()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	err = repository.Migrate(gdb)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	err = repository.RunSeeds(gdb)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	rc = &cache.RedisClient{Client: redisClient}
+
+	defer func() {
+		if err = pool.Purge(resource); err != nil {
+			log.Fatal(err.Error())
+		}
+		if err = pool.Purge(redisResource); err != nil {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	m.Run()
+}
+
+// createFlight creates a flight from Taipei to Tokyo departing at departure,
+// as the seeded flights may have departed already
+func createFlight(t *testing.T, departure time.Time) *model.Flight {
+	flight := repository.MockFlight()
+	flight.FlightNumber = "TX" + gofakeit.DigitN(4)
+	flight.DepartureCity, flight.ArrivalCity = "Taipei", "Tokyo"
+	flight.DepartureTime, flight.ArrivalTime = departure, departure.Add(3*time.Hour)
+	require.NoError(t, gdb.Create(flight).Error)
+	return flight
+}
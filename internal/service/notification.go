@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/notify"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+// Notification statuses
+const (
+	NotificationStatusPending = "PENDING"
+	NotificationStatusSent    = "SENT"
+	NotificationStatusFailed  = "FAILED"
+)
+
+// NotificationConfig holds the tunables of the notifier
+type NotificationConfig struct {
+	// BatchSize is the number of notifications sent per dispatch
+	BatchSize int
+	// MaxAttempts is the number of attempts after which a notification fails
+	MaxAttempts int
+	// Timeout is how long sending one email may take
+	Timeout time.Duration
+	// Backoff is the delay before the first retry, doubled for every further
+	// one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxAge is how long a notification may wait to be sent before it fails
+	// as stale, 0 to wait forever
+	MaxAge time.Duration
+}
+
+// Notifier defines the interface for emailing customers about their orders
+type Notifier interface {
+	// Dispatch fails the notifications older than MaxAge and sends the
+	// others that are due. It returns the number of notifications sent.
+	Dispatch(ctx context.Context) (int, error)
+	// Run dispatches every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// notifier implements Notifier
+type notifier struct {
+	gdb    *gorm.DB
+	mailer notify.Mailer
+	cfg    NotificationConfig
+}
+
+// NewNotifier creates a new instance of Notifier. Every replica may dispatch,
+// rows taken by one are skipped by the others. Without a mailer nothing is
+// sent, and notifications only expire.
+func NewNotifier(gdb *gorm.DB, mailer notify.Mailer, cfg NotificationConfig) Notifier {
+	return &notifier{
+		gdb:    gdb,
+		mailer: mailer,
+		cfg:    cfg,
+	}
+}
+
+// queueNotification writes a notification about an order within tx, so it is
+// only sent if the change it tells about is committed. data is the payload
+// of the notification, nil if the order tells it all.
+func queueNotification(tx *gorm.DB, kind string, orderID uint, data any) error {
+	payload := []byte("{}")
+	if data != nil {
+		var err error
+		if payload, err = json.Marshal(data); err != nil {
+			return fmt.Errorf("failed to encode %s notification: %w", kind, err)
+		}
+	}
+	notification := &model.Notification{
+		Kind:          kind,
+		OrderID:       orderID,
+		Payload:       string(payload),
+		Status:        NotificationStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to queue %s notification: %w", kind, err)
+	}
+	return nil
+}
+
+func (n *notifier) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := n.Dispatch(ctx); err != nil {
+				log.Printf("failed to dispatch notifications: %v\n", err)
+			}
+		}
+	}
+}
+
+func (n *notifier) Dispatch(ctx context.Context) (int, error) {
+	if err := n.expire(ctx); err != nil {
+		return 0, err
+	}
+	if n.mailer == nil {
+		return 0, nil
+	}
+
+	notifications, err := claimDue[model.Notification](ctx, n.gdb, NotificationStatusPending, n.batchSize(), 2*n.cfg.Timeout,
+		"Order.Flight", "Order.Customer", "Order.Travelers")
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range notifications {
+		wg.Add(1)
+		go func(notification *model.Notification) {
+			defer wg.Done()
+			n.deliver(ctx, notification)
+		}(&notifications[i])
+	}
+	wg.Wait()
+	return len(notifications), nil
+}
+
+// expire fails the pending notifications that were not sent within MaxAge,
+// news of bookings that old is no use to customers.
+func (n *notifier) expire(ctx context.Context) error {
+	if n.cfg.MaxAge <= 0 {
+		return nil
+	}
+	now := time.Now()
+	result := n.gdb.WithContext(ctx).Model(&model.Notification{}).
+		Where("status = ? AND next_attempt_at <= ? AND created_at < ?", NotificationStatusPending, now, now.Add(-n.cfg.MaxAge)).
+		Updates(map[string]any{
+			"status":     NotificationStatusFailed,
+			"last_error": "expired before it was sent",
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to expire notifications: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		metrics.Add("notifications_expired", result.RowsAffected)
+		log.Printf("expired %d notifications\n", result.RowsAffected)
+	}
+	return nil
+}
+
+// deliver sends a notification and records the outcome, scheduling a retry
+// with exponential backoff when it failed.
+func (n *notifier) deliver(ctx context.Context, notification *model.Notification) {
+	err := n.send(ctx, notification)
+
+	updates, outcome := attemptUpdates(retryPolicy{
+		MaxAttempts: n.cfg.MaxAttempts,
+		Backoff:     n.cfg.Backoff,
+		MaxBackoff:  n.cfg.MaxBackoff,
+	}, notification.Attempts, err)
+	switch outcome {
+	case attemptSucceeded:
+		updates["status"] = NotificationStatusSent
+		updates["sent_at"] = time.Now()
+		metrics.Inc("notifications_sent")
+	case attemptFailed:
+		updates["status"] = NotificationStatusFailed
+		metrics.Inc("notifications_failed")
+		log.Printf("giving up sending notification %d: %v\n", notification.ID, err)
+	default:
+		metrics.Inc("notifications_retried")
+	}
+
+	// The outcome is recorded even when ctx is done, or the notification is
+	// sent again once its claim expires
+	if err = n.gdb.WithContext(context.WithoutCancel(ctx)).Model(notification).Updates(updates).Error; err != nil {
+		log.Printf("failed to record notification %d: %v\n", notification.ID, err)
+	}
+}
+
+// send renders a notification and emails it to the customer of its order.
+func (n *notifier) send(ctx context.Context, notification *model.Notification) error {
+	order := notification.Order
+	if order == nil || order.Flight == nil || order.Customer == nil {
+		return errors.New("order, flight or customer no longer exists")
+	}
+
+	itinerary := notify.NewItinerary(order)
+	if notification.Kind == notify.KindFlightChanged {
+		change := &notify.FlightChange{}
+		if err := json.Unmarshal([]byte(notification.Payload), change); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		itinerary.Change = change
+	}
+
+	msg, err := notify.Render(notification.Kind, itinerary)
+	if err != nil {
+		return err
+	}
+	// Confirmations and flight changes carry the current itinerary
+	if notification.Kind != notify.KindOrderCancelled {
+		pdf, err := notify.ItineraryPDF(itinerary)
+		if err != nil {
+			return err
+		}
+		msg.Attachments = append(msg.Attachments, notify.Attachment{
+			Filename:    notify.ItineraryFilename(order.OrderNumber),
+			ContentType: notify.ItineraryContentType,
+			Data:        pdf,
+		})
+	}
+	msg.ID = fmt.Sprintf("<notification-%d@tonx>", notification.ID)
+	msg.To = mail.Address{Name: order.Customer.Name, Address: order.Customer.Email}
+
+	if n.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.cfg.Timeout)
+		defer cancel()
+	}
+	return n.mailer.Send(ctx, msg)
+}
+
+func (n *notifier) batchSize() int {
+	if n.cfg.BatchSize <= 0 {
+		return 100
+	}
+	return n.cfg.BatchSize
+}
@@ -0,0 +1,148 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/notify"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+// stubMailer records the messages it sends, by recipient.
+type stubMailer struct {
+	mu   sync.Mutex
+	sent map[string][]*notify.Message
+	err  error
+}
+
+func (m *stubMailer) Send(_ context.Context, msg *notify.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent[msg.To.Address] = append(m.sent[msg.To.Address], msg)
+	return nil
+}
+
+func (m *stubMailer) messages(address string) []*notify.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sent[address]
+}
+
+func TestNotifier_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	mailer := &stubMailer{sent: make(map[string][]*notify.Message), err: errors.New("smtp server down")}
+	notifier := NewNotifier(gdb, mailer, NotificationConfig{
+		BatchSize:   1000,
+		MaxAttempts: 3,
+		Timeout:     time.Second,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	})
+
+	flight := createFlight(t, time.Now().Add(48*time.Hour))
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+
+	svc := NewOrderService(gdb, inventory.NewMemoryInventory(), nil, BookingLimits{})
+	_, err = svc.CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     flight.ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 2,
+		Travelers:    []TravelerName{{FirstName: "Mei", LastName: "Lin"}},
+	})
+	require.ErrorIs(t, err, ErrInvalidTravelers)
+	order, err := svc.CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     flight.ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 2,
+		Travelers:    []TravelerName{{FirstName: "Mei", LastName: "Lin"}, {FirstName: " Wei ", LastName: "Chen"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, order.Travelers, 2)
+	require.Equal(t, "Wei", order.Travelers[1].FirstName)
+
+	// Failed notifications are retried until they are sent
+	_, err = notifier.Dispatch(ctx)
+	require.NoError(t, err)
+	var notification model.Notification
+	err = gdb.Where("order_id = ? AND kind = ?", order.ID, notify.KindOrderConfirmed).First(&notification).Error
+	require.NoError(t, err)
+	require.Equal(t, NotificationStatusPending, notification.Status)
+	require.Equal(t, 1, notification.Attempts)
+	require.Equal(t, "smtp server down", notification.LastError)
+
+	mailer.mu.Lock()
+	mailer.err = nil
+	mailer.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	_, err = notifier.Dispatch(ctx)
+	require.NoError(t, err)
+	err = gdb.First(&notification, notification.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, NotificationStatusSent, notification.Status)
+	require.NotNil(t, notification.SentAt)
+
+	messages := mailer.messages(customer.Email)
+	require.Len(t, messages, 1)
+	require.Contains(t, messages[0].Subject, "Booking confirmed")
+	require.Contains(t, messages[0].Text, order.OrderNumber)
+	require.Contains(t, messages[0].Text, "Wei Chen")
+	require.Len(t, messages[0].Attachments, 1)
+	require.Equal(t, notify.ItineraryFilename(order.OrderNumber), messages[0].Attachments[0].Filename)
+
+	_, err = svc.CancelOrder(ctx, order.ID)
+	require.NoError(t, err)
+	_, err = notifier.Dispatch(ctx)
+	require.NoError(t, err)
+	messages = mailer.messages(customer.Email)
+	require.Len(t, messages, 2)
+	require.Contains(t, messages[1].Subject, "Booking cancelled")
+	require.Empty(t, messages[1].Attachments)
+	require.NotEqual(t, messages[0].ID, messages[1].ID)
+}
+
+func TestNotifier_Expire(t *testing.T) {
+	ctx := context.Background()
+	notifier := NewNotifier(gdb, nil, NotificationConfig{MaxAge: time.Hour})
+
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+	order, err := NewOrderService(gdb, inventory.NewMemoryInventory(), nil, BookingLimits{}).CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     createFlight(t, time.Now().Add(48*time.Hour)).ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 1,
+	})
+	require.NoError(t, err)
+
+	// Without a mailer notifications wait until they are too old to send
+	sent, err := notifier.Dispatch(ctx)
+	require.NoError(t, err)
+	require.Zero(t, sent)
+	var notification model.Notification
+	err = gdb.Where("order_id = ?", order.ID).First(&notification).Error
+	require.NoError(t, err)
+	require.Equal(t, NotificationStatusPending, notification.Status)
+
+	err = gdb.Model(&notification).Update("created_at", time.Now().Add(-2*time.Hour)).Error
+	require.NoError(t, err)
+	_, err = notifier.Dispatch(ctx)
+	require.NoError(t, err)
+	err = gdb.First(&notification, notification.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, NotificationStatusFailed, notification.Status)
+	require.Zero(t, notification.Attempts)
+}
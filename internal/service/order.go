@@ -5,30 +5,66 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/boarding"
 	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/inventory"
 	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/notify"
 	"github.com/joremysh/tonx/internal/repository"
-	"github.com/joremysh/tonx/pkg/cache"
+	"github.com/joremysh/tonx/pkg/metrics"
 )
 
 var (
-	ErrFlightNotFound   = errors.New("flight not found")
-	ErrNoAvailableSeats = errors.New("no available seats")
+	ErrFlightNotFound      = errors.New("flight not found")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderNotCancellable = errors.New("order is cancelled already or its flight has departed")
+	// ErrNoAvailableSeats is returned by every seat inventory, so callers
+	// need not know which one is in use
+	ErrNoAvailableSeats = inventory.ErrNoAvailableSeats
+	ErrTooManyTickets   = errors.New("too many tickets in one order")
+	ErrInvalidTravelers = errors.New("travelers must be named once per ticket, in the Latin letters of their travel documents, with names of at most 50 characters")
+	ErrFlightSeatLimit  = inventory.ErrFlightSeatLimit
+	ErrPendingHoldLimit = inventory.ErrPendingHoldLimit
 )
 
+// BookingLimits keeps a single customer from taking every seat of a flight.
+// Zero limits are not checked.
+type BookingLimits struct {
+	// MaxTicketsPerOrder caps the tickets of one order
+	MaxTicketsPerOrder int
+	// MaxTicketsPerFlight caps the tickets of a customer on one flight,
+	// across all of their orders
+	MaxTicketsPerFlight int
+	// MaxPendingHolds caps the orders of a customer whose seats are reserved
+	// but not yet booked, such as queued orders
+	MaxPendingHolds int
+}
+
 // Order defines the interface for order operations
 type Order interface {
 	// CreateOrder creates a new order with concurrency control
 	CreateOrder(ctx context.Context, req CreateOrderRequest) (*model.Order, error)
-	// InitializeFlightSeats initializes or updates the available seats in Redis
+	// GetOrder returns an order, or ErrOrderNotFound
+	GetOrder(ctx context.Context, orderID uint) (*model.Order, error)
+	// GetOrderByNumber returns an order with its flight, customer, travelers
+	// and entitlements, or ErrOrderNotFound
+	GetOrderByNumber(ctx context.Context, orderNumber string) (*model.Order, error)
+	// CancelOrder cancels an order whose flight has not departed yet and
+	// gives its seats back. A refund entitlement of the order is used for
+	// the refund, and keeps orders of cancelled flights cancellable after
+	// they were due to depart.
+	CancelOrder(ctx context.Context, orderID uint) (*model.Order, error)
+	// InitializeFlightSeats initializes the available seats in the seat
+	// inventory unless they are set already
 	InitializeFlightSeats(ctx context.Context, flightID uint, availableSeats int) error
 }
 
@@ -37,134 +73,413 @@ type CreateOrderRequest struct {
 	FlightID     uint
 	CustomerID   uint
 	TicketAmount int
+	// AgencyID is the agency booking through its API key, if any
+	AgencyID *uint
+	// Travelers name the passengers, one per ticket. They may be left out,
+	// but orders without them cannot be checked in online.
+	Travelers []TravelerName
+}
+
+// TravelerName is the name of a passenger as on their travel document
+type TravelerName struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// validate checks the parts of the request that do not depend on the flight
+func (req CreateOrderRequest) validate(limits BookingLimits) error {
+	if limits.MaxTicketsPerOrder > 0 && req.TicketAmount > limits.MaxTicketsPerOrder {
+		return ErrTooManyTickets
+	}
+	if len(req.Travelers) == 0 {
+		return nil
+	}
+	if len(req.Travelers) != req.TicketAmount {
+		return ErrInvalidTravelers
+	}
+	for _, traveler := range req.Travelers {
+		first, last := strings.TrimSpace(traveler.FirstName), strings.TrimSpace(traveler.LastName)
+		if utf8.RuneCountInString(first) > 50 || utf8.RuneCountInString(last) > 50 {
+			return ErrInvalidTravelers
+		}
+		// Boarding passes only print Latin letters
+		if _, ok := boarding.LatinName(first); !ok {
+			return ErrInvalidTravelers
+		}
+		if _, ok := boarding.LatinName(last); !ok {
+			return ErrInvalidTravelers
+		}
+	}
+	return nil
 }
 
 // orderService implements Order
 type orderService struct {
-	gdb         *gorm.DB
-	orderRepo   repository.Order
-	redisClient *cache.RedisClient
+	gdb       *gorm.DB
+	orderRepo repository.Order
+	seats     inventory.SeatInventory
+	limits    BookingLimits
 }
 
 // NewOrderService creates a new instance of Order
-func NewOrderService(gdb *gorm.DB, redisClient *cache.RedisClient, orderRepo repository.Order) Order {
+func NewOrderService(gdb *gorm.DB, seats inventory.SeatInventory, orderRepo repository.Order, limits BookingLimits) Order {
 	return &orderService{
-		gdb:         gdb,
-		orderRepo:   orderRepo,
-		redisClient: redisClient,
+		gdb:       gdb,
+		orderRepo: orderRepo,
+		seats:     seats,
+		limits:    limits,
 	}
 }
 
-// InitializeFlightSeats initializes or updates the available seats in Redis
+// InitializeFlightSeats initializes the available seats in the seat inventory
+// unless they are set already
 func (s *orderService) InitializeFlightSeats(ctx context.Context, flightID uint, availableSeats int) error {
-	key := fmt.Sprintf(constant.FLIGHT_KEY, flightID)
-	return s.redisClient.Set(ctx, key, availableSeats, 24*time.Hour)
+	return s.seats.Init(ctx, flightID, availableSeats)
 }
 
 func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest) (*model.Order, error) {
-	flightKey := fmt.Sprintf("flight:%d:available_seats", req.FlightID)
+	if err := req.validate(s.limits); err != nil {
+		return nil, err
+	}
 
-	// 1. Check available seats in Redis first
-	originalSeats, err := s.redisClient.Client.Get(ctx, flightKey).Int()
+	// 1-2. Check and reserve seats in the seat inventory
+	reservation, err := s.reserveSeats(ctx, req, constant.ReservationTTL)
+	if errors.Is(err, inventory.ErrUnavailable) {
+		// The flight row lock alone still prevents overbooking, it only makes
+		// the bookings of a flight wait for each other.
+		log.Printf("booking flight %d in the database only: %v\n", req.FlightID, err)
+		metrics.Inc("orders_database_only")
+		return s.createOrder(req)
+	}
 	if err != nil {
-		if !errors.Is(err, redis.Nil) {
-			return nil, fmt.Errorf("failed to get available seats from Redis: %w", err)
-		}
-		// Key doesn't exist, get flight info from database
-		var flight model.Flight
-		if err = s.gdb.Where("id = ?", req.FlightID).First(&flight).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, ErrFlightNotFound
-			}
-			return nil, fmt.Errorf("failed to get flight: %w", err)
+		return nil, err
+	}
+
+	// Prepare to release the reserved seats if anything fails after this point.
+	// Only this reservation is given back, so concurrent bookings are not undone.
+	confirmed := false
+	defer func() {
+		if !confirmed {
+			// The request context may be done by the time the booking fails.
+			s.releaseSeats(context.Background(), req.FlightID, req.CustomerID, reservation)
 		}
+	}()
 
-		originalSeats = flight.AvailableSeats
-		log.Println("originalSeats from DB", "originalSeats", originalSeats)
+	// 3. Start database transaction only for writing data
+	order, err := s.createOrder(req)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed = true // No need to release the seats on success
+	s.confirmSeats(ctx, req.FlightID, req.CustomerID, reservation)
+	return order, nil
+}
 
-		// Initialize Redis with current available seats using SetNX
-		if _, err = s.redisClient.Client.SetNX(ctx, flightKey, originalSeats, 24*time.Hour).Result(); err != nil {
-			return nil, fmt.Errorf("failed to initialize Redis with available seats: %w", err)
+func (s *orderService) GetOrder(ctx context.Context, orderID uint) (*model.Order, error) {
+	var order model.Order
+	if err := s.gdb.WithContext(ctx).Preload("Travelers").Preload("Entitlements").First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
 		}
-		log.Println("set originalSeats from DB to Redis successfully.", "originalSeats", originalSeats)
-	} else if originalSeats == 0 {
-		// Key exists but no seats available
-		return nil, ErrNoAvailableSeats
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
+	return &order, nil
+}
 
-	// 2. Check and decrement available seats using Redis Lua script
-	result, err := s.redisClient.Client.Eval(ctx, constant.CheckAndDecrementSeatsScript, []string{flightKey}, req.TicketAmount).Result()
+func (s *orderService) GetOrderByNumber(ctx context.Context, orderNumber string) (*model.Order, error) {
+	var order model.Order
+	err := s.gdb.WithContext(ctx).
+		Preload("Flight").Preload("Customer").Preload("Travelers").Preload("Entitlements").
+		Where("order_number = ?", orderNumber).
+		First(&order).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute Redis script: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
+	return &order, nil
+}
 
-	resultInt, ok := result.(int64)
-	if !ok {
-		return nil, fmt.Errorf("failed to parse Redis script result: not an integer")
+func (s *orderService) CancelOrder(ctx context.Context, orderID uint) (*model.Order, error) {
+	order, err := s.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
 	}
 
-	switch resultInt {
-	case -1:
-		return nil, fmt.Errorf("flight seats not found in Redis")
-	case 0:
-		return nil, ErrNoAvailableSeats
+	var flight model.Flight
+	err = s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The flight is locked before the order, in the same order as bookings
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flight, order.FlightID).Error; err != nil {
+			return fmt.Errorf("failed to lock flight record: %w", err)
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(order, orderID).Error; err != nil {
+			return fmt.Errorf("failed to lock order record: %w", err)
+		}
+		if order.Status == string(api.OrderStatusCANCELLED) {
+			return ErrOrderNotCancellable
+		}
+		if !flight.DepartureTime.After(time.Now()) {
+			// Cancelled flights never depart, their refunds may be claimed
+			// later
+			refundable, err := hasEntitlement(tx, order.ID, EntitlementRefund)
+			if err != nil {
+				return err
+			}
+			if !refundable || flight.Status != string(api.FlightStatusCANCELLED) {
+				return ErrOrderNotCancellable
+			}
+		}
+
+		if err := tx.Model(order).Update("status", string(api.OrderStatusCANCELLED)).Error; err != nil {
+			return fmt.Errorf("failed to cancel order: %w", err)
+		}
+		if err := tx.Model(&flight).Update("available_seats", gorm.Expr("available_seats + ?", order.TicketAmount)).Error; err != nil {
+			return fmt.Errorf("failed to update flight seats: %w", err)
+		}
+		// Seats its travelers checked in to can be taken by others again
+		if err := tx.Model(&model.Traveler{}).Where("order_id = ?", order.ID).Update("seat_flight_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to release traveler seats: %w", err)
+		}
+		if err := useEntitlement(tx, order, EntitlementRefund); err != nil {
+			return err
+		}
+		if err := recordEvent(tx, EventOrderCancelled, order.ID, newOrderEvent(order)); err != nil {
+			return err
+		}
+		return queueNotification(tx, notify.KindOrderCancelled, order.ID, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
-	if resultInt != 1 {
-		return nil, fmt.Errorf("invalid Redis script result: %d", resultInt)
+
+	// A counter that missed the seats is repaired by the reconciler. Closed
+	// flights keep their seats off sale.
+	if !closedStatus(flight.Status) {
+		if err = s.seats.Restock(ctx, order.FlightID, order.CustomerID, order.TicketAmount); err != nil {
+			log.Printf("failed to restock seats of cancelled order %s: %v\n", order.OrderNumber, err)
+		}
 	}
+	metrics.Inc("orders_cancelled")
+	return order, nil
+}
 
-	// Prepare to restore Redis seats if anything fails after this point
-	seatRestored := false
-	defer func() {
-		if !seatRestored {
-			// Restore to the original value before decrement
-			if err = s.redisClient.Client.Set(ctx, flightKey, originalSeats, 24*time.Hour).Err(); err != nil {
-				log.Printf("failed to restore seat in Redis: %v\n", err)
-			}
+// hasEntitlement reports whether an order has an unused entitlement of the
+// given kind
+func hasEntitlement(tx *gorm.DB, orderID uint, kind string) (bool, error) {
+	var count int64
+	err := tx.Model(&model.OrderEntitlement{}).
+		Where("order_id = ? AND kind = ? AND used_at IS NULL", orderID, kind).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to get entitlements: %w", err)
+	}
+	return count > 0, nil
+}
+
+// useEntitlement marks an unused entitlement of an order of the given kind
+// as used within tx, if the order has one
+func useEntitlement(tx *gorm.DB, order *model.Order, kind string) error {
+	now := time.Now()
+	err := tx.Model(&model.OrderEntitlement{}).
+		Where("order_id = ? AND kind = ? AND used_at IS NULL", order.ID, kind).
+		Update("used_at", now).Error
+	if err != nil {
+		return fmt.Errorf("failed to use entitlement: %w", err)
+	}
+	for i := range order.Entitlements {
+		if entitlement := &order.Entitlements[i]; entitlement.Kind == kind && entitlement.UsedAt == nil {
+			entitlement.UsedAt = &now
 		}
-	}()
+	}
+	return nil
+}
 
+// createOrder writes an order and takes its seats in one database transaction
+func (s *orderService) createOrder(req CreateOrderRequest) (*model.Order, error) {
 	var order *model.Order
+	err := s.gdb.Transaction(func(tx *gorm.DB) (err error) {
+		order, err = createOrderTx(tx, req, s.limits, generateOrderNumber(constant.ORD_PREFIX))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
 
-	// 3. Start database transaction only for writing data
-	if err = s.gdb.Transaction(func(tx *gorm.DB) error {
-		// 4. Lock and get flight for final update
-		var flight model.Flight
-		if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flight, req.FlightID).Error; err != nil {
-			return fmt.Errorf("failed to lock flight record: %w", err)
+// reserveSeats reserves the seats of an order in the seat inventory, within
+// the limits of its customer. The counters of the flight and customer are
+// initialized from the database when they are missing. It returns the token
+// of the reservation, which stays releasable for ttl.
+func (s *orderService) reserveSeats(ctx context.Context, req CreateOrderRequest, ttl time.Duration) (string, error) {
+	hold := inventory.Hold{
+		CustomerID:     req.CustomerID,
+		Seats:          req.TicketAmount,
+		MaxFlightSeats: s.limits.MaxTicketsPerFlight,
+		MaxPending:     s.limits.MaxPendingHolds,
+	}
+	// Each counter is missing at most once, concurrent bookings may
+	// initialize them too but only the first wins
+	for attempt := 0; ; attempt++ {
+		reservation, err := s.seats.Reserve(ctx, req.FlightID, hold, ttl)
+		if attempt == 2 {
+			return reservation, err
 		}
-
-		// Double-check available seats
-		if flight.AvailableSeats < req.TicketAmount {
-			return ErrNoAvailableSeats
+		switch {
+		case errors.Is(err, inventory.ErrNotInitialized):
+			err = s.initFlightSeats(ctx, req.FlightID)
+		case errors.Is(err, inventory.ErrCustomerNotInitialized):
+			err = s.initCustomerSeats(ctx, req.FlightID, req.CustomerID)
+		default:
+			return reservation, err
 		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
 
-		// 5. Create order
-		order = &model.Order{
-			FlightID:    flight.ID,
-			CustomerID:  req.CustomerID,
-			Status:      string(api.OrderStatusCOMPLETED),
-			TotalAmount: flight.BasePrice * req.TicketAmount,
-			OrderNumber: generateOrderNumber(constant.ORD_PREFIX),
-			BookingTime: time.Now(),
+// initFlightSeats sets the seat inventory counter of a flight from the
+// database.
+func (s *orderService) initFlightSeats(ctx context.Context, flightID uint) error {
+	// Counter doesn't exist, get flight info from database
+	var flight model.Flight
+	if err := s.gdb.Where("id = ?", flightID).First(&flight).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFlightNotFound
 		}
+		return fmt.Errorf("failed to get flight: %w", err)
+	}
+
+	if err := s.seats.Init(ctx, flightID, flight.AvailableSeats); err != nil {
+		return err
+	}
+	log.Println("set originalSeats from DB to seat inventory successfully.", "originalSeats", flight.AvailableSeats)
+	return nil
+}
+
+// initCustomerSeats sets the seat inventory counter of the seats a customer
+// booked on a flight from the database.
+func (s *orderService) initCustomerSeats(ctx context.Context, flightID, customerID uint) error {
+	booked, err := bookedSeats(s.gdb.WithContext(ctx), flightID, customerID)
+	if err != nil {
+		return err
+	}
+	return s.seats.InitCustomer(ctx, flightID, customerID, booked)
+}
+
+// releaseSeats gives the seats of a reservation that will not be persisted
+// back to the seat inventory.
+func (s *orderService) releaseSeats(ctx context.Context, flightID, customerID uint, reservation string) {
+	if err := s.seats.Release(ctx, flightID, customerID, reservation); err != nil {
+		log.Printf("failed to release seats of flight %d: %v\n", flightID, err)
+	}
+}
+
+// confirmSeats drops a reservation whose order was persisted, so it can no
+// longer be released.
+func (s *orderService) confirmSeats(ctx context.Context, flightID, customerID uint, reservation string) {
+	if err := s.seats.Confirm(ctx, flightID, customerID, reservation); err != nil {
+		log.Printf("failed to confirm reservation %s of flight %d: %v\n", reservation, flightID, err)
+	}
+}
+
+// bookedSeats returns the tickets of the non-cancelled orders of a customer
+// on a flight.
+func bookedSeats(tx *gorm.DB, flightID, customerID uint) (int, error) {
+	var booked int
+	err := tx.Model(&model.Order{}).
+		Select("COALESCE(SUM(ticket_amount), 0)").
+		Where("flight_id = ? AND customer_id = ? AND status <> ?", flightID, customerID, api.OrderStatusCANCELLED).
+		Scan(&booked).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count booked seats: %w", err)
+	}
+	return booked, nil
+}
 
-		if err = tx.Create(order).Error; err != nil {
-			return fmt.Errorf("failed to create order: %w", err)
+// createOrderTx locks the flight, creates the order and takes its seats in
+// the database within tx. The limits are checked again under the lock, as
+// bookings bypassing the seat inventory are not counted there.
+func createOrderTx(tx *gorm.DB, req CreateOrderRequest, limits BookingLimits, orderNumber string) (*model.Order, error) {
+	if err := req.validate(limits); err != nil {
+		return nil, err
+	}
+
+	// 4. Lock and get flight for final update
+	var flight model.Flight
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flight, req.FlightID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFlightNotFound
 		}
+		return nil, fmt.Errorf("failed to lock flight record: %w", err)
+	}
+	// Seat counters may still offer seats of a flight closed meanwhile
+	if closedStatus(flight.Status) {
+		return nil, ErrFlightClosed
+	}
 
-		// 6. Update flight available seats in database
-		if err = tx.Model(&flight).Update("available_seats", gorm.Expr("available_seats - ?", req.TicketAmount)).Error; err != nil {
-			return fmt.Errorf("failed to update flight seats: %w", err)
+	// Double-check available seats
+	if flight.AvailableSeats < req.TicketAmount {
+		return nil, ErrNoAvailableSeats
+	}
+	if limits.MaxTicketsPerFlight > 0 {
+		booked, err := bookedSeats(tx, flight.ID, req.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		if booked+req.TicketAmount > limits.MaxTicketsPerFlight {
+			return nil, ErrFlightSeatLimit
 		}
+	}
 
-		log.Printf("updated flight ID %d available seats from %d to %d\n", flight.ID, flight.AvailableSeats, flight.AvailableSeats-req.TicketAmount)
-		return nil
-	}); err != nil {
+	// 5. Create order
+	order := &model.Order{
+		FlightID:     flight.ID,
+		CustomerID:   req.CustomerID,
+		AgencyID:     req.AgencyID,
+		Status:       string(api.OrderStatusCOMPLETED),
+		TotalAmount:  flight.BasePrice * req.TicketAmount,
+		TicketAmount: req.TicketAmount,
+		OrderNumber:  orderNumber,
+		BookingTime:  time.Now(),
+	}
+
+	if err := tx.Create(order).Error; err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	if len(req.Travelers) > 0 {
+		order.Travelers = make([]model.Traveler, len(req.Travelers))
+		for i, traveler := range req.Travelers {
+			order.Travelers[i] = model.Traveler{
+				OrderID:   order.ID,
+				FirstName: strings.TrimSpace(traveler.FirstName),
+				LastName:  strings.TrimSpace(traveler.LastName),
+			}
+		}
+		if err := tx.Create(&order.Travelers).Error; err != nil {
+			return nil, fmt.Errorf("failed to create travelers: %w", err)
+		}
+	}
+	// Orders are confirmed as they are created, their seats are taken in the
+	// same transaction
+	for _, eventType := range []string{EventOrderCreated, EventOrderConfirmed} {
+		if err := recordEvent(tx, eventType, order.ID, newOrderEvent(order)); err != nil {
+			return nil, err
+		}
+	}
+	if err := queueNotification(tx, notify.KindOrderConfirmed, order.ID, nil); err != nil {
 		return nil, err
 	}
 
-	seatRestored = true // No need to restore Redis seats on success
+	// 6. Update flight available seats in database
+	if err := tx.Model(&flight).Update("available_seats", gorm.Expr("available_seats - ?", req.TicketAmount)).Error; err != nil {
+		return nil, fmt.Errorf("failed to update flight seats: %w", err)
+	}
+
+	log.Printf("updated flight ID %d available seats from %d to %d\n", flight.ID, flight.AvailableSeats, flight.AvailableSeats-req.TicketAmount)
 	return order, nil
 }
 
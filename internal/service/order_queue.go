@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/cache"
+)
+
+var ErrTicketNotFound = errors.New("order ticket not found")
+
+// Order ticket statuses
+const (
+	TicketStatusPending   = "PENDING"
+	TicketStatusCompleted = "COMPLETED"
+	TicketStatusFailed    = "FAILED"
+)
+
+// OrderQueue defines the interface for asynchronous order submission
+type OrderQueue interface {
+	// Submit reserves the seats in Redis and enqueues the order to be
+	// persisted by the workers. It returns a pending ticket to poll.
+	Submit(ctx context.Context, req CreateOrderRequest) (*OrderTicket, error)
+	// Ticket returns the current state of a submitted order
+	Ticket(ctx context.Context, ticketID string) (*OrderTicket, error)
+	// Run consumes the queue as the named consumer until ctx is done
+	Run(ctx context.Context, consumer string) error
+}
+
+// OrderTicket tracks an order submitted through the queue
+type OrderTicket struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	FlightID     uint   `json:"flight_id"`
+	CustomerID   uint   `json:"customer_id"`
+	TicketAmount int    `json:"ticket_amount"`
+	OrderNumber  string `json:"order_number"`
+	AgencyID     *uint  `json:"agency_id,omitempty"`
+	// Travelers are persisted with the order
+	Travelers []TravelerName `json:"travelers,omitempty"`
+	// Reservation is the token of the seats reserved for the order in Redis
+	Reservation string    `json:"reservation"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// OrderQueueConfig holds the tunables of the queue workers
+type OrderQueueConfig struct {
+	// BatchSize is the number of orders persisted in one transaction
+	BatchSize int64
+	// Block is how long a worker waits for new orders before checking for
+	// stale ones again
+	Block time.Duration
+	// ClaimIdle is how long an order may stay unacknowledged before another
+	// worker takes it over
+	ClaimIdle time.Duration
+	// MaxDeliveries is the number of attempts after which an order is moved
+	// to the dead-letter stream
+	MaxDeliveries int64
+	// Limits are the booking limits of the customers submitting orders
+	Limits BookingLimits
+}
+
+// orderQueue implements OrderQueue
+type orderQueue struct {
+	*orderService
+	redisClient *cache.RedisClient
+	cfg         OrderQueueConfig
+}
+
+// NewOrderQueue creates a new instance of OrderQueue. The queue itself lives
+// in Redis, whichever seat inventory the seats are reserved from.
+func NewOrderQueue(gdb *gorm.DB, redisClient *cache.RedisClient, seats inventory.SeatInventory, cfg OrderQueueConfig) OrderQueue {
+	return &orderQueue{
+		orderService: &orderService{
+			gdb:    gdb,
+			seats:  seats,
+			limits: cfg.Limits,
+		},
+		redisClient: redisClient,
+		cfg:         cfg,
+	}
+}
+
+func (q *orderQueue) Submit(ctx context.Context, req CreateOrderRequest) (*OrderTicket, error) {
+	if err := req.validate(q.limits); err != nil {
+		return nil, err
+	}
+
+	// The reservation has to outlive the ticket, queued orders may wait for
+	// a while before they are persisted or given up. It counts as a pending
+	// hold of the customer until then.
+	reservation, err := q.reserveSeats(ctx, req, constant.OrderTicketTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ticket := &OrderTicket{
+		ID:           uuid.New().String(),
+		Status:       TicketStatusPending,
+		FlightID:     req.FlightID,
+		CustomerID:   req.CustomerID,
+		TicketAmount: req.TicketAmount,
+		AgencyID:     req.AgencyID,
+		Travelers:    req.Travelers,
+		// The order number is fixed up front, so a redelivered order can be
+		// recognized as already persisted.
+		OrderNumber: generateOrderNumber(constant.ORD_PREFIX),
+		Reservation: reservation,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err = q.saveTicket(ctx, ticket); err != nil {
+		q.releaseSeats(ctx, req.FlightID, req.CustomerID, reservation)
+		return nil, err
+	}
+
+	payload, err := json.Marshal(ticket)
+	if err != nil {
+		q.releaseSeats(ctx, req.FlightID, req.CustomerID, reservation)
+		return nil, err
+	}
+	// Seats held by queued orders are taken in Redis but not yet in the
+	// database, the reconciler needs to know about them. The stream lives in
+	// another cluster slot than the flight, so both cannot change atomically.
+	if err = q.redisClient.Client.Eval(ctx, constant.HoldSeatsScript,
+		[]string{heldSeatsKey(req.FlightID)},
+		ticket.ID, req.TicketAmount, int(constant.OrderTicketTTL.Seconds()),
+	).Err(); err != nil {
+		q.releaseSeats(ctx, req.FlightID, req.CustomerID, reservation)
+		return nil, fmt.Errorf("failed to hold seats: %w", err)
+	}
+	if err = q.redisClient.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: constant.ORDER_STREAM,
+		Values: map[string]interface{}{"ticket": payload},
+	}).Err(); err != nil {
+		q.dropHeldSeats(ctx, ticket)
+		q.releaseSeats(ctx, req.FlightID, req.CustomerID, reservation)
+		return nil, fmt.Errorf("failed to enqueue order: %w", err)
+	}
+	return ticket, nil
+}
+
+func (q *orderQueue) Ticket(ctx context.Context, ticketID string) (*OrderTicket, error) {
+	ticket := &OrderTicket{}
+	if err := q.redisClient.Get(ctx, fmt.Sprintf(constant.ORDER_TICKET_KEY, ticketID), ticket); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func (q *orderQueue) Run(ctx context.Context, consumer string) error {
+	err := q.redisClient.Client.XGroupCreateMkStream(ctx, constant.ORDER_STREAM, constant.ORDER_CONSUMER_GROUP, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		// Take over orders left unacknowledged by crashed or failing workers
+		// before reading new ones.
+		messages, err := q.claimStale(ctx, consumer)
+		if err != nil {
+			log.Printf("failed to claim stale orders: %v\n", err)
+		}
+
+		if len(messages) == 0 {
+			streams, err := q.redisClient.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    constant.ORDER_CONSUMER_GROUP,
+				Consumer: consumer,
+				Streams:  []string{constant.ORDER_STREAM, ">"},
+				Count:    q.cfg.BatchSize,
+				Block:    q.cfg.Block,
+			}).Result()
+			if err != nil {
+				if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+					log.Printf("failed to read order queue: %v\n", err)
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+			for _, stream := range streams {
+				messages = append(messages, stream.Messages...)
+			}
+		}
+
+		q.process(ctx, messages)
+	}
+	return nil
+}
+
+// claimStale moves orders that stayed unacknowledged for longer than
+// ClaimIdle to consumer. Orders delivered MaxDeliveries times already are
+// dead-lettered instead, unless a worker saved them but failed to
+// acknowledge them.
+func (q *orderQueue) claimStale(ctx context.Context, consumer string) ([]redis.XMessage, error) {
+	pending, err := q.redisClient.Client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: constant.ORDER_STREAM,
+		Group:  constant.ORDER_CONSUMER_GROUP,
+		Idle:   q.cfg.ClaimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  q.cfg.BatchSize,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(pending))
+	exhausted := make(map[string]bool)
+	for _, entry := range pending {
+		ids = append(ids, entry.ID)
+		exhausted[entry.ID] = entry.RetryCount >= q.cfg.MaxDeliveries
+	}
+
+	claimed, err := q.redisClient.Client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   constant.ORDER_STREAM,
+		Group:    constant.ORDER_CONSUMER_GROUP,
+		Consumer: consumer,
+		MinIdle:  q.cfg.ClaimIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]redis.XMessage, 0, len(claimed))
+	for _, message := range claimed {
+		if !exhausted[message.ID] {
+			messages = append(messages, message)
+			continue
+		}
+		ticket, err := decodeTicket(message)
+		if err != nil {
+			q.deadLetter(ctx, message, err)
+			continue
+		}
+		// Releasing the seats of a saved order would sell them twice
+		persisted, err := q.persisted(ctx, ticket)
+		switch {
+		case err != nil:
+			log.Printf("failed to look up order %s: %v\n", ticket.OrderNumber, err)
+		case persisted:
+			q.complete(ctx, message, ticket)
+		default:
+			q.deadLetter(ctx, message, fmt.Errorf("gave up after %d deliveries", q.cfg.MaxDeliveries))
+		}
+	}
+	return messages, nil
+}
+
+// persisted reports whether the order of a ticket was saved.
+func (q *orderQueue) persisted(ctx context.Context, ticket *OrderTicket) (bool, error) {
+	var count int64
+	if err := q.gdb.WithContext(ctx).Model(&model.Order{}).Where("order_number = ?", ticket.OrderNumber).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// process persists a batch of queued orders. The batch is written in one
+// transaction, and falls back to one transaction per order when that fails so
+// a single bad order does not hold back the others.
+func (q *orderQueue) process(ctx context.Context, messages []redis.XMessage) {
+	type queued struct {
+		message redis.XMessage
+		ticket  *OrderTicket
+	}
+	batch := make([]queued, 0, len(messages))
+	for _, message := range messages {
+		ticket, err := decodeTicket(message)
+		if err != nil {
+			q.deadLetter(ctx, message, err)
+			continue
+		}
+		batch = append(batch, queued{message: message, ticket: ticket})
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	persist := func(tx *gorm.DB, ticket *OrderTicket) error {
+		// A redelivered order may have been committed before the worker
+		// that wrote it failed to acknowledge it.
+		var count int64
+		if err := tx.Model(&model.Order{}).Where("order_number = ?", ticket.OrderNumber).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		_, err := createOrderTx(tx, CreateOrderRequest{
+			FlightID:     ticket.FlightID,
+			CustomerID:   ticket.CustomerID,
+			TicketAmount: ticket.TicketAmount,
+			AgencyID:     ticket.AgencyID,
+			Travelers:    ticket.Travelers,
+		}, q.limits, ticket.OrderNumber)
+		return err
+	}
+
+	err := q.gdb.Transaction(func(tx *gorm.DB) error {
+		for _, item := range batch {
+			if err := persist(tx, item.ticket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for _, item := range batch {
+			q.complete(ctx, item.message, item.ticket)
+		}
+		return
+	}
+
+	log.Printf("failed to persist batch of %d orders, retrying one by one: %v\n", len(batch), err)
+	for _, item := range batch {
+		err = q.gdb.Transaction(func(tx *gorm.DB) error {
+			return persist(tx, item.ticket)
+		})
+		switch {
+		case err == nil:
+			q.complete(ctx, item.message, item.ticket)
+		case errors.Is(err, ErrNoAvailableSeats) || errors.Is(err, ErrFlightNotFound) ||
+			errors.Is(err, ErrTooManyTickets) || errors.Is(err, ErrFlightSeatLimit) ||
+			errors.Is(err, ErrInvalidTravelers) || errors.Is(err, ErrFlightClosed):
+			// Retrying cannot help, give the seats back right away.
+			q.deadLetter(ctx, item.message, err)
+		default:
+			// Leave the order pending, it is claimed again after ClaimIdle.
+			log.Printf("failed to persist order %s: %v\n", item.ticket.OrderNumber, err)
+		}
+	}
+}
+
+// complete acknowledges a persisted order and marks its ticket completed.
+func (q *orderQueue) complete(ctx context.Context, message redis.XMessage, ticket *OrderTicket) {
+	ticket.Status = TicketStatusCompleted
+	ticket.UpdatedAt = time.Now()
+	if err := q.saveTicket(ctx, ticket); err != nil {
+		log.Printf("failed to update ticket %s: %v\n", ticket.ID, err)
+	}
+	q.ack(ctx, message, ticket)
+	q.confirmSeats(ctx, ticket.FlightID, ticket.CustomerID, ticket.Reservation)
+}
+
+// ack acknowledges a queued order, deletes it from the stream and drops the
+// seats it held.
+func (q *orderQueue) ack(ctx context.Context, message redis.XMessage, ticket *OrderTicket) {
+	if ticket != nil {
+		q.dropHeldSeats(ctx, ticket)
+	}
+	if err := q.redisClient.Client.XAck(ctx, constant.ORDER_STREAM, constant.ORDER_CONSUMER_GROUP, message.ID).Err(); err != nil {
+		log.Printf("failed to acknowledge order %s: %v\n", message.ID, err)
+		return
+	}
+	// Acknowledged entries are of no use to anyone, the stream would only
+	// grow with them
+	if err := q.redisClient.Client.XDel(ctx, constant.ORDER_STREAM, message.ID).Err(); err != nil {
+		log.Printf("failed to delete order %s from the queue: %v\n", message.ID, err)
+	}
+}
+
+// dropHeldSeats drops the seats held by a queued order, at most once however
+// often the order is acknowledged.
+func (q *orderQueue) dropHeldSeats(ctx context.Context, ticket *OrderTicket) {
+	if err := q.redisClient.Client.HDel(ctx, heldSeatsKey(ticket.FlightID), ticket.ID).Err(); err != nil {
+		log.Printf("failed to drop held seats of ticket %s: %v\n", ticket.ID, err)
+	}
+}
+
+// deadLetter moves an order that cannot be persisted to the dead-letter
+// stream, fails its ticket and gives its seats back.
+func (q *orderQueue) deadLetter(ctx context.Context, message redis.XMessage, cause error) {
+	log.Printf("moving order %s to the dead-letter stream: %v\n", message.ID, cause)
+
+	values := map[string]interface{}{
+		"id":    message.ID,
+		"error": cause.Error(),
+	}
+	for k, v := range message.Values {
+		values[k] = v
+	}
+	if err := q.redisClient.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: constant.ORDER_DEAD_LETTER_STREAM,
+		MaxLen: constant.OrderDeadLetterMaxLen,
+		Approx: true,
+		Values: values,
+	}).Err(); err != nil {
+		// Keep the order pending rather than losing it.
+		log.Printf("failed to dead-letter order %s: %v\n", message.ID, err)
+		return
+	}
+
+	ticket, err := decodeTicket(message)
+	if err != nil {
+		q.ack(ctx, message, nil)
+		return
+	}
+	q.ack(ctx, message, ticket)
+	q.releaseSeats(ctx, ticket.FlightID, ticket.CustomerID, ticket.Reservation)
+	ticket.Status = TicketStatusFailed
+	ticket.Error = cause.Error()
+	ticket.UpdatedAt = time.Now()
+	if err = q.saveTicket(ctx, ticket); err != nil {
+		log.Printf("failed to update ticket %s: %v\n", ticket.ID, err)
+	}
+}
+
+func (q *orderQueue) saveTicket(ctx context.Context, ticket *OrderTicket) error {
+	return q.redisClient.Set(ctx, fmt.Sprintf(constant.ORDER_TICKET_KEY, ticket.ID), ticket, constant.OrderTicketTTL)
+}
+
+func decodeTicket(message redis.XMessage) (*OrderTicket, error) {
+	payload, ok := message.Values["ticket"].(string)
+	if !ok {
+		return nil, fmt.Errorf("order %s has no ticket", message.ID)
+	}
+	ticket := &OrderTicket{}
+	if err := json.Unmarshal([]byte(payload), ticket); err != nil {
+		return nil, fmt.Errorf("order %s has an invalid ticket: %w", message.ID, err)
+	}
+	return ticket, nil
+}
+
+func heldSeatsKey(flightID uint) string {
+	return fmt.Sprintf(constant.FLIGHT_HELD_KEY, flightID)
+}
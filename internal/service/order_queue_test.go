@@ -0,0 +1,149 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestOrderQueue_Submit(t *testing.T) {
+	queue := NewOrderQueue(gdb, rc, inventory.NewRedisInventory(rc), OrderQueueConfig{
+		BatchSize:     10,
+		Block:         100 * time.Millisecond,
+		ClaimIdle:     100 * time.Millisecond,
+		MaxDeliveries: 2,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = queue.Run(ctx, "test")
+	}()
+
+	flight := &model.Flight{}
+	err = gdb.Last(flight).Error
+	require.NoError(t, err)
+
+	customer := &model.Customer{
+		Name:  gofakeit.Name(),
+		Email: gofakeit.Email(),
+		Phone: gofakeit.Phone(),
+	}
+	err = gdb.Save(customer).Error
+	require.NoError(t, err)
+
+	ticket, err := queue.Submit(ctx, CreateOrderRequest{
+		FlightID:     flight.ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TicketStatusPending, ticket.Status)
+
+	require.Eventually(t, func() bool {
+		ticket, err = queue.Ticket(ctx, ticket.ID)
+		return err == nil && ticket.Status == TicketStatusCompleted
+	}, 10*time.Second, 50*time.Millisecond)
+
+	order := &model.Order{}
+	err = gdb.First(order, &model.Order{OrderNumber: ticket.OrderNumber}).Error
+	require.NoError(t, err)
+	require.Equal(t, customer.ID, order.CustomerID)
+
+	check := &model.Flight{}
+	err = gdb.First(check, flight.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, flight.AvailableSeats-2, check.AvailableSeats)
+
+	// A message without a ticket can never succeed and is dead-lettered.
+	err = redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: constant.ORDER_STREAM,
+		Values: map[string]interface{}{"garbage": "1"},
+	}).Err()
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		n, err := redisClient.XLen(ctx, constant.ORDER_DEAD_LETTER_STREAM).Result()
+		return err == nil && n > 0
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestOrderQueue_ClaimStaleSavedOrder(t *testing.T) {
+	ctx := context.Background()
+	seats := inventory.NewRedisInventory(rc)
+	queue := NewOrderQueue(gdb, rc, seats, OrderQueueConfig{
+		BatchSize:     100,
+		ClaimIdle:     time.Millisecond,
+		MaxDeliveries: 1,
+	}).(*orderQueue)
+	err = redisClient.XGroupCreateMkStream(ctx, constant.ORDER_STREAM, constant.ORDER_CONSUMER_GROUP, "0").Err()
+	if err != nil {
+		require.ErrorContains(t, err, "BUSYGROUP")
+	}
+
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+	order, err := NewOrderService(gdb, seats, nil, BookingLimits{}).CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     createFlight(t, time.Now().Add(48*time.Hour)).ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 1,
+	})
+	require.NoError(t, err)
+
+	// A worker saved the order but died before acknowledging it
+	ticket := &OrderTicket{
+		ID:           uuid.New().String(),
+		Status:       TicketStatusPending,
+		FlightID:     order.FlightID,
+		CustomerID:   order.CustomerID,
+		TicketAmount: 1,
+		OrderNumber:  order.OrderNumber,
+	}
+	payload, err := json.Marshal(ticket)
+	require.NoError(t, err)
+	id, err := redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: constant.ORDER_STREAM,
+		Values: map[string]interface{}{"ticket": payload},
+	}).Result()
+	require.NoError(t, err)
+	_, err = redisClient.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    constant.ORDER_CONSUMER_GROUP,
+		Consumer: "crashed",
+		Streams:  []string{constant.ORDER_STREAM, ">"},
+		Count:    1000,
+	}).Result()
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// Its delivery is used up, yet it completes rather than being given up
+	messages, err := queue.claimStale(ctx, "test")
+	require.NoError(t, err)
+	for _, message := range messages {
+		require.NotEqual(t, id, message.ID)
+	}
+	ticket, err = queue.Ticket(ctx, ticket.ID)
+	require.NoError(t, err)
+	require.Equal(t, TicketStatusCompleted, ticket.Status)
+	dead, err := redisClient.XRange(ctx, constant.ORDER_DEAD_LETTER_STREAM, "-", "+").Result()
+	require.NoError(t, err)
+	for _, message := range dead {
+		require.NotEqual(t, id, message.Values["id"])
+	}
+	// Acknowledged orders are deleted from the queue
+	left, err := redisClient.XRange(ctx, constant.ORDER_STREAM, id, id).Result()
+	require.NoError(t, err)
+	require.Empty(t, left)
+}
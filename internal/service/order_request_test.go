@@ -0,0 +1,30 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrderRequest_Validate(t *testing.T) {
+	limits := BookingLimits{MaxTicketsPerOrder: 2}
+	named := func(names ...string) []TravelerName {
+		travelers := make([]TravelerName, len(names))
+		for i, name := range names {
+			travelers[i].FirstName, travelers[i].LastName, _ = strings.Cut(name, " ")
+		}
+		return travelers
+	}
+
+	require.NoError(t, CreateOrderRequest{TicketAmount: 2}.validate(limits))
+	require.NoError(t, CreateOrderRequest{TicketAmount: 3}.validate(BookingLimits{}))
+	require.ErrorIs(t, CreateOrderRequest{TicketAmount: 3}.validate(limits), ErrTooManyTickets)
+	require.NoError(t, CreateOrderRequest{TicketAmount: 2, Travelers: named("Mei Lin", "Wei Chen")}.validate(limits))
+	require.ErrorIs(t, CreateOrderRequest{TicketAmount: 2, Travelers: named("Mei Lin")}.validate(limits), ErrInvalidTravelers)
+	require.ErrorIs(t, CreateOrderRequest{TicketAmount: 1, Travelers: named("Mei ")}.validate(limits), ErrInvalidTravelers)
+	require.ErrorIs(t, CreateOrderRequest{TicketAmount: 1, Travelers: named("Mei " + strings.Repeat("L", 51))}.validate(limits), ErrInvalidTravelers)
+	require.NoError(t, CreateOrderRequest{TicketAmount: 1, Travelers: named("Zoë Dvořák")}.validate(limits))
+	require.ErrorIs(t, CreateOrderRequest{TicketAmount: 1, Travelers: named("美玲 林")}.validate(limits), ErrInvalidTravelers)
+	require.ErrorIs(t, CreateOrderRequest{TicketAmount: 1, Travelers: named("Mei -")}.validate(limits), ErrInvalidTravelers)
+}
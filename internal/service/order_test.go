@@ -1,59 +1,30 @@
+//go:build integration
+
 package service
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
-	"github.com/ory/dockertest/v3"
-	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
 	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/inventory"
 	"github.com/joremysh/tonx/internal/model"
 	"github.com/joremysh/tonx/internal/repository"
-	"github.com/joremysh/tonx/pkg/cache"
-	testingx "github.com/joremysh/tonx/pkg/testing"
-)
-
-var (
-	gdb           *gorm.DB
-	err           error
-	pool          *dockertest.Pool
-	resource      *dockertest.Resource
-	redisResource *dockertest.Resource
-	redisClient   *redis.Client
-	rc            *cache.RedisClient
+	"github.com/joremysh/tonx/pkg/breaker"
 )
 
-func TestMain(m *testing.M) {
-	pool, resource, gdb, redisResource, redisClient, err = testingx.NewTestContainers()
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	err = repository.Migrate(gdb)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	rc = &cache.RedisClient{Client: redisClient}
-
-	defer func() {
-		if err = pool.Purge(resource); err != nil {
-			log.Fatal(err.Error())
-		}
-		if err = pool.Purge(redisResource); err != nil {
-			log.Fatal(err.Error())
-		}
-	}()
-
-	m.Run()
-}
-
 func TestOrderService_CreateOrder(t *testing.T) {
-	svc := NewOrderService(gdb, rc, nil)
+	svc := NewOrderService(gdb, inventory.NewRedisInventory(rc), nil, BookingLimits{})
 
 	flight := &model.Flight{}
 	err = gdb.First(flight).Error
@@ -98,7 +69,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 }
 
 func TestOrderService_CreateOrderWithNotEnoughTicket(t *testing.T) {
-	svc := NewOrderService(gdb, rc, nil)
+	svc := NewOrderService(gdb, inventory.NewRedisInventory(rc), nil, BookingLimits{})
 
 	flight := &model.Flight{}
 	err = gdb.First(flight).Error
@@ -135,7 +106,7 @@ func TestOrderService_CreateOrderWithNotEnoughTicket(t *testing.T) {
 }
 
 func TestOrderService_CreateOrderMultipleTimesInSerial(t *testing.T) {
-	svc := NewOrderService(gdb, rc, nil)
+	svc := NewOrderService(gdb, inventory.NewRedisInventory(rc), nil, BookingLimits{})
 
 	flight := &model.Flight{}
 	err = gdb.First(flight).Error
@@ -177,7 +148,7 @@ func TestOrderService_CreateOrderMultipleTimesInSerial(t *testing.T) {
 }
 
 func TestOrderService_CreateOrder_Concurrent(t *testing.T) {
-	svc := NewOrderService(gdb, rc, nil)
+	svc := NewOrderService(gdb, inventory.NewRedisInventory(rc), nil, BookingLimits{})
 	ctx := context.Background()
 
 	testCases := []struct {
@@ -331,3 +302,269 @@ func TestOrderService_CreateOrder_Concurrent(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderService_CreateOrder_ConcurrentWithFailures(t *testing.T) {
+	svc := NewOrderService(gdb, inventory.NewRedisInventory(rc), nil, BookingLimits{})
+	ctx := context.Background()
+
+	flight := &model.Flight{}
+	err = gdb.First(flight).Error
+	require.NoError(t, err)
+	flight.AvailableSeats = 100
+	err = gdb.Save(flight).Error
+	require.NoError(t, err)
+	err = rc.Delete(ctx, flight.FlightKey())
+	require.NoError(t, err)
+
+	customer := &model.Customer{
+		Name:  gofakeit.Name(),
+		Email: gofakeit.Email(),
+		Phone: gofakeit.Phone(),
+	}
+	err = gdb.Save(customer).Error
+	require.NoError(t, err)
+
+	// Fail every third order insert, so failed bookings release their seats
+	// while others are reserving and committing theirs.
+	var inserts atomic.Int64
+	callback := "test:fail_order_inserts"
+	err = gdb.Callback().Create().Before("gorm:create").Register(callback, func(db *gorm.DB) {
+		if db.Statement.Schema != nil && db.Statement.Schema.Table == "orders" && inserts.Add(1)%3 == 0 {
+			_ = db.AddError(errors.New("injected failure"))
+		}
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = gdb.Callback().Create().Remove(callback)
+	})
+
+	numGoroutines := 30
+	ticketAmount := 2
+	var wg sync.WaitGroup
+	var successCount atomic.Int64
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.CreateOrder(ctx, CreateOrderRequest{
+				FlightID:     flight.ID,
+				CustomerID:   customer.ID,
+				TicketAmount: ticketAmount,
+			}); err == nil {
+				successCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Less(t, successCount.Load(), int64(numGoroutines))
+
+	expectedSeats := flight.AvailableSeats - int(successCount.Load())*ticketAmount
+	var finalFlight model.Flight
+	err = gdb.First(&finalFlight, flight.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, expectedSeats, finalFlight.AvailableSeats)
+
+	var redisSeats int
+	err = rc.Get(ctx, flight.FlightKey(), &redisSeats)
+	require.NoError(t, err)
+	require.Equal(t, expectedSeats, redisSeats, "Redis seats count mismatch")
+
+	reservations, err := rc.Client.Keys(ctx, fmt.Sprintf(constant.RESERVATION_KEY, flight.ID, "*")).Result()
+	require.NoError(t, err)
+	require.Empty(t, reservations)
+}
+
+func TestOrderService_ReleaseSeatsOnce(t *testing.T) {
+	svc := &orderService{gdb: gdb, seats: inventory.NewRedisInventory(rc)}
+	ctx := context.Background()
+
+	flight := &model.Flight{}
+	err = gdb.First(flight).Error
+	require.NoError(t, err)
+	err = rc.Delete(ctx, flight.FlightKey())
+	require.NoError(t, err)
+	err = svc.InitializeFlightSeats(ctx, flight.ID, 10)
+	require.NoError(t, err)
+
+	reservation, err := svc.reserveSeats(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: 1, TicketAmount: 3}, constant.ReservationTTL)
+	require.NoError(t, err)
+
+	// Another booking takes seats in between, releasing must not undo it.
+	other, err := svc.reserveSeats(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: 2, TicketAmount: 2}, constant.ReservationTTL)
+	require.NoError(t, err)
+	svc.confirmSeats(ctx, flight.ID, 2, other)
+
+	svc.releaseSeats(ctx, flight.ID, 1, reservation)
+	svc.releaseSeats(ctx, flight.ID, 1, reservation)
+	svc.releaseSeats(ctx, flight.ID, 2, other)
+
+	var redisSeats int
+	err = rc.Get(ctx, flight.FlightKey(), &redisSeats)
+	require.NoError(t, err)
+	require.Equal(t, 8, redisSeats)
+}
+
+// unavailableInventory fails every call like an unreachable Redis
+type unavailableInventory struct {
+	inventory.SeatInventory
+}
+
+func (unavailableInventory) Reserve(context.Context, uint, inventory.Hold, time.Duration) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestOrderService_CreateOrderWithoutSeatInventory(t *testing.T) {
+	b := breaker.New(breaker.Settings{MaxFailures: 1, OpenTimeout: time.Minute})
+	svc := NewOrderService(gdb, inventory.WithBreaker(unavailableInventory{}, b), nil, BookingLimits{})
+	ctx := context.Background()
+
+	flight := &model.Flight{}
+	err = gdb.First(flight).Error
+	require.NoError(t, err)
+	flight.AvailableSeats = 3
+	err = gdb.Save(flight).Error
+	require.NoError(t, err)
+
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+
+	// The first booking trips the breaker, the second finds it open
+	for i := 0; i < 2; i++ {
+		order, err := svc.CreateOrder(ctx, CreateOrderRequest{
+			FlightID:     flight.ID,
+			CustomerID:   customer.ID,
+			TicketAmount: 1,
+		})
+		require.NoError(t, err)
+		require.NotZero(t, order.ID)
+	}
+	require.Equal(t, breaker.StateOpen, b.State())
+
+	// The database still refuses overbooking
+	_, err = svc.CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     flight.ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 2,
+	})
+	require.ErrorIs(t, err, ErrNoAvailableSeats)
+
+	check := &model.Flight{}
+	err = gdb.First(check, flight.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, 1, check.AvailableSeats)
+}
+
+func TestOrderService_BookingLimits(t *testing.T) {
+	seats := inventory.NewRedisInventory(rc)
+	svc := NewOrderService(gdb, seats, nil, BookingLimits{MaxTicketsPerOrder: 3, MaxTicketsPerFlight: 5})
+	ctx := context.Background()
+
+	flight := &model.Flight{}
+	err = gdb.Where("available_seats > ?", 50).First(flight).Error
+	require.NoError(t, err)
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+
+	_, err = svc.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 4})
+	require.ErrorIs(t, err, ErrTooManyTickets)
+
+	// Booked before the limits were counted in Redis
+	_, err = svc.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1})
+	require.NoError(t, err)
+	err = rc.Delete(ctx, fmt.Sprintf(constant.FLIGHT_CUSTOMER_KEY, flight.ID, customer.ID))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var booked atomic.Int64
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1})
+			if err != nil {
+				require.ErrorIs(t, err, ErrFlightSeatLimit)
+				return
+			}
+			booked.Add(1)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(4), booked.Load())
+
+	var orders int64
+	err = gdb.Model(&model.Order{}).Where("flight_id = ? AND customer_id = ?", flight.ID, customer.ID).Count(&orders).Error
+	require.NoError(t, err)
+	require.Equal(t, int64(5), orders)
+}
+
+func TestOrderQueue_PendingHoldLimit(t *testing.T) {
+	queue := NewOrderQueue(gdb, rc, inventory.NewRedisInventory(rc), OrderQueueConfig{
+		Limits: BookingLimits{MaxPendingHolds: 2},
+	})
+	ctx := context.Background()
+
+	flight := &model.Flight{}
+	err = gdb.Where("available_seats > ?", 10).First(flight).Error
+	require.NoError(t, err)
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+
+	// Queued orders hold their seats until a worker persists them
+	req := CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 1}
+	_, err = queue.Submit(ctx, req)
+	require.NoError(t, err)
+	_, err = queue.Submit(ctx, req)
+	require.NoError(t, err)
+	_, err = queue.Submit(ctx, req)
+	require.ErrorIs(t, err, ErrPendingHoldLimit)
+
+	other := repository.MockCustomer()
+	err = gdb.Create(other).Error
+	require.NoError(t, err)
+	_, err = queue.Submit(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: other.ID, TicketAmount: 1})
+	require.NoError(t, err)
+}
+
+func TestOrderService_CancelOrder(t *testing.T) {
+	seats := inventory.NewRedisInventory(rc)
+	svc := NewOrderService(gdb, seats, nil, BookingLimits{})
+	ctx := context.Background()
+
+	flight := createFlight(t, time.Now().Add(48*time.Hour))
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+
+	order, err := svc.CreateOrder(ctx, CreateOrderRequest{FlightID: flight.ID, CustomerID: customer.ID, TicketAmount: 2})
+	require.NoError(t, err)
+	var before model.Flight
+	err = gdb.First(&before, flight.ID).Error
+	require.NoError(t, err)
+	redisBefore, err := seats.Get(ctx, flight.ID)
+	require.NoError(t, err)
+
+	cancelled, err := svc.CancelOrder(ctx, order.ID)
+	require.NoError(t, err)
+	require.Equal(t, string(api.OrderStatusCANCELLED), cancelled.Status)
+	_, err = svc.CancelOrder(ctx, order.ID)
+	require.ErrorIs(t, err, ErrOrderNotCancellable)
+	_, err = svc.CancelOrder(ctx, 0)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+
+	var after model.Flight
+	err = gdb.First(&after, flight.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, before.AvailableSeats+2, after.AvailableSeats)
+	redisAfter, err := seats.Get(ctx, flight.ID)
+	require.NoError(t, err)
+	require.Equal(t, redisBefore+2, redisAfter)
+
+	// Every change of the order is in the outbox
+	var types []string
+	err = gdb.Model(&model.OutboxEvent{}).Where("aggregate_id = ?", order.ID).Order("created_at").Pluck("type", &types).Error
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{EventOrderCreated, EventOrderConfirmed, EventOrderCancelled}, types)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+// Event types written to the outbox
+const (
+	EventOrderCreated   = "order.created"
+	EventOrderConfirmed = "order.confirmed"
+	EventOrderCancelled = "order.cancelled"
+	EventFlightChanged  = "flight.changed"
+)
+
+// EventTypes lists every event type webhooks can subscribe to
+var EventTypes = []string{EventOrderCreated, EventOrderConfirmed, EventOrderCancelled, EventFlightChanged}
+
+// OrderEvent is the data of the order events
+type OrderEvent struct {
+	OrderID      uint      `json:"order_id"`
+	OrderNumber  string    `json:"order_number"`
+	FlightID     uint      `json:"flight_id"`
+	CustomerID   uint      `json:"customer_id"`
+	AgencyID     *uint     `json:"agency_id,omitempty"`
+	Status       string    `json:"status"`
+	TicketAmount int       `json:"ticket_amount"`
+	TotalAmount  int       `json:"total_amount"`
+	BookingTime  time.Time `json:"booking_time"`
+}
+
+func newOrderEvent(order *model.Order) OrderEvent {
+	return OrderEvent{
+		OrderID:      order.ID,
+		OrderNumber:  order.OrderNumber,
+		FlightID:     order.FlightID,
+		CustomerID:   order.CustomerID,
+		AgencyID:     order.AgencyID,
+		Status:       order.Status,
+		TicketAmount: order.TicketAmount,
+		TotalAmount:  order.TotalAmount,
+		BookingTime:  order.BookingTime,
+	}
+}
+
+// FlightChangedEvent is the data of flight.changed events
+type FlightChangedEvent struct {
+	FlightID              uint      `json:"flight_id"`
+	FlightNumber          string    `json:"flight_number"`
+	PreviousStatus        string    `json:"previous_status"`
+	Status                string    `json:"status"`
+	PreviousDepartureTime time.Time `json:"previous_departure_time"`
+	PreviousArrivalTime   time.Time `json:"previous_arrival_time"`
+	DepartureTime         time.Time `json:"departure_time"`
+	ArrivalTime           time.Time `json:"arrival_time"`
+	Reason                string    `json:"reason"`
+	AffectedOrders        int       `json:"affected_orders"`
+	EntitlementsGranted   int       `json:"entitlements_granted"`
+}
+
+func newFlightChangedEvent(flight *model.Flight, history *model.FlightStatusHistory) FlightChangedEvent {
+	return FlightChangedEvent{
+		FlightID:              flight.ID,
+		FlightNumber:          flight.FlightNumber,
+		PreviousStatus:        history.PreviousStatus,
+		Status:                history.Status,
+		PreviousDepartureTime: history.PreviousDepartureTime,
+		PreviousArrivalTime:   history.PreviousArrivalTime,
+		DepartureTime:         history.DepartureTime,
+		ArrivalTime:           history.ArrivalTime,
+		Reason:                history.Reason,
+		AffectedOrders:        history.AffectedOrders,
+		EntitlementsGranted:   history.EntitlementsGranted,
+	}
+}
+
+// recordEvent writes an event to the outbox within tx, so it is only
+// published if the change it describes is committed.
+func recordEvent(tx *gorm.DB, eventType string, aggregateID uint, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	event := &model.OutboxEvent{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     string(payload),
+	}
+	if err = tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to write %s event: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/cache"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+// Reconciler defines the interface for repairing seat inventory drift
+type Reconciler interface {
+	// Reconcile compares the seats of every active flight in the database and
+	// Redis with its orders, and repairs them unless dryRun is set
+	Reconcile(ctx context.Context, dryRun bool) (*ReconcileReport, error)
+	// Run reconciles every interval until ctx is done, on only one of the
+	// replicas running it each interval
+	Run(ctx context.Context, interval time.Duration)
+	// Rebuild sets the Redis counters of every active flight from the
+	// database, for when Redis missed bookings while it was unavailable
+	Rebuild(ctx context.Context) error
+}
+
+// SeatDrift describes a flight whose seat counts disagree with its orders
+type SeatDrift struct {
+	FlightID uint
+	// Expected is the total seats minus the tickets of non-cancelled orders
+	Expected int
+	Database int
+	// Redis is nil when the flight has no Redis counter
+	Redis *int
+	// Held is the number of seats held by queued orders not persisted yet
+	Held int
+}
+
+// ReconcileReport summarizes a reconciliation run
+type ReconcileReport struct {
+	Checked          int
+	DatabaseRepaired int
+	RedisRepaired    int
+	Drifts           []SeatDrift
+}
+
+// reconciler implements Reconciler
+type reconciler struct {
+	gdb         *gorm.DB
+	redisClient *cache.RedisClient
+	grace       time.Duration
+	// id tells the replica holding the lock of scheduled runs
+	id string
+}
+
+// NewReconciler creates a new instance of Reconciler. A Redis counter is only
+// repaired when it still disagrees after grace, so reservations of bookings in
+// progress are not mistaken for drift.
+func NewReconciler(gdb *gorm.DB, redisClient *cache.RedisClient, grace time.Duration) Reconciler {
+	return &reconciler{
+		gdb:         gdb,
+		redisClient: redisClient,
+		grace:       grace,
+		id:          uuid.New().String(),
+	}
+}
+
+func (r *reconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Reconciling locks every active flight, so the replicas take
+			// turns. The lock expires with the interval rather than being
+			// released, or a replica ticking later would run again.
+			elected, err := r.redisClient.Client.SetNX(ctx, constant.RECONCILE_LOCK_KEY, r.id, interval).Result()
+			if err != nil {
+				log.Printf("failed to elect a reconciler: %v\n", err)
+				continue
+			}
+			if !elected {
+				continue
+			}
+			if _, err := r.Reconcile(ctx, false); err != nil {
+				log.Printf("failed to reconcile seats: %v\n", err)
+			}
+		}
+	}
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, dryRun bool) (*ReconcileReport, error) {
+	flightIDs, err := r.activeFlights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{Checked: len(flightIDs)}
+	suspects := make(map[uint]SeatDrift)
+	for _, flightID := range flightIDs {
+		drift, err := r.reconcileDatabase(ctx, flightID, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if drift.Database != drift.Expected {
+			report.Drifts = append(report.Drifts, drift)
+			if !dryRun {
+				report.DatabaseRepaired++
+			}
+		}
+		if drift.Redis != nil && *drift.Redis != redisTarget(drift.Expected, drift.Held) {
+			suspects[flightID] = drift
+		}
+	}
+
+	// Check the Redis counters again after the grace period, bookings in
+	// progress have settled by then.
+	if len(suspects) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(r.grace):
+		}
+	}
+	for flightID, before := range suspects {
+		drift, err := r.reconcileRedis(ctx, flightID, before, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if drift == nil {
+			continue
+		}
+		if drift.Database == drift.Expected {
+			report.Drifts = append(report.Drifts, *drift)
+		}
+		if !dryRun {
+			report.RedisRepaired++
+		}
+	}
+
+	for _, drift := range report.Drifts {
+		redisSeats := "none"
+		if drift.Redis != nil {
+			redisSeats = strconv.Itoa(*drift.Redis)
+		}
+		log.Printf("seat drift on flight %d: expected %d, database %d, redis %s, held %d\n",
+			drift.FlightID, drift.Expected, drift.Database, redisSeats, drift.Held)
+	}
+	log.Printf("reconciled %d flights: %d drifted, %d repaired in database, %d repaired in redis\n",
+		report.Checked, len(report.Drifts), report.DatabaseRepaired, report.RedisRepaired)
+
+	metrics.Inc("reconcile_runs")
+	metrics.Add("reconcile_flights_checked", int64(report.Checked))
+	metrics.Add("reconcile_flights_drifted", int64(len(report.Drifts)))
+	metrics.Add("reconcile_database_repaired", int64(report.DatabaseRepaired))
+	metrics.Add("reconcile_redis_repaired", int64(report.RedisRepaired))
+	return report, nil
+}
+
+func (r *reconciler) Rebuild(ctx context.Context) error {
+	flightIDs, err := r.activeFlights(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The counters are read before the flights, and only set if they did not
+	// move since, so a booking made meanwhile is not discarded. A reservation
+	// not committed yet leaves the counter too high, which the database
+	// catches, and a counter that keeps moving is left to the next
+	// reconciliation.
+	rebuilt := 0
+	for _, flightID := range flightIDs {
+		for attempt := 0; attempt < rebuildAttempts; attempt++ {
+			ok, err := r.rebuildFlight(ctx, flightID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				rebuilt++
+				break
+			}
+		}
+	}
+
+	log.Printf("rebuilt the seat counters of %d of %d flights\n", rebuilt, len(flightIDs))
+	metrics.Inc("seat_counter_rebuilds")
+	return nil
+}
+
+// rebuildAttempts is how many times Rebuild tries to set a counter that
+// bookings keep moving
+const rebuildAttempts = 3
+
+// rebuildFlight sets the Redis counter of a flight from the database, and
+// reports false if it moved while the flight was read.
+func (r *reconciler) rebuildFlight(ctx context.Context, flightID uint) (bool, error) {
+	seats, held, err := r.redisSeats(ctx, flightID)
+	if err != nil {
+		return false, err
+	}
+	var flight model.Flight
+	if err = r.gdb.WithContext(ctx).First(&flight, flightID).Error; err != nil {
+		return false, fmt.Errorf("failed to get flight: %w", err)
+	}
+
+	expected := ""
+	if seats != nil {
+		expected = strconv.Itoa(*seats)
+	}
+	set, err := r.redisClient.Client.Eval(ctx, constant.RebuildSeatsScript, []string{flight.FlightKey(), heldSeatsKey(flightID)},
+		expected, held, redisTarget(flight.AvailableSeats, held), int((24 * time.Hour).Seconds())).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to rebuild seats in Redis: %w", err)
+	}
+	return set == 1, nil
+}
+
+// activeFlights returns the IDs of the flights that can still be booked.
+func (r *reconciler) activeFlights(ctx context.Context) ([]uint, error) {
+	var flightIDs []uint
+	if err := r.gdb.WithContext(ctx).Model(&model.Flight{}).
+		Where("status IN ? AND departure_time > ?", []string{string(api.FlightStatusSCHEDULED), string(api.FlightStatusDELAYED)}, time.Now()).
+		Pluck("id", &flightIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list active flights: %w", err)
+	}
+	return flightIDs, nil
+}
+
+// reconcileDatabase repairs the available seats column of a flight. The
+// flight is locked like in order creation, so the orders cannot change while
+// they are counted.
+func (r *reconciler) reconcileDatabase(ctx context.Context, flightID uint, dryRun bool) (SeatDrift, error) {
+	drift := SeatDrift{FlightID: flightID}
+	err := r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var flight model.Flight
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flight, flightID).Error; err != nil {
+			return fmt.Errorf("failed to lock flight record: %w", err)
+		}
+		expected, err := expectedSeats(tx, &flight)
+		if err != nil {
+			return err
+		}
+		drift.Expected = expected
+		drift.Database = flight.AvailableSeats
+
+		if drift.Database != expected && !dryRun {
+			if err = tx.Model(&flight).Update("available_seats", expected).Error; err != nil {
+				return fmt.Errorf("failed to repair flight seats: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return drift, err
+	}
+
+	drift.Redis, drift.Held, err = r.redisSeats(ctx, flightID)
+	return drift, err
+}
+
+// reconcileRedis repairs the Redis counter of a flight if it disagrees with
+// the orders and has not moved since before, and returns the drift if any.
+// Counters that move before they are repaired are left alone and return no
+// drift.
+func (r *reconciler) reconcileRedis(ctx context.Context, flightID uint, before SeatDrift, dryRun bool) (*SeatDrift, error) {
+	var flight model.Flight
+	if err := r.gdb.WithContext(ctx).First(&flight, flightID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	expected, err := expectedSeats(r.gdb.WithContext(ctx), &flight)
+	if err != nil {
+		return nil, err
+	}
+	redisSeats, held, err := r.redisSeats(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if redisSeats == nil || *redisSeats != *before.Redis || *redisSeats == redisTarget(expected, held) {
+		// Either moved by a booking or settled by itself
+		return nil, nil
+	}
+
+	drift := &SeatDrift{
+		FlightID: flightID,
+		Expected: expected,
+		Database: before.Database,
+		Redis:    redisSeats,
+		Held:     held,
+	}
+	if dryRun {
+		return drift, nil
+	}
+
+	flightKey := fmt.Sprintf(constant.FLIGHT_KEY, flightID)
+	set, err := r.redisClient.Client.Eval(ctx, constant.CompareAndSetSeatsScript, []string{flightKey}, *redisSeats, redisTarget(expected, held)).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair seats in Redis: %w", err)
+	}
+	if set == 0 {
+		// Moved by a booking since it was read
+		return nil, nil
+	}
+	return drift, nil
+}
+
+// redisSeats returns the Redis counter of a flight, nil if there is none, and
+// the seats held by its queued orders whose holds did not expire.
+func (r *reconciler) redisSeats(ctx context.Context, flightID uint) (*int, int, error) {
+	values, err := r.redisClient.Client.Eval(ctx, constant.ReadSeatsScript,
+		[]string{fmt.Sprintf(constant.FLIGHT_KEY, flightID), heldSeatsKey(flightID)},
+	).Slice()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get seats from Redis: %w", err)
+	}
+
+	var seats *int
+	if s, _ := values[0].(string); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid seat counter of flight %d: %w", flightID, err)
+		}
+		seats = &n
+	}
+	held, _ := values[1].(int64)
+	return seats, int(held), nil
+}
+
+// redisTarget returns the Redis counter a flight should have: the seats left
+// in the database less the live holds of queued orders. A queued order that
+// was persisted but not dropped yet is counted twice for a moment, so the
+// counter stops at 0 rather than going negative.
+func redisTarget(expected, held int) int {
+	return max(0, expected-held)
+}
+
+// expectedSeats returns the total seats of a flight minus the tickets of its
+// non-cancelled orders. Orders from before ticket amounts were recorded are
+// counted from their total amount.
+func expectedSeats(tx *gorm.DB, flight *model.Flight) (int, error) {
+	basePrice := max(flight.BasePrice, 1)
+	var booked int
+	if err := tx.Model(&model.Order{}).
+		Select("COALESCE(SUM(CASE WHEN ticket_amount > 0 THEN ticket_amount ELSE total_amount DIV ? END), 0)", basePrice).
+		Where("flight_id = ? AND status <> ?", flight.ID, string(api.OrderStatusCANCELLED)).
+		Scan(&booked).Error; err != nil {
+		return 0, fmt.Errorf("failed to count booked seats: %w", err)
+	}
+	return flight.TotalSeats - booked, nil
+}
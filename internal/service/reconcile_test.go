@@ -0,0 +1,111 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/api"
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+
+	flight := repository.MockFlight()
+	flight.FlightNumber = "RC" + gofakeit.DigitN(6)
+	flight.DepartureTime = time.Now().Add(48 * time.Hour)
+	flight.ArrivalTime = flight.DepartureTime.Add(2 * time.Hour)
+	flight.TotalSeats = 50
+	flight.AvailableSeats = 50
+	err = gdb.Create(flight).Error
+	require.NoError(t, err)
+
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+
+	// Orders written behind the back of the seat counters
+	for _, status := range []api.OrderStatus{api.OrderStatusCOMPLETED, api.OrderStatusCANCELLED} {
+		err = gdb.Create(&model.Order{
+			FlightID:     flight.ID,
+			CustomerID:   customer.ID,
+			Status:       string(status),
+			TotalAmount:  5 * flight.BasePrice,
+			TicketAmount: 5,
+			OrderNumber:  generateOrderNumber("RC"),
+			BookingTime:  time.Now(),
+		}).Error
+		require.NoError(t, err)
+	}
+	err = redisClient.Set(ctx, flight.FlightKey(), 50, time.Hour).Err()
+	require.NoError(t, err)
+
+	reconciler := NewReconciler(gdb, rc, 10*time.Millisecond)
+	report, err := reconciler.Reconcile(ctx, true)
+	require.NoError(t, err)
+	require.Zero(t, report.DatabaseRepaired)
+	require.Zero(t, report.RedisRepaired)
+
+	report, err = reconciler.Reconcile(ctx, false)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, report.DatabaseRepaired, 1)
+	require.GreaterOrEqual(t, report.RedisRepaired, 1)
+
+	check := &model.Flight{}
+	err = gdb.First(check, flight.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, 45, check.AvailableSeats)
+
+	seats, err := redisClient.Get(ctx, flight.FlightKey()).Int()
+	require.NoError(t, err)
+	require.Equal(t, 45, seats)
+}
+
+func TestReconciler_Rebuild(t *testing.T) {
+	ctx := context.Background()
+
+	flight := repository.MockFlight()
+	flight.FlightNumber = "RB" + gofakeit.DigitN(6)
+	flight.DepartureTime = time.Now().Add(48 * time.Hour)
+	flight.ArrivalTime = flight.DepartureTime.Add(2 * time.Hour)
+	flight.TotalSeats = 50
+	flight.AvailableSeats = 30
+	err = gdb.Create(flight).Error
+	require.NoError(t, err)
+
+	// Redis missed the bookings made while it was unavailable, and holds
+	// seats of a queued order. The hold of an order whose drop was lost has
+	// expired and is not counted.
+	err = redisClient.Set(ctx, flight.FlightKey(), 50, time.Hour).Err()
+	require.NoError(t, err)
+	err = redisClient.Eval(ctx, constant.HoldSeatsScript, []string{heldSeatsKey(flight.ID)}, "queued", 4, 3600).Err()
+	require.NoError(t, err)
+	err = redisClient.HSet(ctx, heldSeatsKey(flight.ID), "lost", "6:1").Err()
+	require.NoError(t, err)
+
+	err = NewReconciler(gdb, rc, 0).Rebuild(ctx)
+	require.NoError(t, err)
+
+	seats, err := redisClient.Get(ctx, flight.FlightKey()).Int()
+	require.NoError(t, err)
+	require.Equal(t, 26, seats)
+	require.False(t, redisClient.HExists(ctx, heldSeatsKey(flight.ID), "lost").Val())
+
+	// Lost counters are created again
+	err = redisClient.Del(ctx, flight.FlightKey(), heldSeatsKey(flight.ID)).Err()
+	require.NoError(t, err)
+	err = NewReconciler(gdb, rc, 0).Rebuild(ctx)
+	require.NoError(t, err)
+	seats, err = redisClient.Get(ctx, flight.FlightKey()).Int()
+	require.NoError(t, err)
+	require.Equal(t, 30, seats)
+	require.Positive(t, redisClient.TTL(ctx, flight.FlightKey()).Val())
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/internal/importer"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule defines the interface for recurring flights and the flights
+// generated from them
+type Schedule interface {
+	// CreateSchedule stores a schedule and generates its flights
+	CreateSchedule(ctx context.Context, schedule *model.Schedule) error
+	ListSchedules(ctx context.Context) ([]model.Schedule, error)
+	// Generate creates the flights of every schedule departing between now
+	// and the horizon that do not exist yet. Existing flights are left as
+	// they are, they may have been booked or rescheduled. A schedule that
+	// fails does not stop the others, its error is returned with the report.
+	Generate(ctx context.Context, now time.Time) (*GenerateReport, error)
+	// Run generates every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// GenerateReport summarizes a generation run
+type GenerateReport struct {
+	Schedules int
+	Created   int
+	// Failed counts the schedules whose flights could not be generated
+	Failed int
+}
+
+// scheduleService implements Schedule
+type scheduleService struct {
+	gdb     *gorm.DB
+	horizon int
+}
+
+// NewScheduleService creates a new instance of Schedule that generates
+// flights horizon days ahead.
+func NewScheduleService(gdb *gorm.DB, horizon int) Schedule {
+	return &scheduleService{
+		gdb:     gdb,
+		horizon: horizon,
+	}
+}
+
+func (s *scheduleService) CreateSchedule(ctx context.Context, schedule *model.Schedule) error {
+	if err := importer.NormalizeSchedule(schedule); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+	if err := s.gdb.WithContext(ctx).Create(schedule).Error; err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+	if _, err := s.generate(ctx, schedule, time.Now()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *scheduleService) ListSchedules(ctx context.Context) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	if err := s.gdb.WithContext(ctx).Order("flight_number, effective_from").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (s *scheduleService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Generate(ctx, time.Now()); err != nil {
+			log.Printf("failed to generate scheduled flights: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *scheduleService) Generate(ctx context.Context, now time.Time) (*GenerateReport, error) {
+	var schedules []model.Schedule
+	if err := s.gdb.WithContext(ctx).
+		Where("effective_to IS NULL OR effective_to >= ?", model.Date(now).AddDate(0, 0, -1)).
+		Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	report := &GenerateReport{Schedules: len(schedules)}
+	var errs []error
+	for i := range schedules {
+		created, err := s.generate(ctx, &schedules[i], now)
+		if err != nil {
+			report.Failed++
+			errs = append(errs, err)
+			continue
+		}
+		report.Created += created
+	}
+
+	log.Printf("generated %d flights from %d schedules, %d failed\n", report.Created, report.Schedules, report.Failed)
+	metrics.Inc("schedule_runs")
+	metrics.Add("schedule_flights_created", int64(report.Created))
+	metrics.Add("schedule_failures", int64(report.Failed))
+	return report, errors.Join(errs...)
+}
+
+// generate creates the missing flights of a schedule departing between now
+// and the horizon. Flights already there, from an earlier run, another
+// replica or an import, are skipped by the unique flight number and date.
+func (s *scheduleService) generate(ctx context.Context, schedule *model.Schedule, now time.Time) (int, error) {
+	// A day either side covers the local dates of every time zone
+	period, ok, err := importer.SchedulePeriod(schedule, now.AddDate(0, 0, -1), now.AddDate(0, 0, s.horizon+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schedule %d: %w", schedule.ID, err)
+	}
+	if !ok {
+		return 0, nil
+	}
+	instances, errs := importer.Expand([]importer.Period{period})
+	if len(errs) > 0 {
+		return 0, fmt.Errorf("failed to expand schedule %d: %w", schedule.ID, errs[0])
+	}
+
+	horizon := now.AddDate(0, 0, s.horizon)
+	var flights []*model.Flight
+	for i := range instances {
+		flight := &instances[i].Flight
+		if flight.DepartureTime.Before(now) || flight.DepartureTime.After(horizon) {
+			continue
+		}
+		flight.ScheduleID = &schedule.ID
+		flights = append(flights, flight)
+	}
+	if len(flights) == 0 {
+		return 0, nil
+	}
+
+	result := s.gdb.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(flights, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to create flights of schedule %d: %w", schedule.ID, result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
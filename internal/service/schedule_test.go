@@ -0,0 +1,82 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/model"
+)
+
+func TestScheduleService_Generate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	schedule := &model.Schedule{
+		FlightNumber:     "BR" + gofakeit.DigitN(4),
+		DepartureAirport: "TPE",
+		ArrivalAirport:   "NRT",
+		DaysOfWeek:       "1234567",
+		DepartureTime:    "08:00",
+		ArrivalTime:      "12:20",
+		EffectiveFrom:    now,
+		Aircraft:         "Airbus A330-300",
+	}
+	scheduleService := NewScheduleService(gdb, 7)
+	err = scheduleService.CreateSchedule(ctx, schedule)
+	require.NoError(t, err)
+
+	var flights []model.Flight
+	err = gdb.Where("schedule_id = ?", schedule.ID).Order("departure_time").Find(&flights).Error
+	require.NoError(t, err)
+	// A week ahead, today's flight only if it has not left yet
+	require.GreaterOrEqual(t, len(flights), 6)
+	require.LessOrEqual(t, len(flights), 8)
+	for _, flight := range flights {
+		require.Equal(t, schedule.FlightNumber, flight.FlightNumber)
+		require.Equal(t, "Taipei", flight.DepartureCity)
+		require.Equal(t, "Tokyo", flight.ArrivalCity)
+		require.Equal(t, 8, flight.DepartureTime.In(time.FixedZone("", 8*3600)).Hour())
+		require.True(t, flight.DepartureTime.After(now))
+	}
+
+	// Generating again creates nothing new, further ahead only what is missing
+	report, err := scheduleService.Generate(ctx, now)
+	require.NoError(t, err)
+	var count int64
+	err = gdb.Model(&model.Flight{}).Where("schedule_id = ?", schedule.ID).Count(&count).Error
+	require.NoError(t, err)
+	require.Equal(t, int64(len(flights)), count)
+	require.GreaterOrEqual(t, report.Schedules, 1)
+
+	report, err = NewScheduleService(gdb, 14).Generate(ctx, now)
+	require.NoError(t, err)
+	err = gdb.Model(&model.Flight{}).Where("schedule_id = ?", schedule.ID).Count(&count).Error
+	require.NoError(t, err)
+	require.Equal(t, int64(len(flights)+7), count)
+	require.GreaterOrEqual(t, report.Created, 7)
+
+	// A broken schedule is reported without holding the others back
+	broken := *schedule
+	broken.ID = 0
+	broken.FlightNumber = "BR" + gofakeit.DigitN(4)
+	broken.DepartureAirport = "ZZZ"
+	err = gdb.Create(&broken).Error
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		gdb.Delete(&broken)
+	})
+
+	report, err = NewScheduleService(gdb, 21).Generate(ctx, now)
+	require.ErrorContains(t, err, fmt.Sprintf("schedule %d", broken.ID))
+	require.Equal(t, 1, report.Failed)
+	err = gdb.Model(&model.Flight{}).Where("schedule_id = ?", schedule.ID).Count(&count).Error
+	require.NoError(t, err)
+	require.Equal(t, int64(len(flights)+14), count)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/repository"
+	"github.com/joremysh/tonx/pkg/cache"
+)
+
+var (
+	ErrUnknownWaitingToken = errors.New("unknown or expired waiting room token")
+	ErrNotAdmitted         = errors.New("missing or invalid admission token")
+)
+
+// WaitingRoom defines the interface for admission control in front of booking
+type WaitingRoom interface {
+	// Join queues a visitor for a flight and returns their position token.
+	// It returns ErrFlightNotFound for flights that do not exist.
+	Join(ctx context.Context, flightID uint) (*WaitingRoomStatus, error)
+	// Status returns the position of a visitor, or whether they are admitted
+	Status(ctx context.Context, flightID uint, token string) (*WaitingRoomStatus, error)
+	// Admit consumes the admission of token for a booking on a flight. The
+	// returned function gives the admission back, for bookings that fail.
+	Admit(ctx context.Context, flightID uint, token string) (func(), error)
+}
+
+// WaitingRoomStatus is the state of a visitor in a flight's waiting room
+type WaitingRoomStatus struct {
+	Token    string
+	FlightID uint
+	Admitted bool
+	// Position is the 1-based place in the queue of a visitor not admitted yet
+	Position int64
+	// ExpiresIn is how long an admitted visitor has left to book
+	ExpiresIn time.Duration
+}
+
+// WaitingRoomConfig holds the tunables of the waiting room
+type WaitingRoomConfig struct {
+	// Enabled makes booking require an admission token
+	Enabled bool
+	// Rate is the number of visitors admitted per second and flight
+	Rate float64
+	// Burst is the number of visitors that may be admitted at once after a
+	// quiet period
+	Burst int
+	// AdmissionTTL is how long an admitted visitor has to book
+	AdmissionTTL time.Duration
+}
+
+// waitingRoom implements WaitingRoom on Redis. Visitors are kept in a sorted
+// set per flight, ordered by arrival, and admitted from its head whenever any
+// visitor joins or polls.
+type waitingRoom struct {
+	flightRepo  repository.Flight
+	redisClient *cache.RedisClient
+	cfg         WaitingRoomConfig
+}
+
+// NewWaitingRoom creates a new instance of WaitingRoom
+func NewWaitingRoom(flightRepo repository.Flight, redisClient *cache.RedisClient, cfg WaitingRoomConfig) WaitingRoom {
+	return &waitingRoom{
+		flightRepo:  flightRepo,
+		redisClient: redisClient,
+		cfg:         cfg,
+	}
+}
+
+func (w *waitingRoom) Join(ctx context.Context, flightID uint) (*WaitingRoomStatus, error) {
+	// Check the flight before creating any key, so unknown IDs leave none
+	if _, err := w.flightRepo.Get(flightID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
+	}
+
+	queueKey := fmt.Sprintf(constant.WAITING_ROOM_QUEUE_KEY, flightID)
+	seqKey := fmt.Sprintf(constant.WAITING_ROOM_SEQ_KEY, flightID)
+
+	// Scores come from a counter rather than the clock, so visitors arriving
+	// at the same moment on different servers still get a strict order.
+	seq, err := w.redisClient.Client.Incr(ctx, seqKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	token := uuid.New().String()
+
+	pipe := w.redisClient.Client.TxPipeline()
+	pipe.ZAdd(ctx, queueKey, redis.Z{Score: float64(seq), Member: token})
+	pipe.Expire(ctx, queueKey, constant.WaitingRoomQueueTTL)
+	pipe.Expire(ctx, seqKey, constant.WaitingRoomQueueTTL)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return w.Status(ctx, flightID, token)
+}
+
+func (w *waitingRoom) Status(ctx context.Context, flightID uint, token string) (*WaitingRoomStatus, error) {
+	if err := w.admit(ctx, flightID); err != nil {
+		return nil, err
+	}
+
+	status := &WaitingRoomStatus{
+		Token:    token,
+		FlightID: flightID,
+	}
+	ttl, err := w.redisClient.Client.TTL(ctx, w.admissionKey(flightID, token)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		status.Admitted = true
+		status.ExpiresIn = ttl
+		return status, nil
+	}
+
+	rank, err := w.redisClient.Client.ZRank(ctx, fmt.Sprintf(constant.WAITING_ROOM_QUEUE_KEY, flightID), token).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrUnknownWaitingToken
+		}
+		return nil, err
+	}
+	status.Position = rank + 1
+	return status, nil
+}
+
+func (w *waitingRoom) Admit(ctx context.Context, flightID uint, token string) (func(), error) {
+	if token == "" {
+		return nil, ErrNotAdmitted
+	}
+	key := w.admissionKey(flightID, token)
+
+	// Consuming the admission up front keeps concurrent bookings from
+	// sharing one token.
+	pipe := w.redisClient.Client.TxPipeline()
+	ttlCmd := pipe.TTL(ctx, key)
+	delCmd := pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	ttl := ttlCmd.Val()
+	if delCmd.Val() == 0 || ttl <= 0 {
+		return nil, ErrNotAdmitted
+	}
+
+	restore := func() {
+		// The request context may be done by the time a booking fails.
+		_ = w.redisClient.Client.Set(context.Background(), key, "1", ttl).Err()
+	}
+	return restore, nil
+}
+
+// admit lets the visitors due by now through the waiting room of a flight.
+// No more than a burst can be admitted at once, so only that many visitors
+// at the head of the queue are offered to the script.
+func (w *waitingRoom) admit(ctx context.Context, flightID uint) error {
+	queueKey := fmt.Sprintf(constant.WAITING_ROOM_QUEUE_KEY, flightID)
+	head, err := w.redisClient.Client.ZRange(ctx, queueKey, 0, int64(max(w.cfg.Burst, 1))-1).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{queueKey, fmt.Sprintf(constant.WAITING_ROOM_BUCKET_KEY, flightID)}
+	args := []any{w.cfg.Rate, w.cfg.Burst, int(w.cfg.AdmissionTTL.Seconds())}
+	for _, token := range head {
+		keys = append(keys, w.admissionKey(flightID, token))
+		args = append(args, token)
+	}
+	return w.redisClient.Client.Eval(ctx, constant.AdmitFromWaitingRoomScript, keys, args...).Err()
+}
+
+func (w *waitingRoom) admissionKey(flightID uint, token string) string {
+	return fmt.Sprintf(constant.WAITING_ROOM_ADMISSION_KEY, flightID) + token
+}
@@ -0,0 +1,63 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/constant"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestWaitingRoom_AdmitsInOrder(t *testing.T) {
+	// No refill, so only the burst is ever admitted.
+	room := NewWaitingRoom(repository.NewFlightRepo(gdb), rc, WaitingRoomConfig{
+		Enabled:      true,
+		Rate:         0,
+		Burst:        1,
+		AdmissionTTL: time.Minute,
+	})
+	ctx := context.Background()
+	flightID := createFlight(t, time.Now().Add(24*time.Hour)).ID
+
+	first, err := room.Join(ctx, flightID)
+	require.NoError(t, err)
+	require.True(t, first.Admitted)
+
+	second, err := room.Join(ctx, flightID)
+	require.NoError(t, err)
+	require.False(t, second.Admitted)
+	require.Equal(t, int64(1), second.Position)
+
+	_, err = room.Admit(ctx, flightID, second.Token)
+	require.ErrorIs(t, err, ErrNotAdmitted)
+
+	restore, err := room.Admit(ctx, flightID, first.Token)
+	require.NoError(t, err)
+
+	// An admission can only be used once, unless it is given back.
+	_, err = room.Admit(ctx, flightID, first.Token)
+	require.ErrorIs(t, err, ErrNotAdmitted)
+	restore()
+	_, err = room.Admit(ctx, flightID, first.Token)
+	require.NoError(t, err)
+
+	_, err = room.Status(ctx, flightID, "unknown")
+	require.ErrorIs(t, err, ErrUnknownWaitingToken)
+}
+
+func TestWaitingRoom_JoinUnknownFlight(t *testing.T) {
+	room := NewWaitingRoom(repository.NewFlightRepo(gdb), rc, WaitingRoomConfig{Enabled: true})
+	ctx := context.Background()
+
+	_, err := room.Join(ctx, 0)
+	require.ErrorIs(t, err, ErrFlightNotFound)
+	exists, err := rc.Client.Exists(ctx, fmt.Sprintf(constant.WAITING_ROOM_SEQ_KEY, 0)).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/pkg/metrics"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "PENDING"
+	DeliveryStatusDelivered = "DELIVERED"
+	DeliveryStatusFailed    = "FAILED"
+)
+
+// Headers of webhook deliveries
+const (
+	WebhookSignatureHeader = "X-Tonx-Signature"
+	WebhookEventIDHeader   = "X-Tonx-Event-Id"
+	WebhookEventTypeHeader = "X-Tonx-Event-Type"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+// CreateWebhookRequest represents the request for subscribing a webhook
+type CreateWebhookRequest struct {
+	URL string
+	// EventTypes are the events sent to the webhook, all of them if empty
+	EventTypes []string
+}
+
+// WebhookConfig holds the tunables of the dispatcher
+type WebhookConfig struct {
+	// BatchSize is the number of events and deliveries handled per dispatch
+	BatchSize int
+	// MaxAttempts is the number of attempts after which a delivery fails
+	MaxAttempts int
+	// Timeout is how long a webhook may take to answer
+	Timeout time.Duration
+	// Backoff is the delay before the first retry, doubled for every further
+	// one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// WebhookEvent is the body of a webhook delivery
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Webhook defines the interface for delivering outbox events to webhooks
+type Webhook interface {
+	// CreateWebhook subscribes a webhook and generates the secret its
+	// deliveries are signed with
+	CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID uint) error
+	// Replay delivers the events created in [from, to) to a webhook again,
+	// whether they were delivered before or not. It returns the number of
+	// events queued.
+	Replay(ctx context.Context, webhookID uint, from, to time.Time) (int, error)
+	// Dispatch queues the deliveries of new events and sends those due. It
+	// returns the number of deliveries sent.
+	Dispatch(ctx context.Context) (int, error)
+	// Run dispatches every interval until ctx is done
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// webhookService implements Webhook
+type webhookService struct {
+	gdb    *gorm.DB
+	client *http.Client
+	cfg    WebhookConfig
+}
+
+// NewWebhookService creates a new instance of Webhook. Every replica may
+// dispatch, rows taken by one are skipped by the others.
+func NewWebhookService(gdb *gorm.DB, cfg WebhookConfig) Webhook {
+	return &webhookService{
+		gdb:    gdb,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+	}
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*model.Webhook, error) {
+	endpoint, err := url.Parse(req.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %s", ErrInvalidWebhook, eventType)
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	eventTypes := slices.Clone(req.EventTypes)
+	slices.Sort(eventTypes)
+	webhook := &model.Webhook{
+		URL:        req.URL,
+		Secret:     webhookSecretPrefix + hex.EncodeToString(secret),
+		EventTypes: strings.Join(slices.Compact(eventTypes), ","),
+		Active:     true,
+	}
+	if err = s.gdb.WithContext(ctx).Create(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	if err := s.gdb.WithContext(ctx).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, webhookID uint) error {
+	result := s.gdb.WithContext(ctx).Delete(&model.Webhook{}, webhookID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s *webhookService) Replay(ctx context.Context, webhookID uint, from, to time.Time) (int, error) {
+	var webhook model.Webhook
+	if err := s.gdb.WithContext(ctx).First(&webhook, webhookID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrWebhookNotFound
+		}
+		return 0, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	query := s.gdb.WithContext(ctx).Where("created_at >= ? AND created_at < ?", from, to)
+	if types := webhook.EventTypeList(); len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+
+	queued := 0
+	var events []model.OutboxEvent
+	err := query.Select("id").FindInBatches(&events, s.batchSize(), func(tx *gorm.DB, _ int) error {
+		now := time.Now()
+		deliveries := make([]model.WebhookDelivery, len(events))
+		for i, event := range events {
+			deliveries[i] = model.WebhookDelivery{
+				EventID:       event.ID,
+				WebhookID:     webhook.ID,
+				Status:        DeliveryStatusPending,
+				NextAttemptAt: now,
+			}
+		}
+		// Deliveries made before are started over
+		err := s.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"status":          DeliveryStatusPending,
+				"attempts":        0,
+				"next_attempt_at": now,
+				"last_error":      "",
+			}),
+		}).Create(&deliveries).Error
+		if err != nil {
+			return err
+		}
+		queued += len(deliveries)
+		return nil
+	}).Error
+	if err != nil {
+		return queued, fmt.Errorf("failed to queue replayed deliveries: %w", err)
+	}
+	return queued, nil
+}
+
+func (s *webhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Dispatch(ctx); err != nil {
+				log.Printf("failed to dispatch webhooks: %v\n", err)
+			}
+		}
+	}
+}
+
+func (s *webhookService) Dispatch(ctx context.Context) (int, error) {
+	if err := s.fanOut(ctx); err != nil {
+		return 0, err
+	}
+
+	deliveries, err := claimDue[model.WebhookDelivery](ctx, s.gdb, DeliveryStatusPending, s.batchSize(), 2*s.cfg.Timeout, "Event", "Webhook")
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// fanOut queues a delivery of each new event to every active webhook
+// subscribed to it, and marks the events dispatched.
+func (s *webhookService) fanOut(ctx context.Context) error {
+	return s.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []model.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").
+			Order("created_at").
+			Limit(s.batchSize()).
+			Find(&events).Error
+		if err != nil {
+			return fmt.Errorf("failed to get new events: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		var webhooks []model.Webhook
+		if err = tx.Where("active = ?", true).Find(&webhooks).Error; err != nil {
+			return fmt.Errorf("failed to get webhooks: %w", err)
+		}
+
+		now := time.Now()
+		var deliveries []model.WebhookDelivery
+		ids := make([]string, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+			for _, webhook := range webhooks {
+				if webhook.Subscribes(event.Type) {
+					deliveries = append(deliveries, model.WebhookDelivery{
+						EventID:       event.ID,
+						WebhookID:     webhook.ID,
+						Status:        DeliveryStatusPending,
+						NextAttemptAt: now,
+					})
+				}
+			}
+		}
+		if len(deliveries) > 0 {
+			if err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+				return fmt.Errorf("failed to queue deliveries: %w", err)
+			}
+		}
+		if err = tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("dispatched_at", now).Error; err != nil {
+			return fmt.Errorf("failed to mark events dispatched: %w", err)
+		}
+		return nil
+	})
+}
+
+// deliver sends a delivery and records the outcome, scheduling a retry with
+// exponential backoff when it failed.
+func (s *webhookService) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	statusCode, err := s.send(ctx, delivery.Webhook, delivery.Event)
+
+	updates, outcome := attemptUpdates(retryPolicy{
+		MaxAttempts: s.cfg.MaxAttempts,
+		Backoff:     s.cfg.Backoff,
+		MaxBackoff:  s.cfg.MaxBackoff,
+	}, delivery.Attempts, err)
+	updates["last_status_code"] = statusCode
+	switch outcome {
+	case attemptSucceeded:
+		updates["status"] = DeliveryStatusDelivered
+		updates["delivered_at"] = time.Now()
+		metrics.Inc("webhooks_delivered")
+	case attemptFailed:
+		updates["status"] = DeliveryStatusFailed
+		metrics.Inc("webhooks_failed")
+		log.Printf("giving up delivering event %s to webhook %d: %v\n", delivery.EventID, delivery.WebhookID, err)
+	default:
+		metrics.Inc("webhooks_retried")
+	}
+
+	// The outcome is recorded even when ctx is done, or the delivery is sent
+	// again once its claim expires
+	if err = s.gdb.WithContext(context.WithoutCancel(ctx)).Model(delivery).Updates(updates).Error; err != nil {
+		log.Printf("failed to record delivery %d: %v\n", delivery.ID, err)
+	}
+}
+
+// send posts an event to a webhook. It returns the status code of the
+// answer, 0 if there was none.
+func (s *webhookService) send(ctx context.Context, webhook *model.Webhook, event *model.OutboxEvent) (int, error) {
+	if webhook == nil || event == nil {
+		return 0, errors.New("webhook or event no longer exists")
+	}
+
+	body, err := json.Marshal(WebhookEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventIDHeader, event.ID)
+	req.Header.Set(WebhookEventTypeHeader, event.Type)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *webhookService) batchSize() int {
+	if s.cfg.BatchSize <= 0 {
+		return 100
+	}
+	return s.cfg.BatchSize
+}
+
+// SignWebhook returns the signature header of a delivery body, the
+// timestamp and the hex HMAC-SHA256 of "timestamp.body" with the secret of
+// the webhook. Receivers recompute it, and reject old timestamps to stop
+// replayed requests.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
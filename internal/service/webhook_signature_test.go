@@ -0,0 +1,18 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhook(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	signature := SignWebhook("whsec_test", at, []byte(`{"id":"1"}`))
+	require.True(t, strings.HasPrefix(signature, "t=1700000000,v1="))
+	require.Equal(t, signature, SignWebhook("whsec_test", at, []byte(`{"id":"1"}`)))
+	require.NotEqual(t, signature, SignWebhook("whsec_other", at, []byte(`{"id":"1"}`)))
+	require.NotEqual(t, signature, SignWebhook("whsec_test", at, []byte(`{"id":"2"}`)))
+}
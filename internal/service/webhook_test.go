@@ -0,0 +1,150 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joremysh/tonx/internal/inventory"
+	"github.com/joremysh/tonx/internal/model"
+	"github.com/joremysh/tonx/internal/repository"
+)
+
+func TestWebhookService_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	received := make(map[string][]WebhookEvent)
+	var failing atomic.Bool
+	var secret string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event WebhookEvent
+		if json.Unmarshal(body, &event) != nil || failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// Receivers check the signature against the timestamp it carries
+		var ts int64
+		for _, part := range strings.Split(r.Header.Get(WebhookSignatureHeader), ",") {
+			if v, ok := strings.CutPrefix(part, "t="); ok {
+				ts, _ = strconv.ParseInt(v, 10, 64)
+			}
+		}
+		require.Equal(t, SignWebhook(secret, time.Unix(ts, 0), body), r.Header.Get(WebhookSignatureHeader))
+		require.Equal(t, event.ID, r.Header.Get(WebhookEventIDHeader))
+
+		mu.Lock()
+		received[event.ID] = append(received[event.ID], event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	svc := NewWebhookService(gdb, WebhookConfig{
+		BatchSize:   1000,
+		MaxAttempts: 3,
+		Timeout:     time.Second,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	})
+	_, err = svc.CreateWebhook(ctx, CreateWebhookRequest{URL: "ftp://example.com"})
+	require.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = svc.CreateWebhook(ctx, CreateWebhookRequest{URL: server.URL, EventTypes: []string{"order.shipped"}})
+	require.ErrorIs(t, err, ErrInvalidWebhook)
+	webhook, err := svc.CreateWebhook(ctx, CreateWebhookRequest{URL: server.URL, EventTypes: []string{EventOrderCreated}})
+	require.NoError(t, err)
+	secret = webhook.Secret
+	defer func() {
+		require.NoError(t, svc.DeleteWebhook(ctx, webhook.ID))
+	}()
+
+	flight := &model.Flight{}
+	err = gdb.Where("available_seats > ?", 10).First(flight).Error
+	require.NoError(t, err)
+	customer := repository.MockCustomer()
+	err = gdb.Create(customer).Error
+	require.NoError(t, err)
+	order, err := NewOrderService(gdb, inventory.NewMemoryInventory(), nil, BookingLimits{}).CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     flight.ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 1,
+	})
+	require.NoError(t, err)
+
+	var event model.OutboxEvent
+	err = gdb.Where("aggregate_id = ? AND type = ?", order.ID, EventOrderCreated).First(&event).Error
+	require.NoError(t, err)
+
+	// Failed deliveries are retried until they succeed
+	failing.Store(true)
+	_, err = svc.Dispatch(ctx)
+	require.NoError(t, err)
+	var delivery model.WebhookDelivery
+	err = gdb.Where("event_id = ? AND webhook_id = ?", event.ID, webhook.ID).First(&delivery).Error
+	require.NoError(t, err)
+	require.Equal(t, DeliveryStatusPending, delivery.Status)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+
+	failing.Store(false)
+	time.Sleep(10 * time.Millisecond)
+	_, err = svc.Dispatch(ctx)
+	require.NoError(t, err)
+	err = gdb.First(&delivery, delivery.ID).Error
+	require.NoError(t, err)
+	require.Equal(t, DeliveryStatusDelivered, delivery.Status)
+
+	mu.Lock()
+	require.Len(t, received[event.ID], 1)
+	require.Equal(t, EventOrderCreated, received[event.ID][0].Type)
+	var data OrderEvent
+	require.NoError(t, json.Unmarshal(received[event.ID][0].Data, &data))
+	require.Equal(t, order.OrderNumber, data.OrderNumber)
+	mu.Unlock()
+
+	// Deliveries done already are not sent again along with the due ones
+	other, err := NewOrderService(gdb, inventory.NewMemoryInventory(), nil, BookingLimits{}).CreateOrder(ctx, CreateOrderRequest{
+		FlightID:     flight.ID,
+		CustomerID:   customer.ID,
+		TicketAmount: 1,
+	})
+	require.NoError(t, err)
+	var otherEvent model.OutboxEvent
+	err = gdb.Where("aggregate_id = ? AND type = ?", other.ID, EventOrderCreated).First(&otherEvent).Error
+	require.NoError(t, err)
+	err = gdb.Create(&model.WebhookDelivery{
+		EventID:       otherEvent.ID,
+		WebhookID:     webhook.ID,
+		Status:        DeliveryStatusDelivered,
+		Attempts:      1,
+		NextAttemptAt: time.Now(),
+	}).Error
+	require.NoError(t, err)
+
+	// Replayed events are delivered again with the same ID
+	queued, err := svc.Replay(ctx, webhook.ID, event.CreatedAt.Add(-time.Second), event.CreatedAt.Add(time.Second))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, queued, 1)
+	_, err = svc.Dispatch(ctx)
+	require.NoError(t, err)
+	mu.Lock()
+	require.Len(t, received[event.ID], 2)
+	require.Empty(t, received[otherEvent.ID])
+	mu.Unlock()
+
+	_, err = svc.Replay(ctx, webhook.ID+1000, time.Now().Add(-time.Hour), time.Now())
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+}
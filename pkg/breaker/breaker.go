@@ -0,0 +1,165 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned instead of calling through an open breaker.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateOpen fails every call until the open timeout has passed.
+	StateOpen
+	// StateHalfOpen lets a single probe through to decide whether to close
+	// again.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Settings configures a breaker.
+type Settings struct {
+	// MaxFailures is the number of consecutive failures that open the breaker.
+	MaxFailures int
+	// OpenTimeout is how long the breaker stays open before probing.
+	OpenTimeout time.Duration
+	// OnStateChange is called on every state change, outside of the lock.
+	OnStateChange func(from, to State)
+}
+
+// Breaker is a consecutive-failures circuit breaker, safe for concurrent use.
+type Breaker struct {
+	settings Settings
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	// generation counts the state changes, so calls let through before one
+	// do not count after it
+	generation uint64
+}
+
+// New creates a closed breaker.
+func New(settings Settings) *Breaker {
+	if settings.MaxFailures < 1 {
+		settings.MaxFailures = 1
+	}
+	return &Breaker{settings: settings}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState(time.Now())
+}
+
+// Execute calls fn unless the breaker is open, and counts an error returned
+// by fn as a failure. It returns ErrOpen without calling fn when open.
+// Outcomes of calls that were let through before the breaker last changed
+// state are ignored.
+func (b *Breaker) Execute(fn func() error) error {
+	generation, err := b.before()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.after(generation, err == nil)
+	return err
+}
+
+func (b *Breaker) before() (uint64, error) {
+	b.mu.Lock()
+	from := b.state
+	b.setState(b.currentState(time.Now()))
+	state, generation := b.state, b.generation
+
+	var err error
+	switch {
+	case state == StateOpen:
+		err = ErrOpen
+	case state == StateHalfOpen && b.probing:
+		err = ErrOpen
+	case state == StateHalfOpen:
+		b.probing = true
+	}
+	b.mu.Unlock()
+
+	b.notify(from, state)
+	return generation, err
+}
+
+func (b *Breaker) after(generation uint64, success bool) {
+	b.mu.Lock()
+	from := b.state
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	switch {
+	case from == StateHalfOpen && success:
+		// Only the probe was let through in this generation
+		b.setState(StateClosed)
+	case from == StateHalfOpen:
+		b.setState(StateOpen)
+	case success:
+		b.failures = 0
+	default:
+		b.failures++
+		if b.failures >= b.settings.MaxFailures {
+			b.setState(StateOpen)
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// setState moves the breaker to state, starting a new generation if it
+// changes. b.mu must be held.
+func (b *Breaker) setState(state State) {
+	if state == b.state {
+		return
+	}
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.probing = false
+	if state == StateOpen {
+		b.openedAt = time.Now()
+	}
+}
+
+// currentState moves an open breaker to half-open once its timeout passed.
+func (b *Breaker) currentState(now time.Time) State {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.settings.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,82 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker_Execute(t *testing.T) {
+	var changes []State
+	b := New(Settings{
+		MaxFailures: 2,
+		OpenTimeout: 20 * time.Millisecond,
+		OnStateChange: func(_, to State) {
+			changes = append(changes, to)
+		},
+	})
+	failure := errors.New("down")
+	fail := func() error { return failure }
+	succeed := func() error { return nil }
+
+	require.ErrorIs(t, b.Execute(fail), failure)
+	require.Equal(t, StateClosed, b.State())
+	require.ErrorIs(t, b.Execute(fail), failure)
+	require.Equal(t, StateOpen, b.State())
+
+	called := false
+	err := b.Execute(func() error {
+		called = true
+		return nil
+	})
+	require.ErrorIs(t, err, ErrOpen)
+	require.False(t, called)
+
+	// A failed probe opens the breaker again
+	time.Sleep(25 * time.Millisecond)
+	require.Equal(t, StateHalfOpen, b.State())
+	require.ErrorIs(t, b.Execute(fail), failure)
+	require.Equal(t, StateOpen, b.State())
+
+	time.Sleep(25 * time.Millisecond)
+	require.NoError(t, b.Execute(succeed))
+	require.Equal(t, StateClosed, b.State())
+
+	require.Equal(t, []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}, changes)
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b := New(Settings{MaxFailures: 2, OpenTimeout: time.Minute})
+	failure := errors.New("down")
+
+	require.Error(t, b.Execute(func() error { return failure }))
+	require.NoError(t, b.Execute(func() error { return nil }))
+	require.Error(t, b.Execute(func() error { return failure }))
+	require.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_StaleOutcomes(t *testing.T) {
+	b := New(Settings{MaxFailures: 1, OpenTimeout: 20 * time.Millisecond})
+	failure := errors.New("down")
+
+	// A slow call that started before the breaker opened does not close it
+	slow, err := b.before()
+	require.NoError(t, err)
+	require.Error(t, b.Execute(func() error { return failure }))
+	require.Equal(t, StateOpen, b.State())
+	b.after(slow, true)
+	require.Equal(t, StateOpen, b.State())
+
+	// Nor does it decide the probe, or let a second one through
+	time.Sleep(25 * time.Millisecond)
+	probe, err := b.before()
+	require.NoError(t, err)
+	require.Equal(t, StateHalfOpen, b.State())
+	b.after(slow, true)
+	require.Equal(t, StateHalfOpen, b.State())
+	require.ErrorIs(t, b.Execute(func() error { return nil }), ErrOpen)
+	b.after(probe, true)
+	require.Equal(t, StateClosed, b.State())
+}
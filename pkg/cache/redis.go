@@ -2,26 +2,99 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Redis deployment modes
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// Config describes how to connect to Redis.
+type Config struct {
+	// Mode is one of ModeStandalone, ModeSentinel or ModeCluster, standalone
+	// when empty.
+	Mode string
+	// Addrs is the address of the server, of the sentinels, or the seed nodes
+	// of the cluster.
+	Addrs []string
+	// MasterName is the name of the master monitored by the sentinels.
+	MasterName string
+	Username   string
+	Password   string
+	// SentinelPassword authenticates against the sentinels themselves, if
+	// they require it.
+	SentinelPassword string
+	// DB is ignored in cluster mode, which only has DB 0.
+	DB int
+	// TLS enables TLS, with TLSServerName overriding the name verified
+	// against the server certificate.
+	TLS           bool
+	TLSServerName string
+}
+
 type RedisClient struct {
-	Client *redis.Client
+	Client redis.UniversalClient
 }
 
-func NewRedisClient(redisAddr string) (*RedisClient, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     redisAddr, // Redis address
-		Password: "",        // no password set
-		DB:       0,         // use default DB
-	})
+func NewRedisClient(cfg Config) (*RedisClient, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, errors.New("no Redis address configured")
+	}
+	var tlsConfig *tls.Config
+	if cfg.TLS {
+		tlsConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: cfg.TLSServerName,
+		}
+	}
+
+	var client redis.UniversalClient
+	switch cfg.Mode {
+	case ModeStandalone, "":
+		client = redis.NewClient(&redis.Options{
+			Addr:      cfg.Addrs[0],
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			TLSConfig: tlsConfig,
+		})
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, errors.New("sentinel mode requires a master name")
+		}
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Username:         cfg.Username,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			TLSConfig:        tlsConfig,
+		})
+	case ModeCluster:
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     cfg.Addrs,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			TLSConfig: tlsConfig,
+		})
+	default:
+		return nil, fmt.Errorf("unknown Redis mode %q", cfg.Mode)
+	}
 
 	ctx := context.Background()
 	_, err := client.Ping(ctx).Result()
 	if err != nil {
+		_ = client.Close()
 		return nil, err
 	}
 
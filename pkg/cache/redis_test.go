@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedisClient_InvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  Config
+	}{{
+		name: "no address",
+		cfg:  Config{Mode: ModeStandalone},
+	}, {
+		name: "sentinel without master name",
+		cfg:  Config{Mode: ModeSentinel, Addrs: []string{"localhost:26379"}},
+	}, {
+		name: "unknown mode",
+		cfg:  Config{Mode: "replica", Addrs: []string{"localhost:6379"}},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client, err := NewRedisClient(testCase.cfg)
+			require.Error(t, err)
+			require.Nil(t, client)
+		})
+	}
+}
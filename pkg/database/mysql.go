@@ -10,7 +10,8 @@ import (
 
 func NewDatabase(dsn string) (*gorm.DB, error) {
 	gdb, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         logger.Default.LogMode(logger.Info),
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// Counters are published on the expvar handler under the "tonx" map.
+var counters = expvar.NewMap("tonx")
+
+// Add increments the counter name by delta.
+func Add(name string, delta int64) {
+	counters.Add(name, delta)
+}
+
+// Inc increments the counter name by one.
+func Inc(name string) {
+	counters.Add(name, 1)
+}
+
+// Handler serves all published variables as JSON.
+func Handler() http.Handler {
+	return expvar.Handler()
+}
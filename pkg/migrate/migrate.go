@@ -0,0 +1,238 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	lockName    = "schema_migrations"
+	lockTimeout = 60 // seconds
+)
+
+// ErrLocked is returned when another instance holds the migration lock for
+// longer than the lock timeout.
+var ErrLocked = errors.New("migrations are locked by another instance")
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (version)
+)`
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied, nil when it is pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// appliedMigration is a row of the schema_migrations table.
+type appliedMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and rolls back migrations on a MySQL database. Instances
+// take a named lock first, so only one of several replicas migrates at once.
+type Migrator struct {
+	gdb        *gorm.DB
+	migrations []Migration
+}
+
+// New reads the migrations in fsys, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Statements in a file are separated by a
+// semicolon at the end of a line.
+func New(gdb *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return &Migrator{gdb: gdb, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations in order and returns how many it applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.locked(ctx, func(conn *gorm.DB, applied map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := exec(conn, migration.Up); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if err := conn.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error; err != nil {
+				return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last steps applied migrations and returns how many it
+// rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.locked(ctx, func(conn *gorm.DB, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := exec(conn, migration.Down); err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if err := conn.Delete(&appliedMigration{Version: migration.Version}).Error; err != nil {
+				return fmt.Errorf("failed to unrecord migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status returns every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(_ *gorm.DB, applied map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if at, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// locked runs fn on a single connection holding the migration lock, with the
+// migrations applied so far. MySQL named locks belong to a connection, hence
+// everything runs on the one that took it.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB, applied map[int64]time.Time) error) error {
+	return m.gdb.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var acquired *int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		if acquired == nil || *acquired != 1 {
+			return ErrLocked
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+
+		if err := conn.Exec(createTable).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		var rows []appliedMigration
+		if err := conn.Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to list applied migrations: %w", err)
+		}
+		applied := make(map[int64]time.Time, len(rows))
+		for _, row := range rows {
+			applied[row.Version] = row.AppliedAt
+		}
+		return fn(conn, applied)
+	})
+}
+
+// exec runs the statements of a migration file one by one. MySQL commits
+// schema changes implicitly, so a failed migration is not rolled back and has
+// to be written to be rerun.
+func exec(conn *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := conn.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t (c);")},
+		"0002_add_index.down.sql":    {Data: []byte("DROP INDEX idx ON t;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                  {Data: []byte("ignored")},
+	}
+	migrator, err := New(nil, fsys)
+	require.NoError(t, err)
+	require.Len(t, migrator.migrations, 2)
+	require.Equal(t, int64(1), migrator.migrations[0].Version)
+	require.Equal(t, "create_table", migrator.migrations[0].Name)
+	require.Equal(t, "DROP TABLE t;", migrator.migrations[0].Down)
+	require.Equal(t, int64(2), migrator.migrations[1].Version)
+
+	_, err = New(nil, fstest.MapFS{
+		"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+	})
+	require.ErrorContains(t, err, "needs both an up and a down file")
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`
+-- Two tables
+CREATE TABLE a (
+    id INT
+);
+
+CREATE TABLE b (id INT);
+INSERT INTO b VALUES (1)
+`)
+	require.Equal(t, []string{
+		"CREATE TABLE a (\n    id INT\n);",
+		"CREATE TABLE b (id INT);",
+		"INSERT INTO b VALUES (1)",
+	}, statements)
+}
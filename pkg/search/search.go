@@ -0,0 +1,123 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Match is a candidate that matched a query, ranked by Distance.
+type Match struct {
+	Value string
+	// Distance is 0 for a prefix match and the number of edits needed to turn
+	// the query into a prefix of the value otherwise.
+	Distance int
+}
+
+// Normalize folds s for comparison: accents are stripped, letters are
+// lowercased and surrounding and repeated whitespace is collapsed.
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// MaxDistance is the number of typos tolerated in a query of n runes.
+func MaxDistance(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Suggest ranks candidates against query. Candidates with a word starting with
+// the query come first, followed by candidates whose prefix is within
+// MaxDistance edits of the query. At most limit matches are returned.
+func Suggest(query string, candidates []string, limit int) []Match {
+	q := []rune(Normalize(query))
+	if len(q) == 0 {
+		return nil
+	}
+	maxDistance := MaxDistance(len(q))
+
+	var matches []Match
+	for _, candidate := range candidates {
+		distance := -1
+		normalized := Normalize(candidate)
+		for _, word := range wordSuffixes(normalized) {
+			d := prefixDistance(q, []rune(word))
+			if d <= maxDistance && (distance < 0 || d < distance) {
+				distance = d
+			}
+		}
+		if distance >= 0 {
+			matches = append(matches, Match{Value: candidate, Distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Value < matches[j].Value
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// wordSuffixes returns s and every suffix of s starting at a word, so that
+// "new york" can be found by "york" as well.
+func wordSuffixes(s string) []string {
+	suffixes := []string{s}
+	for i, r := range s {
+		if r == ' ' || r == '-' {
+			suffixes = append(suffixes, s[i+1:])
+		}
+	}
+	return suffixes
+}
+
+// prefixDistance returns the smallest optimal string alignment distance
+// between query and any prefix of value.
+func prefixDistance(query, value []rune) int {
+	prev2 := make([]int, len(value)+1)
+	prev := make([]int, len(value)+1)
+	curr := make([]int, len(value)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(query); i++ {
+		curr[0] = i
+		for j := 1; j <= len(value); j++ {
+			cost := 1
+			if query[i-1] == value[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && query[i-1] == value[j-2] && query[i-2] == value[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	best := prev[0]
+	for _, d := range prev[1:] {
+		best = min(best, d)
+	}
+	return best
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	require.Equal(t, "sao paulo", Normalize("  São   Paulo "))
+	require.Equal(t, "zurich", Normalize("ZÜRICH"))
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Taipei", "Tainan", "Tokyo", "New York", "São Paulo", "Zürich"}
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []Match
+	}{{
+		name:     "prefix",
+		query:    "tai",
+		expected: []Match{{Value: "Tainan"}, {Value: "Taipei"}},
+	}, {
+		name:     "accent and case insensitive",
+		query:    "SAO",
+		expected: []Match{{Value: "São Paulo"}},
+	}, {
+		name:     "later word",
+		query:    "york",
+		expected: []Match{{Value: "New York"}},
+	}, {
+		name:     "typo",
+		query:    "tokoy",
+		expected: []Match{{Value: "Tokyo", Distance: 1}},
+	}, {
+		name:     "short queries are not fuzzy",
+		query:    "zx",
+		expected: nil,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, Suggest(testCase.query, candidates, 10))
+		})
+	}
+}
@@ -58,7 +58,8 @@ func NewTestContainers() (*dockertest.Pool, *dockertest.Resource, *gorm.DB, *doc
 	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
 	err = pool.Retry(func() error {
 		gdb, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
+			Logger:         logger.Default.LogMode(logger.Info),
+			TranslateError: true,
 		})
 		if err != nil {
 			return err